@@ -0,0 +1,32 @@
+package http
+
+import "github.com/akarso/pano_chart/backend/domain"
+
+// candleJSON is the wire form of a single candle.
+// Timestamp holds either epoch milliseconds or an RFC3339 string, depending on the requested format.
+type candleJSON struct {
+	Timestamp interface{} `json:"timestamp"`
+	Open      float64     `json:"open"`
+	High      float64     `json:"high"`
+	Low       float64     `json:"low"`
+	Close     float64     `json:"close"`
+	Volume    float64     `json:"volume"`
+}
+
+// toCandleJSON converts a series into its wire form. The result is never nil,
+// so empty series encode as [] rather than null.
+func toCandleJSON(series domain.CandleSeries, f timestampFormat) []candleJSON {
+	all := series.All()
+	out := make([]candleJSON, len(all))
+	for i, c := range all {
+		out[i] = candleJSON{
+			Timestamp: f.render(c.Timestamp()),
+			Open:      c.Open(),
+			High:      c.High(),
+			Low:       c.Low(),
+			Close:     c.Close(),
+			Volume:    c.Volume(),
+		}
+	}
+	return out
+}
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/application/usecases"
+	"github.com/akarso/pano_chart/backend/domain"
+	"github.com/akarso/pano_chart/backend/domain/indicators"
+)
+
+// statusClientClosedRequest is the non-standard status (nginx convention) used when the
+// client went away before the response was ready; it mostly shows up in access logs.
+const statusClientClosedRequest = 499
+
+// Error codes exposed to clients (see COMMON.md, Error Semantics).
+const (
+	CodeInvalidSymbol       = "INVALID_SYMBOL"
+	CodeInvalidTimeframe    = "INVALID_TIMEFRAME"
+	CodeInvalidTimestamp    = "INVALID_TIMESTAMP"
+	CodeInvalidRange        = "INVALID_RANGE"
+	CodeMissingParameter    = "MISSING_PARAMETER"
+	CodeInvalidParameter    = "INVALID_PARAMETER"
+	CodeInvalidIndicator    = "INVALID_INDICATOR"
+	CodeRateLimited         = "RATE_LIMITED"
+	CodeUpstreamUnavailable = "UPSTREAM_UNAVAILABLE"
+	CodeTimeout             = "TIMEOUT"
+	CodeCanceled            = "CANCELED"
+	CodeInternalError       = "INTERNAL_ERROR"
+	CodeSlowConsumer        = "SLOW_CONSUMER"
+	CodeStreamLimit         = "STREAM_LIMIT"
+)
+
+// errorDetail is the inner object of the error envelope.
+type errorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// errorEnvelope is the COMMON.md error response shape: {"error":{"code","message"}}.
+type errorEnvelope struct {
+	Error errorDetail `json:"error"`
+}
+
+// writeError writes an error envelope with the given status, code and message.
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(errorEnvelope{Error: errorDetail{Code: code, Message: message}})
+}
+
+// writeErrorFrom maps a domain or use case error to an error envelope. A known retry
+// delay is passed on in a Retry-After header, rounded up to whole seconds.
+func writeErrorFrom(w http.ResponseWriter, err error) {
+	status, detail := classifyError(err)
+	if d := ports.RetryAfter(err); d > 0 && status == http.StatusTooManyRequests {
+		w.Header().Set("Retry-After", strconv.FormatInt(int64((d+time.Second-1)/time.Second), 10))
+	}
+	writeError(w, status, detail.Code, detail.Message)
+}
+
+// classifyError maps typed domain and application errors to an HTTP status and error detail.
+// Unrecognized errors are reported as INTERNAL_ERROR without leaking their message.
+func classifyError(err error) (int, errorDetail) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidSymbol):
+		return http.StatusBadRequest, errorDetail{Code: CodeInvalidSymbol, Message: err.Error()}
+	case errors.Is(err, domain.ErrInvalidTimeframe):
+		return http.StatusBadRequest, errorDetail{Code: CodeInvalidTimeframe, Message: err.Error()}
+	case errors.Is(err, domain.ErrInvalidRange):
+		return http.StatusBadRequest, errorDetail{Code: CodeInvalidRange, Message: err.Error()}
+	case errors.Is(err, indicators.ErrInvalidParameter):
+		return http.StatusBadRequest, errorDetail{Code: CodeInvalidIndicator, Message: err.Error()}
+	case errors.Is(err, usecases.ErrInvalidScreenerQuery):
+		return http.StatusBadRequest, errorDetail{Code: CodeInvalidParameter, Message: err.Error()}
+	case errors.Is(err, ports.ErrRateLimited):
+		return http.StatusTooManyRequests, errorDetail{Code: CodeRateLimited, Message: "upstream rate limit reached, retry later"}
+	case errors.Is(err, ports.ErrUpstreamUnavailable):
+		return http.StatusBadGateway, errorDetail{Code: CodeUpstreamUnavailable, Message: "upstream provider unavailable"}
+	case errors.Is(err, ports.ErrStreamFull):
+		return http.StatusServiceUnavailable, errorDetail{Code: CodeStreamLimit, Message: "stream capacity reached, retry later"}
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, errorDetail{Code: CodeTimeout, Message: "request timed out"}
+	case errors.Is(err, context.Canceled):
+		return statusClientClosedRequest, errorDetail{Code: CodeCanceled, Message: "request canceled"}
+	default:
+		return http.StatusInternalServerError, errorDetail{Code: CodeInternalError, Message: "internal error"}
+	}
+}
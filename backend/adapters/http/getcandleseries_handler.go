@@ -1,102 +1,112 @@
 package http
 
 import (
+	"bytes"
 	"encoding/json"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/akarso/pano_chart/backend/application/usecases"
 	"github.com/akarso/pano_chart/backend/domain"
 )
 
+// candleSeriesResponse is the body of a successful /api/v1/candles response.
+type candleSeriesResponse struct {
+	Symbol    string       `json:"symbol"`
+	Timeframe string       `json:"timeframe"`
+	Candles   []candleJSON `json:"candles"`
+}
+
+// seriesParams are the query parameters shared by single-series endpoints.
+type seriesParams struct {
+	symbol domain.Symbol
+	tf     domain.Timeframe
+	from   time.Time
+	to     time.Time
+	format timestampFormat
+}
+
+// parseSeriesParams reads symbol, timeframe, from, to and format from q.
+// On failure it writes the error response and returns false.
+func parseSeriesParams(w http.ResponseWriter, q url.Values) (seriesParams, bool) {
+	symStr := q.Get("symbol")
+	tfStr := q.Get("timeframe")
+	fromStr := q.Get("from")
+	toStr := q.Get("to")
+
+	if symStr == "" || tfStr == "" || fromStr == "" || toStr == "" {
+		writeError(w, http.StatusBadRequest, CodeMissingParameter, "symbol, timeframe, from and to are required")
+		return seriesParams{}, false
+	}
+
+	format, err := parseTimestampFormat(q.Get("format"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return seriesParams{}, false
+	}
+
+	// Construct domain objects
+	sym, err := domain.NewSymbol(symStr)
+	if err != nil {
+		writeErrorFrom(w, err)
+		return seriesParams{}, false
+	}
+	tf, err := domain.NewTimeframe(tfStr)
+	if err != nil {
+		writeErrorFrom(w, err)
+		return seriesParams{}, false
+	}
+
+	from, err := parseTimestamp(fromStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidTimestamp, "from must be epoch milliseconds or RFC3339")
+		return seriesParams{}, false
+	}
+	to, err := parseTimestamp(toStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidTimestamp, "to must be epoch milliseconds or RFC3339")
+		return seriesParams{}, false
+	}
+
+	return seriesParams{symbol: sym, tf: tf, from: from, to: to, format: format}, true
+}
+
+// writeJSON writes v as a 200 JSON response. v is encoded before anything is written, so
+// a value that cannot be encoded yields a 500 error envelope rather than a truncated 200.
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternalError, "response could not be encoded")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
+
 // NewGetCandleSeriesHandler constructs an http.HandlerFunc that adapts HTTP requests
 // to the GetCandleSeries use case.
+//
+// `from` and `to` accept UTC epoch milliseconds or RFC3339. Candle timestamps are
+// emitted as epoch milliseconds unless `format=rfc3339` requests the legacy shape.
 func NewGetCandleSeriesHandler(uc usecases.GetCandleSeries) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		q := r.URL.Query()
-		symStr := q.Get("symbol")
-		tfStr := q.Get("timeframe")
-		fromStr := q.Get("from")
-		toStr := q.Get("to")
-
-		if symStr == "" || tfStr == "" || fromStr == "" || toStr == "" {
-			http.Error(w, "missing required query parameters", http.StatusBadRequest)
+		p, ok := parseSeriesParams(w, r.URL.Query())
+		if !ok {
 			return
 		}
 
-		// Construct domain objects
-		sym, err := domain.NewSymbol(symStr)
+		series, err := uc.Execute(r.Context(), p.symbol, p.tf, p.from, p.to)
 		if err != nil {
-			http.Error(w, "invalid symbol", http.StatusBadRequest)
+			writeErrorFrom(w, err)
 			return
 		}
-		tf, err := domain.NewTimeframe(tfStr)
-		if err != nil {
-			http.Error(w, "invalid timeframe", http.StatusBadRequest)
-			return
-		}
-
-		from, err := time.Parse(time.RFC3339, fromStr)
-		if err != nil {
-			http.Error(w, "invalid from time", http.StatusBadRequest)
-			return
-		}
-		if from.Location() != time.UTC {
-			from = from.UTC()
-		}
-		to, err := time.Parse(time.RFC3339, toStr)
-		if err != nil {
-			http.Error(w, "invalid to time", http.StatusBadRequest)
-			return
-		}
-		if to.Location() != time.UTC {
-			to = to.UTC()
-		}
-
-		series, err := uc.Execute(sym, tf, from, to)
-		if err != nil {
-			http.Error(w, "use case error", http.StatusInternalServerError)
-			return
-		}
-
-		// Build response
-		resp := struct {
-			Symbol    string `json:"symbol"`
-			Timeframe string `json:"timeframe"`
-			Candles   []struct {
-				Timestamp string  `json:"timestamp"`
-				Open      float64 `json:"open"`
-				High      float64 `json:"high"`
-				Low       float64 `json:"low"`
-				Close     float64 `json:"close"`
-				Volume    float64 `json:"volume"`
-			} `json:"candles"`
-		}{
-			Symbol:    sym.String(),
-			Timeframe: tf.String(),
-		}
-
-		all := series.All()
-		resp.Candles = make([]struct {
-			Timestamp string  `json:"timestamp"`
-			Open      float64 `json:"open"`
-			High      float64 `json:"high"`
-			Low       float64 `json:"low"`
-			Close     float64 `json:"close"`
-			Volume    float64 `json:"volume"`
-		}, len(all))
-
-		for i, c := range all {
-			resp.Candles[i].Timestamp = c.Timestamp().Format(time.RFC3339)
-			resp.Candles[i].Open = c.Open()
-			resp.Candles[i].High = c.High()
-			resp.Candles[i].Low = c.Low()
-			resp.Candles[i].Close = c.Close()
-			resp.Candles[i].Volume = c.Volume()
-		}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		_ = json.NewEncoder(w).Encode(resp)
+		writeJSON(w, candleSeriesResponse{
+			Symbol:    p.symbol.String(),
+			Timeframe: p.tf.String(),
+			Candles:   toCandleJSON(series, p.format),
+		})
 	}
 }
@@ -0,0 +1,113 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/akarso/pano_chart/backend/application/usecases"
+	"github.com/akarso/pano_chart/backend/domain/indicators"
+)
+
+// maxIndicatorsPerRequest bounds how many indicators a single request may compute.
+const maxIndicatorsPerRequest = 10
+
+// indicatorPointJSON is one indicator value; Value is null while the indicator warms up.
+type indicatorPointJSON struct {
+	Timestamp interface{} `json:"timestamp"`
+	Value     *float64    `json:"value"`
+}
+
+type indicatorLineJSON struct {
+	Name   string               `json:"name"`
+	Points []indicatorPointJSON `json:"points"`
+}
+
+type indicatorJSON struct {
+	Name  string              `json:"name"`
+	Lines []indicatorLineJSON `json:"lines"`
+}
+
+type indicatorsResponse struct {
+	Symbol     string          `json:"symbol"`
+	Timeframe  string          `json:"timeframe"`
+	Candles    []candleJSON    `json:"candles"`
+	Indicators []indicatorJSON `json:"indicators"`
+}
+
+// NewGetIndicatorsHandler constructs an http.HandlerFunc that adapts HTTP requests
+// to the GetIndicators use case.
+//
+// In addition to the /api/v1/candles parameters it accepts:
+//   - indicators: comma-separated specs, e.g. "sma:20,rsi:14,macd:12:26:9,bb:20:2" (required)
+//   - gaps: "reset" (default) restarts indicators after gaps, "ignore" treats data as contiguous
+func NewGetIndicatorsHandler(uc usecases.GetIndicators) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		p, ok := parseSeriesParams(w, q)
+		if !ok {
+			return
+		}
+
+		specStr := q.Get("indicators")
+		if specStr == "" {
+			writeError(w, http.StatusBadRequest, CodeMissingParameter, "indicators is required")
+			return
+		}
+		parts := strings.Split(specStr, ",")
+		if len(parts) > maxIndicatorsPerRequest {
+			writeError(w, http.StatusBadRequest, CodeInvalidParameter, fmt.Sprintf("at most %d indicators are allowed", maxIndicatorsPerRequest))
+			return
+		}
+		specs := make([]indicators.Spec, 0, len(parts))
+		for _, part := range parts {
+			spec, err := indicators.ParseSpec(part)
+			if err != nil {
+				writeErrorFrom(w, err)
+				return
+			}
+			specs = append(specs, spec)
+		}
+
+		var gaps indicators.GapMode
+		switch strings.ToLower(q.Get("gaps")) {
+		case "", "reset":
+			gaps = indicators.GapReset
+		case "ignore":
+			gaps = indicators.GapIgnore
+		default:
+			writeError(w, http.StatusBadRequest, CodeInvalidParameter, "gaps must be reset or ignore")
+			return
+		}
+
+		res, err := uc.Execute(r.Context(), p.symbol, p.tf, p.from, p.to, specs, gaps)
+		if err != nil {
+			writeErrorFrom(w, err)
+			return
+		}
+
+		resp := indicatorsResponse{
+			Symbol:     p.symbol.String(),
+			Timeframe:  p.tf.String(),
+			Candles:    toCandleJSON(res.Series, p.format),
+			Indicators: make([]indicatorJSON, len(res.Indicators)),
+		}
+		for i, out := range res.Indicators {
+			ind := indicatorJSON{Name: out.Spec.String(), Lines: make([]indicatorLineJSON, len(out.Lines))}
+			for j, line := range out.Lines {
+				pts := make([]indicatorPointJSON, len(line.Points))
+				for k, pt := range line.Points {
+					pts[k].Timestamp = p.format.render(pt.Timestamp)
+					if pt.Valid {
+						v := pt.Value
+						pts[k].Value = &v
+					}
+				}
+				ind.Lines[j] = indicatorLineJSON{Name: line.Name, Points: pts}
+			}
+			resp.Indicators[i] = ind
+		}
+
+		writeJSON(w, resp)
+	}
+}
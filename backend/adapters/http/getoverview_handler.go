@@ -0,0 +1,203 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/usecases"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+const (
+	// maxOverviewSymbols bounds how many symbols a single overview request may ask for.
+	maxOverviewSymbols = 100
+	// defaultOverviewCandles is the window size used when from/to are omitted.
+	defaultOverviewCandles = 100
+)
+
+// rangeJSON is the wire form of analysis.RangeAnalysis.
+type rangeJSON struct {
+	Score    float64 `json:"score"`
+	Upper    float64 `json:"upper"`
+	Lower    float64 `json:"lower"`
+	WidthATR float64 `json:"width_atr"`
+	ADX      float64 `json:"adx"`
+	SlopeT   float64 `json:"slope_t"`
+	SlopeR2  float64 `json:"slope_r2"`
+}
+
+type overviewSymbol struct {
+	Symbol  string       `json:"symbol"`
+	Candles []candleJSON `json:"candles"`
+	Range   *rangeJSON   `json:"range,omitempty"`
+	Error   *errorDetail `json:"error,omitempty"`
+}
+
+type overviewResponse struct {
+	Timeframe string           `json:"timeframe"`
+	Symbols   []overviewSymbol `json:"symbols"`
+}
+
+// NewGetOverviewHandler constructs an http.HandlerFunc that adapts HTTP requests
+// to the GetOverview use case.
+//
+// Query parameters:
+//   - symbols: comma-separated list of symbols (required)
+//   - timeframe: canonical timeframe (required)
+//   - from, to: epoch milliseconds or RFC3339 (optional; both or neither). When omitted
+//     the most recent defaultOverviewCandles candles up to now are requested.
+//   - format: timestamp rendering, epoch_ms (default) or rfc3339
+//   - sort: "range" orders symbols by descending range score (most sideways first);
+//     by default symbols keep request order
+func NewGetOverviewHandler(uc usecases.GetOverview) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		format, err := parseTimestampFormat(q.Get("format"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+			return
+		}
+
+		sortBy := q.Get("sort")
+		if sortBy != "" && sortBy != "range" {
+			writeError(w, http.StatusBadRequest, CodeInvalidParameter, "sort must be range")
+			return
+		}
+
+		p, ok := parseMultiSymbolParams(w, q)
+		if !ok {
+			return
+		}
+
+		overview, err := uc.Execute(r.Context(), p.symbols, p.tf, p.from, p.to)
+		if err != nil {
+			writeErrorFrom(w, err)
+			return
+		}
+
+		resp := overviewResponse{
+			Timeframe: overview.Timeframe.String(),
+			Symbols:   make([]overviewSymbol, len(overview.Entries)),
+		}
+		for i, e := range overview.Entries {
+			entry := overviewSymbol{Symbol: e.Symbol.String(), Candles: []candleJSON{}}
+			if e.Err != nil {
+				_, detail := classifyError(e.Err)
+				entry.Error = &detail
+			} else {
+				entry.Candles = toCandleJSON(e.Series, format)
+			}
+			if ra := e.Range; ra != nil {
+				entry.Range = &rangeJSON{
+					Score:    ra.Score,
+					Upper:    ra.Upper,
+					Lower:    ra.Lower,
+					WidthATR: ra.WidthATR,
+					ADX:      ra.ADX,
+					SlopeT:   ra.SlopeT,
+					SlopeR2:  ra.SlopeR2,
+				}
+			}
+			resp.Symbols[i] = entry
+		}
+		if sortBy == "range" {
+			sortByRangeScore(resp.Symbols)
+		}
+
+		writeJSON(w, resp)
+	}
+}
+
+// multiSymbolParams holds the validated parameters shared by the multi-symbol endpoints.
+type multiSymbolParams struct {
+	symbols []domain.Symbol
+	tf      domain.Timeframe
+	from    time.Time
+	to      time.Time
+}
+
+// parseMultiSymbolParams validates symbols, timeframe and the optional from/to pair.
+// When from/to are omitted the most recent defaultOverviewCandles candles up to now
+// are selected. On failure it writes the error response and returns false.
+func parseMultiSymbolParams(w http.ResponseWriter, q url.Values) (multiSymbolParams, bool) {
+	symsStr := q.Get("symbols")
+	tfStr := q.Get("timeframe")
+	fromStr := q.Get("from")
+	toStr := q.Get("to")
+
+	if symsStr == "" || tfStr == "" {
+		writeError(w, http.StatusBadRequest, CodeMissingParameter, "symbols and timeframe are required")
+		return multiSymbolParams{}, false
+	}
+	if (fromStr == "") != (toStr == "") {
+		writeError(w, http.StatusBadRequest, CodeInvalidRange, "from and to must be provided together")
+		return multiSymbolParams{}, false
+	}
+
+	syms, err := parseSymbolList(symsStr)
+	if err != nil {
+		writeErrorFrom(w, err)
+		return multiSymbolParams{}, false
+	}
+	if len(syms) > maxOverviewSymbols {
+		writeError(w, http.StatusBadRequest, CodeInvalidParameter, fmt.Sprintf("at most %d symbols are allowed", maxOverviewSymbols))
+		return multiSymbolParams{}, false
+	}
+	tf, err := domain.NewTimeframe(tfStr)
+	if err != nil {
+		writeErrorFrom(w, err)
+		return multiSymbolParams{}, false
+	}
+
+	p := multiSymbolParams{symbols: syms, tf: tf}
+	if fromStr == "" {
+		p.to = time.Now().UTC().Truncate(tf.Duration()).Add(tf.Duration())
+		p.from = p.to.Add(-defaultOverviewCandles * tf.Duration())
+		return p, true
+	}
+	if p.from, err = parseTimestamp(fromStr); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidTimestamp, "from must be epoch milliseconds or RFC3339")
+		return multiSymbolParams{}, false
+	}
+	if p.to, err = parseTimestamp(toStr); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidTimestamp, "to must be epoch milliseconds or RFC3339")
+		return multiSymbolParams{}, false
+	}
+	return p, true
+}
+
+// parseSymbolList splits a comma-separated list into validated, de-duplicated symbols,
+// preserving the order of first occurrence.
+func parseSymbolList(s string) ([]domain.Symbol, error) {
+	parts := strings.Split(s, ",")
+	syms := make([]domain.Symbol, 0, len(parts))
+	seen := make(map[domain.Symbol]bool, len(parts))
+	for _, p := range parts {
+		sym, err := domain.NewSymbol(strings.TrimSpace(p))
+		if err != nil {
+			return nil, err
+		}
+		if seen[sym] {
+			continue
+		}
+		seen[sym] = true
+		syms = append(syms, sym)
+	}
+	return syms, nil
+}
+
+// sortByRangeScore orders entries by descending range score. Entries without a
+// score keep their relative order after all scored entries.
+func sortByRangeScore(entries []overviewSymbol) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].Range, entries[j].Range
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return a.Score > b.Score
+	})
+}
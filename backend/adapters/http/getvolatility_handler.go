@@ -0,0 +1,121 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/akarso/pano_chart/backend/application/usecases"
+	"github.com/akarso/pano_chart/backend/domain/indicators"
+)
+
+// volatilitySymbol is one symbol's entry; a volatility value is null when the
+// series is too short for that estimator.
+type volatilitySymbol struct {
+	Symbol     string              `json:"symbol"`
+	Candles    int                 `json:"candles"`
+	Volatility map[string]*float64 `json:"volatility"`
+	Error      *errorDetail        `json:"error,omitempty"`
+}
+
+type volatilityResponse struct {
+	Timeframe string             `json:"timeframe"`
+	Symbols   []volatilitySymbol `json:"symbols"`
+}
+
+// NewGetVolatilityHandler constructs an http.HandlerFunc that adapts HTTP requests
+// to the GetVolatility use case.
+//
+// Query parameters:
+//   - symbols, timeframe, from, to: as for /api/v1/overview
+//   - estimators: comma-separated subset of cc, parkinson, gk, rs (optional; default all)
+//   - sort: an estimator name; orders symbols by descending volatility for that estimator
+func NewGetVolatilityHandler(uc usecases.GetVolatility) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		p, ok := parseMultiSymbolParams(w, q)
+		if !ok {
+			return
+		}
+
+		estimators := indicators.Estimators
+		if s := q.Get("estimators"); s != "" {
+			estimators = nil
+			seen := map[indicators.Estimator]bool{}
+			for _, part := range strings.Split(s, ",") {
+				est, err := indicators.ParseEstimator(part)
+				if err != nil {
+					writeErrorFrom(w, err)
+					return
+				}
+				if !seen[est] {
+					seen[est] = true
+					estimators = append(estimators, est)
+				}
+			}
+		}
+
+		var sortBy indicators.Estimator
+		if s := q.Get("sort"); s != "" {
+			est, err := indicators.ParseEstimator(s)
+			if err != nil || !containsEstimator(estimators, est) {
+				writeError(w, http.StatusBadRequest, CodeInvalidParameter, fmt.Sprintf("sort must be one of the requested estimators, got %q", s))
+				return
+			}
+			sortBy = est
+		}
+
+		report, err := uc.Execute(r.Context(), p.symbols, p.tf, p.from, p.to, estimators)
+		if err != nil {
+			writeErrorFrom(w, err)
+			return
+		}
+
+		resp := volatilityResponse{
+			Timeframe: report.Timeframe.String(),
+			Symbols:   make([]volatilitySymbol, len(report.Entries)),
+		}
+		for i, e := range report.Entries {
+			entry := volatilitySymbol{Symbol: e.Symbol.String(), Candles: e.Candles, Volatility: map[string]*float64{}}
+			if e.Err != nil {
+				_, detail := classifyError(e.Err)
+				entry.Error = &detail
+			}
+			for _, v := range e.Values {
+				entry.Volatility[string(v.Estimator)] = nil
+				if v.Valid {
+					value := v.Value
+					entry.Volatility[string(v.Estimator)] = &value
+				}
+			}
+			resp.Symbols[i] = entry
+		}
+		if sortBy != "" {
+			sortByVolatility(resp.Symbols, string(sortBy))
+		}
+
+		writeJSON(w, resp)
+	}
+}
+
+func containsEstimator(list []indicators.Estimator, est indicators.Estimator) bool {
+	for _, e := range list {
+		if e == est {
+			return true
+		}
+	}
+	return false
+}
+
+// sortByVolatility orders entries by descending volatility for the given estimator.
+// Entries without a value keep their relative order after all valued entries.
+func sortByVolatility(entries []volatilitySymbol, est string) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].Volatility[est], entries[j].Volatility[est]
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return *a > *b
+	})
+}
@@ -0,0 +1,121 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/ports"
+)
+
+// WithRequestTimeout bounds the context of every request passed to next by d, so use
+// cases and repositories abandon work that outlives it. A request that times out is
+// answered with 504 TIMEOUT by the handler's usual error mapping. d <= 0 returns next
+// unchanged.
+func WithRequestTimeout(next http.Handler, d time.Duration) http.Handler {
+	if d <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), d)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// staleWarning is the RFC 7234 warning attached to responses built from stale data.
+const staleWarning = `110 - "Response is Stale"`
+
+// WithStaleWarning tracks staleness (see ports.WithStaleTracking) for every request passed
+// to next, and adds a Warning header to the response if a repository served stale data
+// before the header was written.
+func WithStaleWarning(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, stale := ports.WithStaleTracking(r.Context())
+		next.ServeHTTP(&headerHookWriter{ResponseWriter: w, hook: func(h http.Header) {
+			if stale() {
+				h.Add("Warning", staleWarning)
+			}
+		}}, r.WithContext(ctx))
+	})
+}
+
+// qualityHeader is the response header summarizing the upstream candles rejected or
+// repaired while serving the request.
+const qualityHeader = "X-Data-Quality"
+
+// WithQualityHeader collects a data-quality report (see ports.WithQualityReport) for
+// every request passed to next. If repositories rejected or repaired upstream candles
+// before the header was written, the response carries an X-Data-Quality header such as
+// "rejected=2, repaired=1".
+func WithQualityHeader(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, issues := ports.WithQualityReport(r.Context())
+		next.ServeHTTP(&headerHookWriter{ResponseWriter: w, hook: func(h http.Header) {
+			var rejected, repaired int
+			for _, issue := range issues() {
+				if issue.Repaired {
+					repaired++
+				} else {
+					rejected++
+				}
+			}
+			if rejected+repaired > 0 {
+				h.Set(qualityHeader, fmt.Sprintf("rejected=%d, repaired=%d", rejected, repaired))
+			}
+		}}, r.WithContext(ctx))
+	})
+}
+
+// sourcesHeader is the response header listing the providers candles were served from.
+const sourcesHeader = "X-Data-Sources"
+
+// WithSourcesHeader tracks provenance (see ports.WithProvenance) for every request passed
+// to next. If repositories combining several providers reported where candles came from
+// before the header was written, the response carries an X-Data-Sources header listing
+// those providers once each, in order of their first candle, such as "primary, backup".
+func WithSourcesHeader(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, ranges := ports.WithProvenance(r.Context())
+		next.ServeHTTP(&headerHookWriter{ResponseWriter: w, hook: func(h http.Header) {
+			recorded := ranges()
+			sort.SliceStable(recorded, func(i, j int) bool { return recorded[i].From.Before(recorded[j].From) })
+			var sources []string
+			seen := make(map[string]bool)
+			for _, rng := range recorded {
+				if !seen[rng.Source] {
+					seen[rng.Source] = true
+					sources = append(sources, rng.Source)
+				}
+			}
+			if len(sources) > 0 {
+				h.Set(sourcesHeader, strings.Join(sources, ", "))
+			}
+		}}, r.WithContext(ctx))
+	})
+}
+
+// headerHookWriter calls hook on the response header just before it is written.
+type headerHookWriter struct {
+	http.ResponseWriter
+	hook        func(http.Header)
+	wroteHeader bool
+}
+
+func (w *headerHookWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.hook(w.Header())
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *headerHookWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
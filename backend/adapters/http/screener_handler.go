@@ -0,0 +1,158 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/akarso/pano_chart/backend/application/usecases"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+const (
+	// maxScreenerSymbols bounds the universe a single screener request may scan.
+	maxScreenerSymbols = 250
+	// maxScreenerLookback bounds the number of candles loaded per symbol.
+	maxScreenerLookback = 1000
+)
+
+type screenerRowJSON struct {
+	Symbol  string             `json:"symbol"`
+	Metrics map[string]float64 `json:"metrics"`
+}
+
+type screenerFailureJSON struct {
+	Symbol string      `json:"symbol"`
+	Error  errorDetail `json:"error"`
+}
+
+type screenerResponse struct {
+	Timeframe string                `json:"timeframe"`
+	From      interface{}           `json:"from"`
+	To        interface{}           `json:"to"`
+	Rows      []screenerRowJSON     `json:"rows"`
+	Errors    []screenerFailureJSON `json:"errors"`
+}
+
+// NewScreenerHandler constructs an http.HandlerFunc that adapts HTTP requests
+// to the Screener use case. universe is scanned when the request names no symbols.
+//
+// Query parameters:
+//   - timeframe: canonical timeframe (required)
+//   - symbols: comma-separated universe (optional when a default universe is configured)
+//   - lookback: candles per symbol, ending with the current candle (default 100)
+//   - filter: conditions joined by AND, e.g. "volatility > 0.5 AND change_24h < 2"
+//   - sort: metric to order by; order: desc (default) or asc
+//   - limit: maximum number of rows (default: all)
+//   - format: timestamp rendering, epoch_ms (default) or rfc3339
+func NewScreenerHandler(uc usecases.Screener, universe []domain.Symbol) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		tfStr := q.Get("timeframe")
+		if tfStr == "" {
+			writeError(w, http.StatusBadRequest, CodeMissingParameter, "timeframe is required")
+			return
+		}
+		tf, err := domain.NewTimeframe(tfStr)
+		if err != nil {
+			writeErrorFrom(w, err)
+			return
+		}
+
+		format, err := parseTimestampFormat(q.Get("format"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+			return
+		}
+
+		syms := universe
+		if s := q.Get("symbols"); s != "" {
+			if syms, err = parseSymbolList(s); err != nil {
+				writeErrorFrom(w, err)
+				return
+			}
+		}
+		if len(syms) == 0 {
+			writeError(w, http.StatusBadRequest, CodeMissingParameter, "symbols is required when no default universe is configured")
+			return
+		}
+		if len(syms) > maxScreenerSymbols {
+			writeError(w, http.StatusBadRequest, CodeInvalidParameter, fmt.Sprintf("at most %d symbols are allowed", maxScreenerSymbols))
+			return
+		}
+
+		lookback, ok := parseIntParam(w, q.Get("lookback"), "lookback", defaultOverviewCandles, 2, maxScreenerLookback)
+		if !ok {
+			return
+		}
+		limit, ok := parseIntParam(w, q.Get("limit"), "limit", 0, 1, maxScreenerSymbols)
+		if !ok {
+			return
+		}
+
+		filter, err := usecases.ParseFilter(q.Get("filter"))
+		if err != nil {
+			writeErrorFrom(w, err)
+			return
+		}
+
+		query := usecases.ScreenerQuery{Symbols: syms, Timeframe: tf, Lookback: lookback, Filter: filter, Limit: limit}
+		if s := q.Get("sort"); s != "" {
+			if query.SortBy, err = usecases.ParseMetric(s); err != nil {
+				writeErrorFrom(w, err)
+				return
+			}
+		}
+		switch strings.ToLower(q.Get("order")) {
+		case "", "desc":
+		case "asc":
+			query.Ascending = true
+		default:
+			writeError(w, http.StatusBadRequest, CodeInvalidParameter, "order must be asc or desc")
+			return
+		}
+
+		res, err := uc.Execute(r.Context(), query)
+		if err != nil {
+			writeErrorFrom(w, err)
+			return
+		}
+
+		resp := screenerResponse{
+			Timeframe: res.Timeframe.String(),
+			From:      format.render(res.From),
+			To:        format.render(res.To),
+			Rows:      make([]screenerRowJSON, len(res.Rows)),
+			Errors:    make([]screenerFailureJSON, len(res.Failures)),
+		}
+		for i, row := range res.Rows {
+			metrics := make(map[string]float64, len(row.Metrics))
+			for m, v := range row.Metrics {
+				metrics[string(m)] = v
+			}
+			resp.Rows[i] = screenerRowJSON{Symbol: row.Symbol.String(), Metrics: metrics}
+		}
+		for i, f := range res.Failures {
+			_, detail := classifyError(f.Err)
+			resp.Errors[i] = screenerFailureJSON{Symbol: f.Symbol.String(), Error: detail}
+		}
+
+		writeJSON(w, resp)
+	}
+}
+
+// parseIntParam parses an optional integer query parameter within [min, max].
+// An empty value yields def. On failure it writes the error response and returns false.
+func parseIntParam(w http.ResponseWriter, s, name string, def, min, max int) (int, bool) {
+	if s == "" {
+		return def, true
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < min || v > max {
+		writeError(w, http.StatusBadRequest, CodeInvalidParameter, fmt.Sprintf("%s must be an integer between %d and %d", name, min, max))
+		return 0, false
+	}
+	return v, true
+}
@@ -0,0 +1,156 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+const (
+	// maxStreamPairs bounds how many series a single stream connection may subscribe to.
+	maxStreamPairs = 50
+	// streamKeepAlive is the interval of the comments that keep idle streams open through
+	// proxies.
+	streamKeepAlive = 15 * time.Second
+)
+
+// candleEventJSON is the data of a candle event.
+type candleEventJSON struct {
+	Symbol    string     `json:"symbol"`
+	Timeframe string     `json:"timeframe"`
+	Closed    bool       `json:"closed"`
+	Candle    candleJSON `json:"candle"`
+}
+
+// NewStreamCandlesHandler constructs an http.HandlerFunc that streams candle updates
+// from stream as Server-Sent Events.
+//
+// Query parameters:
+//   - pairs: comma-separated symbol:timeframe pairs, at most maxStreamPairs (required)
+//   - format: timestamp rendering, epoch_ms (default) or rfc3339
+//
+// Every update is sent as a "candle" event. The stream stays open until the client
+// disconnects, or until it falls too far behind, in which case an "error" event with
+// code SLOW_CONSUMER is sent first. When stream refuses the subscription the request
+// fails with STREAM_LIMIT.
+//
+// The handler must not be wrapped in WithRequestTimeout; it lifts the server's write
+// timeout for its own connection.
+func NewStreamCandlesHandler(stream ports.CandleStreamPort) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		format, err := parseTimestampFormat(q.Get("format"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+			return
+		}
+		if q.Get("pairs") == "" {
+			writeError(w, http.StatusBadRequest, CodeMissingParameter, "pairs is required")
+			return
+		}
+		keys, err := parsePairList(q.Get("pairs"))
+		if err != nil {
+			writeErrorFrom(w, err)
+			return
+		}
+		if len(keys) > maxStreamPairs {
+			writeError(w, http.StatusBadRequest, CodeInvalidParameter, fmt.Sprintf("at most %d pairs are allowed", maxStreamPairs))
+			return
+		}
+
+		updates, err := stream.Subscribe(r.Context(), keys)
+		if err != nil {
+			writeErrorFrom(w, err)
+			return
+		}
+
+		rc := http.NewResponseController(w)
+		_ = rc.SetWriteDeadline(time.Time{})
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		if err := rc.Flush(); err != nil {
+			return
+		}
+
+		keepAlive := time.NewTicker(streamKeepAlive)
+		defer keepAlive.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-keepAlive.C:
+				_, err = fmt.Fprint(w, ": keep-alive\n\n")
+			case u, ok := <-updates:
+				if !ok {
+					if r.Context().Err() == nil {
+						_ = writeEvent(w, "error", errorEnvelope{Error: errorDetail{Code: CodeSlowConsumer, Message: "stream fell behind; reload and subscribe again"}})
+						_ = rc.Flush()
+					}
+					return
+				}
+				err = writeEvent(w, "candle", candleEventJSON{
+					Symbol:    u.Candle.Symbol().String(),
+					Timeframe: u.Candle.Timeframe().String(),
+					Closed:    u.Closed,
+					Candle: candleJSON{
+						Timestamp: format.render(u.Candle.Timestamp()),
+						Open:      u.Candle.Open(),
+						High:      u.Candle.High(),
+						Low:       u.Candle.Low(),
+						Close:     u.Candle.Close(),
+						Volume:    u.Candle.Volume(),
+					},
+				})
+			}
+			if err == nil {
+				err = rc.Flush()
+			}
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeEvent writes one Server-Sent Event with JSON data.
+func writeEvent(w http.ResponseWriter, event string, data any) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, b)
+	return err
+}
+
+// parsePairList splits a comma-separated list of symbol:timeframe pairs into validated,
+// de-duplicated series keys, preserving the order of first occurrence.
+func parsePairList(s string) ([]ports.SeriesKey, error) {
+	parts := strings.Split(s, ",")
+	keys := make([]ports.SeriesKey, 0, len(parts))
+	seen := make(map[ports.SeriesKey]bool, len(parts))
+	for _, p := range parts {
+		symStr, tfStr, _ := strings.Cut(strings.TrimSpace(p), ":")
+		sym, err := domain.NewSymbol(symStr)
+		if err != nil {
+			return nil, err
+		}
+		tf, err := domain.NewTimeframe(tfStr)
+		if err != nil {
+			return nil, err
+		}
+		key := ports.SeriesKey{Symbol: sym, Timeframe: tf}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
@@ -0,0 +1,52 @@
+package http
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timestampFormat selects how candle timestamps are rendered in responses.
+type timestampFormat string
+
+const (
+	// formatEpochMillis renders timestamps as UTC epoch milliseconds (COMMON.md default).
+	formatEpochMillis timestampFormat = "epoch_ms"
+	// formatRFC3339 renders timestamps as RFC3339 strings (legacy PR-011 shape).
+	formatRFC3339 timestampFormat = "rfc3339"
+)
+
+// parseTimestampFormat reads the optional `format` query parameter.
+// An empty value selects epoch milliseconds.
+func parseTimestampFormat(s string) (timestampFormat, error) {
+	switch timestampFormat(strings.ToLower(strings.TrimSpace(s))) {
+	case "", formatEpochMillis:
+		return formatEpochMillis, nil
+	case formatRFC3339:
+		return formatRFC3339, nil
+	default:
+		return "", fmt.Errorf("unsupported format %q (use %s or %s)", s, formatEpochMillis, formatRFC3339)
+	}
+}
+
+// render converts t into the JSON value for this format.
+func (f timestampFormat) render(t time.Time) interface{} {
+	if f == formatRFC3339 {
+		return t.UTC().Format(time.RFC3339)
+	}
+	return t.UTC().UnixMilli()
+}
+
+// parseTimestamp accepts either UTC epoch milliseconds or an RFC3339 string
+// and returns the instant in UTC.
+func parseTimestamp(s string) (time.Time, error) {
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.UnixMilli(ms).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.UTC(), nil
+}
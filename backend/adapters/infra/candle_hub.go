@@ -0,0 +1,154 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/akarso/pano_chart/backend/application/ports"
+)
+
+const (
+	// DefaultHubBuffer is the number of updates a subscriber may fall behind when
+	// NewCandleHub is given a non-positive buffer.
+	DefaultHubBuffer = 256
+	// DefaultHubMaxSubscribers bounds the concurrent subscriptions of a CandleHub.
+	DefaultHubMaxSubscribers = 1000
+	// DefaultHubMaxSeries bounds the distinct series watched through a CandleHub, and so
+	// the series polled from the provider.
+	DefaultHubMaxSeries = 100
+)
+
+// CandleHub implements ports.CandleStreamPort in process memory. It is safe for
+// concurrent use.
+//
+// Publishing never waits for subscribers: each subscriber has a buffer of pending
+// updates, and one whose buffer is full when an update arrives is dropped, its channel
+// closed, rather than slowing publishers and every other subscriber down. Clients of a
+// dropped subscription should reload the series and subscribe again.
+//
+// The number of subscriptions and of distinct watched series are bounded (see WithLimits),
+// so clients cannot make the hub's publishers poll an unbounded set of series.
+type CandleHub struct {
+	buffer         int
+	maxSubscribers int
+	maxSeries      int
+
+	mu          sync.Mutex
+	byKey       map[ports.SeriesKey]map[*hubSubscriber]struct{}
+	subscribers int
+}
+
+// hubSubscriber is one subscription.
+type hubSubscriber struct {
+	keys    []ports.SeriesKey
+	updates chan ports.CandleUpdate
+	dropped bool
+}
+
+// NewCandleHub constructs a hub giving every subscriber room for buffer pending updates.
+func NewCandleHub(buffer int) *CandleHub {
+	if buffer <= 0 {
+		buffer = DefaultHubBuffer
+	}
+	return &CandleHub{
+		buffer:         buffer,
+		maxSubscribers: DefaultHubMaxSubscribers,
+		maxSeries:      DefaultHubMaxSeries,
+		byKey:          make(map[ports.SeriesKey]map[*hubSubscriber]struct{}),
+	}
+}
+
+// WithLimits bounds the concurrent subscriptions and the distinct watched series; values
+// <= 0 keep the current limit. It returns h for chaining.
+func (h *CandleHub) WithLimits(subscribers, series int) *CandleHub {
+	if subscribers > 0 {
+		h.maxSubscribers = subscribers
+	}
+	if series > 0 {
+		h.maxSeries = series
+	}
+	return h
+}
+
+// Subscribe implements ports.CandleStreamPort. Duplicate keys are subscribed once.
+func (h *CandleHub) Subscribe(ctx context.Context, keys []ports.SeriesKey) (<-chan ports.CandleUpdate, error) {
+	sub := &hubSubscriber{updates: make(chan ports.CandleUpdate, h.buffer)}
+	h.mu.Lock()
+	if h.subscribers >= h.maxSubscribers {
+		h.mu.Unlock()
+		return nil, fmt.Errorf("%w: %d subscribers", ports.ErrStreamFull, h.maxSubscribers)
+	}
+	added := make(map[ports.SeriesKey]bool)
+	for _, key := range keys {
+		if _, ok := h.byKey[key]; !ok {
+			added[key] = true
+		}
+	}
+	if len(h.byKey)+len(added) > h.maxSeries {
+		h.mu.Unlock()
+		return nil, fmt.Errorf("%w: %d watched series", ports.ErrStreamFull, h.maxSeries)
+	}
+	h.subscribers++
+	for _, key := range keys {
+		subs := h.byKey[key]
+		if subs == nil {
+			subs = make(map[*hubSubscriber]struct{})
+			h.byKey[key] = subs
+		}
+		if _, ok := subs[sub]; !ok {
+			subs[sub] = struct{}{}
+			sub.keys = append(sub.keys, key)
+		}
+	}
+	h.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.drop(sub)
+	}()
+	return sub.updates, nil
+}
+
+// Publish implements ports.CandlePublisherPort.
+func (h *CandleHub) Publish(update ports.CandleUpdate) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.byKey[update.Key()] {
+		select {
+		case sub.updates <- update:
+		default:
+			h.drop(sub)
+		}
+	}
+}
+
+// Watched implements ports.CandlePublisherPort.
+func (h *CandleHub) Watched() []ports.SeriesKey {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	keys := make([]ports.SeriesKey, 0, len(h.byKey))
+	for key := range h.byKey {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// drop unsubscribes sub and closes its channel, unless that was already done.
+// h.mu must be held.
+func (h *CandleHub) drop(sub *hubSubscriber) {
+	if sub.dropped {
+		return
+	}
+	for _, key := range sub.keys {
+		delete(h.byKey[key], sub)
+		if len(h.byKey[key]) == 0 {
+			delete(h.byKey, key)
+		}
+	}
+	sub.dropped = true
+	h.subscribers--
+	close(sub.updates)
+}
@@ -0,0 +1,109 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// CoalescingCandleRepository is a decorator that collapses concurrent identical
+// GetSeries calls into a single call to the wrapped repository and shares the result
+// (series or error) with every waiter. Calls are identical when symbol, timeframe, from
+// and to all match. Results are not retained once the call completes; pair it with a
+// caching decorator for that.
+//
+// The shared call runs detached from any single caller's cancellation: a caller whose
+// context ends stops waiting and gets ctx.Err(), while the others keep waiting. The
+// shared call itself is canceled only once every waiter has gone. If the shared call
+// served stale data, every waiter's context is marked with ports.MarkStale.
+type CoalescingCandleRepository struct {
+	wrapped ports.CandleRepositoryPort
+
+	mu       sync.Mutex
+	inFlight map[string]*coalescedCall
+}
+
+// coalescedCall is one in-flight call to the wrapped repository.
+type coalescedCall struct {
+	done    chan struct{}
+	series  domain.CandleSeries
+	err     error
+	stale   bool
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// NewCoalescingCandleRepository constructs the decorator.
+func NewCoalescingCandleRepository(wrapped ports.CandleRepositoryPort) *CoalescingCandleRepository {
+	return &CoalescingCandleRepository{wrapped: wrapped, inFlight: make(map[string]*coalescedCall)}
+}
+
+// coalesceKey identifies identical requests. Instants are compared exactly.
+func coalesceKey(symbol domain.Symbol, tf domain.Timeframe, from, to time.Time) string {
+	return fmt.Sprintf("%s|%s|%d|%d", symbol.String(), tf.String(), from.UnixNano(), to.UnixNano())
+}
+
+// GetSeries implements the ports.CandleRepositoryPort interface.
+func (r *CoalescingCandleRepository) GetSeries(ctx context.Context, symbol domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.CandleSeries{}, err
+	}
+	key := coalesceKey(symbol, tf, from, to)
+
+	r.mu.Lock()
+	call, ok := r.inFlight[key]
+	if !ok {
+		// Keep the first caller's values (trace IDs etc.) but not its cancellation.
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &coalescedCall{done: make(chan struct{}), cancel: cancel}
+		r.inFlight[key] = call
+		go r.run(callCtx, key, call, symbol, tf, from, to)
+	}
+	call.waiters++
+	r.mu.Unlock()
+
+	select {
+	case <-call.done:
+		if call.stale {
+			ports.MarkStale(ctx)
+		}
+		return call.series, call.err
+	case <-ctx.Done():
+		r.leave(key, call)
+		return domain.CandleSeries{}, ctx.Err()
+	}
+}
+
+// run performs the shared call and publishes its result.
+func (r *CoalescingCandleRepository) run(ctx context.Context, key string, call *coalescedCall, symbol domain.Symbol, tf domain.Timeframe, from, to time.Time) {
+	defer call.cancel()
+	ctx, stale := ports.WithStaleTracking(ctx)
+	call.series, call.err = r.wrapped.GetSeries(ctx, symbol, tf, from, to)
+	call.stale = stale()
+
+	r.mu.Lock()
+	if r.inFlight[key] == call {
+		delete(r.inFlight, key)
+	}
+	r.mu.Unlock()
+	close(call.done)
+}
+
+// leave unregisters a waiter whose context ended. When no waiters remain the shared
+// call is canceled and forgotten, so a later identical request starts afresh.
+func (r *CoalescingCandleRepository) leave(key string, call *coalescedCall) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	call.waiters--
+	if call.waiters > 0 {
+		return
+	}
+	call.cancel()
+	if r.inFlight[key] == call {
+		delete(r.inFlight, key)
+	}
+}
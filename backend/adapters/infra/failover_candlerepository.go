@@ -0,0 +1,173 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// NamedRepository is a provider together with the name used for it in errors and
+// provenance.
+type NamedRepository struct {
+	Name string
+	Repo ports.CandleRepositoryPort
+}
+
+// FailoverCandleRepository is a composite that implements ports.CandleRepositoryPort over
+// an ordered list of providers.
+//
+// Providers are consulted in order until one returns a non-empty series; errors and empty
+// results fail over to the next. With merging enabled, later providers are also asked for
+// the span between the first and last gap of the series so far, and their candles fill
+// the gaps; earlier providers win on equal timestamps. Gaps are the missing candles between
+// two (see CandleSeries.HasGapAfter) as well as those missing at either edge of the
+// requested range, as in a truncated response; candles not yet open are not missing.
+// The source of every contiguous candle run is reported with ports.RecordProvenance.
+//
+// If no provider has candles and any of them failed, the errors are joined, each
+// prefixed by its provider's name. If none fails but all are empty, the empty series is
+// returned.
+type FailoverCandleRepository struct {
+	providers []NamedRepository
+	merge     bool
+	now       func() time.Time
+}
+
+// NewFailoverCandleRepository constructs the composite. At least one provider is required.
+func NewFailoverCandleRepository(providers ...NamedRepository) *FailoverCandleRepository {
+	if len(providers) == 0 {
+		panic("at least one provider is required")
+	}
+	return &FailoverCandleRepository{providers: providers, now: time.Now}
+}
+
+// WithMerge enables filling gaps from later providers. It returns r for chaining.
+func (r *FailoverCandleRepository) WithMerge(merge bool) *FailoverCandleRepository {
+	r.merge = merge
+	return r
+}
+
+// WithClock overrides the clock that decides which candles have opened; intended for
+// tests. It returns r for chaining.
+func (r *FailoverCandleRepository) WithClock(now func() time.Time) *FailoverCandleRepository {
+	r.now = now
+	return r
+}
+
+// GetSeries implements the ports.CandleRepositoryPort interface.
+func (r *FailoverCandleRepository) GetSeries(ctx context.Context, symbol domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+	var (
+		result  domain.CandleSeries
+		sources map[int64]string // candle timestamp (ns) -> provider name
+		errs    []error
+	)
+	for _, p := range r.providers {
+		qFrom, qTo := from, to
+		if sources != nil {
+			var gaps bool
+			if qFrom, qTo, gaps = gapSpan(result, from, r.openUntil(to)); !r.merge || !gaps {
+				break
+			}
+		}
+
+		series, err := p.Repo.GetSeries(ctx, symbol, tf, qFrom, qTo)
+		if err != nil {
+			if ctx.Err() != nil {
+				return domain.CandleSeries{}, err
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", p.Name, err))
+			continue
+		}
+		if series.Len() == 0 {
+			continue
+		}
+
+		if sources == nil {
+			sources = make(map[int64]string, series.Len())
+			for _, c := range series.All() {
+				sources[c.Timestamp().UnixNano()] = p.Name
+			}
+			result = series
+			continue
+		}
+		candles := result.All()
+		for _, c := range series.Between(qFrom, qTo).All() {
+			if _, ok := sources[c.Timestamp().UnixNano()]; !ok {
+				sources[c.Timestamp().UnixNano()] = p.Name
+				candles = append(candles, c)
+			}
+		}
+		if result, err = domain.NewCandleSeries(symbol, tf, candles); err != nil {
+			return domain.CandleSeries{}, err
+		}
+	}
+
+	if sources == nil {
+		// An empty answer does not outweigh a failure: the failed provider may have data.
+		if len(errs) > 0 {
+			return domain.CandleSeries{}, errors.Join(errs...)
+		}
+		return domain.NewCandleSeries(symbol, tf, nil)
+	}
+	ports.RecordProvenance(ctx, sourceRuns(result, sources)...)
+	return result, nil
+}
+
+// openUntil returns to, or now if earlier: no candle opens after it.
+func (r *FailoverCandleRepository) openUntil(to time.Time) time.Time {
+	if now := r.now(); now.Before(to) {
+		return now
+	}
+	return to
+}
+
+// gapSpan returns the span from the start of the first gap in the non-empty series to
+// the end of the last one, and whether the series has any gap. Candles missing between
+// from and the first candle, or between the last candle and to, count as gaps.
+func gapSpan(series domain.CandleSeries, from, to time.Time) (time.Time, time.Time, bool) {
+	step := series.Timeframe().Duration()
+	var start, end time.Time
+	found := false
+	if first, err := series.First(); err == nil && first.Timestamp().Sub(from) >= step {
+		start, end, found = from, first.Timestamp(), true
+	}
+	for i := 0; i < series.Len()-1; i++ {
+		if !series.HasGapAfter(i) {
+			continue
+		}
+		c, _ := series.At(i)
+		next, _ := series.At(i + 1)
+		if !found {
+			start = c.Timestamp().Add(step)
+			found = true
+		}
+		end = next.Timestamp()
+	}
+	if last, err := series.Last(); err == nil && last.Timestamp().Add(step).Before(to) {
+		if !found {
+			start = last.Timestamp().Add(step)
+			found = true
+		}
+		end = to
+	}
+	return start, end, found
+}
+
+// sourceRuns splits series into contiguous runs of candles from the same source.
+func sourceRuns(series domain.CandleSeries, sources map[int64]string) []ports.SourceRange {
+	step := series.Timeframe().Duration()
+	var runs []ports.SourceRange
+	for i, c := range series.All() {
+		src := sources[c.Timestamp().UnixNano()]
+		if n := len(runs); n > 0 && runs[n-1].Source == src && !series.HasGapAfter(i-1) {
+			runs[n-1].To = c.Timestamp().Add(step)
+			continue
+		}
+		runs = append(runs, ports.SourceRange{Source: src, From: c.Timestamp(), To: c.Timestamp().Add(step)})
+	}
+	return runs
+}
@@ -0,0 +1,467 @@
+package infra
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// defaultSegmentRecords is the number of records after which a new segment is started.
+// A read loads every segment overlapping the requested range in full, so segments are
+// kept small: at about 100 bytes per record a segment stays around 400 KiB, and a chart
+// request of a few hundred candles written in order reads one or two of them. Large
+// writes are split across segments so that none exceeds the limit.
+const defaultSegmentRecords = 4096
+
+const (
+	storeIndexFile    = "index.json"
+	storeSegmentGlob  = "seg-*.log"
+	storeSegmentName  = "seg-%06d.log"
+	storeCandleRecord = "c"
+	storeRangeRecord  = "r"
+)
+
+// FileCandleStore implements ports.CandleStorePort on the local filesystem.
+//
+// Every symbol and timeframe has its own directory of append-only segment files holding
+// one JSON record per line: a candle, or a covered range. Records are never rewritten; a
+// later candle with the same timestamp supersedes an earlier one. The directory's
+// index.json records each segment's size and candle time bounds, so reads only open the
+// segments overlapping the requested range, together with the merged covered ranges.
+// The index is a cache of the segments: it is rebuilt when missing or out of date, and a
+// record cut short by a crash is discarded at that point.
+//
+// A FileCandleStore is safe for concurrent use, but the directory must not be shared with
+// another process. Writes are serialized per series; reads take a snapshot of the index
+// and read the segment files without holding any lock, which is safe because records
+// are only ever appended past the sizes the index records.
+type FileCandleStore struct {
+	dir            string
+	segmentRecords int
+
+	mu     sync.Mutex // guards series
+	series map[string]*storedSeries
+}
+
+// storedSeries is the state of one symbol and timeframe. Its lock serializes loading and
+// writes; index is replaced, never modified in place, so a copy taken under the read
+// lock stays valid.
+type storedSeries struct {
+	dir string
+
+	mu     sync.RWMutex
+	loaded bool
+	index  storeIndex
+}
+
+// storeIndex is the content of index.json. Times are epoch milliseconds.
+type storeIndex struct {
+	Segments []segmentMeta `json:"segments"`
+	Covered  [][2]int64    `json:"covered"`
+}
+
+// segmentMeta describes one segment file. First and Last bound its candle timestamps
+// and are meaningful only when Candles > 0.
+type segmentMeta struct {
+	Name    string `json:"name"`
+	Size    int64  `json:"size"`
+	Records int    `json:"records"`
+	Candles int    `json:"candles"`
+	First   int64  `json:"first"`
+	Last    int64  `json:"last"`
+}
+
+// storeRecord is one line of a segment. Candle records carry the timestamp in T;
+// range records cover [T, U).
+type storeRecord struct {
+	Kind   string  `json:"k"`
+	T      int64   `json:"t"`
+	U      int64   `json:"u,omitempty"`
+	Open   float64 `json:"o,omitempty"`
+	High   float64 `json:"h,omitempty"`
+	Low    float64 `json:"l,omitempty"`
+	Close  float64 `json:"c,omitempty"`
+	Volume float64 `json:"v,omitempty"`
+}
+
+// NewFileCandleStore opens the store rooted at dir, creating the directory if needed.
+func NewFileCandleStore(dir string) (*FileCandleStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("candle store: %w", err)
+	}
+	return &FileCandleStore{dir: dir, segmentRecords: defaultSegmentRecords, series: make(map[string]*storedSeries)}, nil
+}
+
+// WithSegmentRecords sets the number of records after which a new segment is started,
+// which bounds how much a read of one segment loads. n <= 0 keeps the current value. It
+// returns s for chaining.
+func (s *FileCandleStore) WithSegmentRecords(n int) *FileCandleStore {
+	if n > 0 {
+		s.segmentRecords = n
+	}
+	return s
+}
+
+// Upsert implements ports.CandleStorePort. The records are appended to the active segment
+// and synced before the index is updated.
+func (s *FileCandleStore) Upsert(ctx context.Context, series domain.CandleSeries, covered ports.TimeRange) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("candle store: %w", err)
+	}
+	records := make([]storeRecord, 0, series.Len()+1)
+	for _, c := range series.All() {
+		records = append(records, storeRecord{
+			Kind: storeCandleRecord, T: c.Timestamp().UnixMilli(),
+			Open: c.Open(), High: c.High(), Low: c.Low(), Close: c.Close(), Volume: c.Volume(),
+		})
+	}
+	if covered.To.After(covered.From) {
+		records = append(records, storeRecord{Kind: storeRangeRecord, T: covered.From.UnixMilli(), U: covered.To.UnixMilli()})
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	ss := s.entry(series.Symbol(), series.Timeframe())
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if err := ss.load(); err != nil {
+		return err
+	}
+	if err := s.appendRecords(ss, records); err != nil {
+		// The segment may hold a partial write; reload and repair it on next use.
+		ss.loaded = false
+		return fmt.Errorf("candle store: %w", err)
+	}
+	return nil
+}
+
+// GetSeries implements ports.CandleRepositoryPort with the candles stored in [from, to).
+func (s *FileCandleStore) GetSeries(ctx context.Context, symbol domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.CandleSeries{}, fmt.Errorf("candle store: %w", err)
+	}
+	lo, hi := from.UnixMilli(), to.UnixMilli()
+	dir, idx, err := s.snapshot(symbol, tf)
+	if err != nil {
+		return domain.CandleSeries{}, err
+	}
+
+	latest := make(map[int64]storeRecord)
+	for _, seg := range idx.Segments {
+		if seg.Candles == 0 || seg.Last < lo || seg.First >= hi {
+			continue
+		}
+		records, _, err := readSegment(filepath.Join(dir, seg.Name), seg.Size)
+		if err != nil {
+			return domain.CandleSeries{}, fmt.Errorf("candle store: %w", err)
+		}
+		for _, rec := range records {
+			if rec.Kind == storeCandleRecord && rec.T >= lo && rec.T < hi {
+				latest[rec.T] = rec
+			}
+		}
+	}
+
+	candles := make([]domain.Candle, 0, len(latest))
+	for _, rec := range latest {
+		c, err := domain.NewCandle(symbol, tf, time.UnixMilli(rec.T).UTC(), rec.Open, rec.High, rec.Low, rec.Close, rec.Volume)
+		if err != nil {
+			return domain.CandleSeries{}, fmt.Errorf("candle store: %w", err)
+		}
+		candles = append(candles, c)
+	}
+	return domain.NewCandleSeries(symbol, tf, candles)
+}
+
+// Covered implements ports.CandleStorePort.
+func (s *FileCandleStore) Covered(ctx context.Context, symbol domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) ([]ports.TimeRange, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("candle store: %w", err)
+	}
+	lo, hi := from.UnixMilli(), to.UnixMilli()
+	_, idx, err := s.snapshot(symbol, tf)
+	if err != nil {
+		return nil, err
+	}
+
+	var ranges []ports.TimeRange
+	for _, r := range idx.Covered {
+		start, end := max(r[0], lo), min(r[1], hi)
+		if start < end {
+			ranges = append(ranges, ports.TimeRange{From: time.UnixMilli(start).UTC(), To: time.UnixMilli(end).UTC()})
+		}
+	}
+	return ranges, nil
+}
+
+// seriesKey identifies a symbol and timeframe.
+func seriesKey(symbol domain.Symbol, tf domain.Timeframe) string {
+	return symbol.String() + "|" + tf.String()
+}
+
+// entry returns the state of symbol and timeframe, creating it unloaded on first use.
+func (s *FileCandleStore) entry(symbol domain.Symbol, tf domain.Timeframe) *storedSeries {
+	key := seriesKey(symbol, tf)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ss, ok := s.series[key]
+	if !ok {
+		ss = &storedSeries{dir: filepath.Join(s.dir, url.PathEscape(symbol.String()), tf.String())}
+		s.series[key] = ss
+	}
+	return ss
+}
+
+// snapshot returns the directory and current index of symbol and timeframe, loading
+// them on first use.
+func (s *FileCandleStore) snapshot(symbol domain.Symbol, tf domain.Timeframe) (string, storeIndex, error) {
+	ss := s.entry(symbol, tf)
+	ss.mu.RLock()
+	if ss.loaded {
+		defer ss.mu.RUnlock()
+		return ss.dir, ss.index, nil
+	}
+	ss.mu.RUnlock()
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if err := ss.load(); err != nil {
+		return "", storeIndex{}, err
+	}
+	return ss.dir, ss.index, nil
+}
+
+// load reads or rebuilds the index unless it is loaded. The caller must hold ss.mu.
+func (ss *storedSeries) load() error {
+	if ss.loaded {
+		return nil
+	}
+	if err := os.MkdirAll(ss.dir, 0o755); err != nil {
+		return fmt.Errorf("candle store: %w", err)
+	}
+	idx, ok := loadIndex(ss.dir)
+	if !ok {
+		var err error
+		if idx, err = rebuildIndex(ss.dir); err != nil {
+			return fmt.Errorf("candle store: %w", err)
+		}
+		if err := writeIndex(ss.dir, idx); err != nil {
+			return fmt.Errorf("candle store: %w", err)
+		}
+	}
+	ss.index, ss.loaded = idx, true
+	return nil
+}
+
+// appendRecords appends records to the active segment of ss, starting new segments as
+// each fills up, syncs them and updates the index. The caller must hold ss.mu.
+func (s *FileCandleStore) appendRecords(ss *storedSeries, records []storeRecord) error {
+	idx := ss.index
+	idx.Segments = append([]segmentMeta(nil), idx.Segments...)
+	for len(records) > 0 {
+		if n := len(idx.Segments); n == 0 || idx.Segments[n-1].Records >= s.segmentRecords {
+			idx.Segments = append(idx.Segments, segmentMeta{Name: fmt.Sprintf(storeSegmentName, n+1)})
+		}
+		seg := &idx.Segments[len(idx.Segments)-1]
+		batch := records[:min(len(records), s.segmentRecords-seg.Records)]
+		records = records[len(batch):]
+
+		size, err := appendSegment(filepath.Join(ss.dir, seg.Name), batch)
+		if err != nil {
+			return err
+		}
+		seg.Size += size
+		for _, rec := range batch {
+			idx.Covered = seg.add(rec, idx.Covered)
+		}
+	}
+	if err := writeIndex(ss.dir, idx); err != nil {
+		return err
+	}
+	ss.index = idx
+	return nil
+}
+
+// appendSegment appends records to the named segment and syncs it, returning the number
+// of bytes written.
+func appendSegment(name string, records []storeRecord) (int64, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, rec := range records {
+		if err := enc.Encode(rec); err != nil {
+			return 0, err
+		}
+	}
+
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		_ = f.Close()
+		return 0, err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return 0, err
+	}
+	return int64(buf.Len()), f.Close()
+}
+
+// add accounts for rec in m and returns covered with any range record merged in.
+func (m *segmentMeta) add(rec storeRecord, covered [][2]int64) [][2]int64 {
+	m.Records++
+	switch rec.Kind {
+	case storeCandleRecord:
+		if m.Candles == 0 || rec.T < m.First {
+			m.First = rec.T
+		}
+		if m.Candles == 0 || rec.T > m.Last {
+			m.Last = rec.T
+		}
+		m.Candles++
+	case storeRangeRecord:
+		covered = mergeRange(covered, [2]int64{rec.T, rec.U})
+	}
+	return covered
+}
+
+// mergeRange adds r to the sorted, disjoint ranges, merging overlapping or adjacent ones.
+func mergeRange(ranges [][2]int64, r [2]int64) [][2]int64 {
+	merged := make([][2]int64, 0, len(ranges)+1)
+	placed := false
+	for _, cur := range ranges {
+		switch {
+		case cur[1] < r[0]:
+			merged = append(merged, cur)
+		case cur[0] > r[1]:
+			if !placed {
+				merged = append(merged, r)
+				placed = true
+			}
+			merged = append(merged, cur)
+		default:
+			r = [2]int64{min(cur[0], r[0]), max(cur[1], r[1])}
+		}
+	}
+	if !placed {
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// loadIndex reads the index of dir and reports whether it matches the segment files.
+func loadIndex(dir string) (storeIndex, bool) {
+	b, err := os.ReadFile(filepath.Join(dir, storeIndexFile))
+	if err != nil {
+		return storeIndex{}, false
+	}
+	var idx storeIndex
+	if err := json.Unmarshal(b, &idx); err != nil {
+		return storeIndex{}, false
+	}
+	names, err := filepath.Glob(filepath.Join(dir, storeSegmentGlob))
+	if err != nil || len(names) != len(idx.Segments) {
+		return storeIndex{}, false
+	}
+	for _, seg := range idx.Segments {
+		info, err := os.Stat(filepath.Join(dir, seg.Name))
+		if err != nil || info.Size() != seg.Size {
+			return storeIndex{}, false
+		}
+	}
+	return idx, true
+}
+
+// rebuildIndex scans the segment files of dir, truncating any torn trailing record.
+func rebuildIndex(dir string) (storeIndex, error) {
+	names, err := filepath.Glob(filepath.Join(dir, storeSegmentGlob))
+	if err != nil {
+		return storeIndex{}, err
+	}
+	sort.Strings(names)
+
+	var idx storeIndex
+	for _, name := range names {
+		records, size, err := readSegment(name, -1)
+		if err != nil {
+			return storeIndex{}, err
+		}
+		if err := os.Truncate(name, size); err != nil {
+			return storeIndex{}, err
+		}
+		seg := segmentMeta{Name: filepath.Base(name), Size: size}
+		for _, rec := range records {
+			idx.Covered = seg.add(rec, idx.Covered)
+		}
+		idx.Segments = append(idx.Segments, seg)
+	}
+	return idx, nil
+}
+
+// writeIndex replaces the index of dir atomically.
+func writeIndex(dir string, idx storeIndex) error {
+	b, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(dir, storeIndexFile+".tmp")
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, storeIndexFile))
+}
+
+// readSegment reads the records in the first limit bytes of a segment (all of it if
+// limit < 0). Reading stops at the first incomplete or malformed line; the returned size
+// is the length of the valid prefix. Records appended past limit are not read.
+func readSegment(name string, limit int64) ([]storeRecord, int64, error) {
+	f, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = f.Close() }()
+	var r io.Reader = f
+	if limit >= 0 {
+		r = io.LimitReader(f, limit)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var (
+		records []storeRecord
+		size    int64
+	)
+	for {
+		i := bytes.IndexByte(b[size:], '\n')
+		if i < 0 {
+			break
+		}
+		var rec storeRecord
+		if err := json.Unmarshal(b[size:size+int64(i)], &rec); err != nil {
+			break
+		}
+		records = append(records, rec)
+		size += int64(i) + 1
+	}
+	return records, size, nil
+}
@@ -0,0 +1,76 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileCheckpointStore implements ports.CheckpointPort with a single JSON file that is
+// replaced atomically on every Save, so a crash leaves either the old or the new state.
+// It is safe for concurrent use within one process.
+type FileCheckpointStore struct {
+	path string
+
+	mu          sync.Mutex
+	checkpoints map[string]time.Time
+}
+
+// NewFileCheckpointStore opens the checkpoint file at path, which need not exist yet.
+func NewFileCheckpointStore(path string) (*FileCheckpointStore, error) {
+	checkpoints := make(map[string]time.Time)
+	b, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("checkpoint store: %w", err)
+	default:
+		if err := json.Unmarshal(b, &checkpoints); err != nil {
+			return nil, fmt.Errorf("checkpoint store: %w", err)
+		}
+	}
+	return &FileCheckpointStore{path: path, checkpoints: checkpoints}, nil
+}
+
+// Load implements ports.CheckpointPort.
+func (s *FileCheckpointStore) Load(_ context.Context, key string) (time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	progress, ok := s.checkpoints[key]
+	return progress, ok, nil
+}
+
+// Save implements ports.CheckpointPort.
+func (s *FileCheckpointStore) Save(_ context.Context, key string, progress time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := make(map[string]time.Time, len(s.checkpoints)+1)
+	for k, v := range s.checkpoints {
+		next[k] = v
+	}
+	next[key] = progress.UTC()
+
+	b, err := json.MarshalIndent(next, "", "  ")
+	if err != nil {
+		return fmt.Errorf("checkpoint store: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("checkpoint store: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return fmt.Errorf("checkpoint store: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("checkpoint store: %w", err)
+	}
+	s.checkpoints = next
+	return nil
+}
@@ -1,19 +1,27 @@
 package infra
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 
+	"github.com/akarso/pano_chart/backend/application/ports"
 	"github.com/akarso/pano_chart/backend/domain"
 )
 
 // FreeTierCandleRepository implements ports.CandleRepositoryPort using a free-tier HTTP API.
+//
+// Every GetSeries call is a single request; wrap the adapter in a
+// WindowedCandleRepository for providers that cap rows per response. By default one
+// invalid candle fails the series; see WithIngestPolicy.
 type FreeTierCandleRepository struct {
 	baseURL *url.URL
 	client  *http.Client
+	policy  IngestPolicy
 }
 
 // NewFreeTierCandleRepository constructs the adapter. BaseURL must be a valid URL.
@@ -25,13 +33,33 @@ func NewFreeTierCandleRepository(base string, client *http.Client) *FreeTierCand
 	return &FreeTierCandleRepository{baseURL: u, client: client}
 }
 
-// GetSeries implements CandleRepositoryPort. It performs a single request to the external API
+// WithIngestPolicy sets how invalid upstream candles are handled; the default is
+// IngestStrict. Dropped and repaired candles are reported with ports.RecordCandleIssues.
+// It returns r for chaining.
+func (r *FreeTierCandleRepository) WithIngestPolicy(p IngestPolicy) *FreeTierCandleRepository {
+	r.policy = p
+	return r
+}
+
+// GetSeries implements CandleRepositoryPort. It requests the range from the external API
 // and translates the response into domain.CandleSeries.
-func (r *FreeTierCandleRepository) GetSeries(symbol domain.Symbol, timeframe domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+// Non-2xx responses are reported as *ports.UpstreamStatusError, carrying any Retry-After
+// delay; transport failures wrap ports.ErrUpstreamUnavailable.
+// The request is bound to ctx: cancellation or an expired deadline aborts it and is
+// reported as an error wrapping ctx.Err() rather than as an upstream failure.
+func (r *FreeTierCandleRepository) GetSeries(ctx context.Context, symbol domain.Symbol, timeframe domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
 	if r.baseURL == nil {
 		return domain.CandleSeries{}, fmt.Errorf("invalid base URL")
 	}
+	candles, err := r.fetch(ctx, symbol, timeframe, from, to)
+	if err != nil {
+		return domain.CandleSeries{}, err
+	}
+	return domain.NewCandleSeries(symbol, timeframe, candles)
+}
 
+// fetch performs the request for [from, to).
+func (r *FreeTierCandleRepository) fetch(ctx context.Context, symbol domain.Symbol, timeframe domain.Timeframe, from time.Time, to time.Time) ([]domain.Candle, error) {
 	q := r.baseURL.Query()
 	q.Set("symbol", symbol.String())
 	q.Set("timeframe", timeframe.String())
@@ -41,19 +69,25 @@ func (r *FreeTierCandleRepository) GetSeries(symbol domain.Symbol, timeframe dom
 	endpoint := *r.baseURL
 	endpoint.RawQuery = q.Encode()
 
-	req, err := http.NewRequest(http.MethodGet, endpoint.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
 	if err != nil {
-		return domain.CandleSeries{}, err
+		return nil, err
 	}
 
 	resp, err := r.client.Do(req)
 	if err != nil {
-		return domain.CandleSeries{}, err
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("free-tier request aborted: %w", ctxErr)
+		}
+		return nil, fmt.Errorf("%w: %w", ports.ErrUpstreamUnavailable, err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return domain.CandleSeries{}, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+		return nil, &ports.UpstreamStatusError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
 	}
 
 	// Expected payload: JSON array of objects with timestamp (RFC3339), open, high, low, close, volume
@@ -68,25 +102,44 @@ func (r *FreeTierCandleRepository) GetSeries(symbol domain.Symbol, timeframe dom
 
 	dec := json.NewDecoder(resp.Body)
 	if err := dec.Decode(&items); err != nil {
-		return domain.CandleSeries{}, err
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("free-tier request aborted: %w", ctxErr)
+		}
+		return nil, err
 	}
 
 	candles := make([]domain.Candle, 0, len(items))
+	var issues []ports.CandleIssue
 	for _, it := range items {
-		ts, err := time.Parse(time.RFC3339, it.Timestamp)
+		c, ok, issue, err := r.policy.ingest(symbol, timeframe, rawCandle(it))
 		if err != nil {
-			return domain.CandleSeries{}, err
+			return nil, err
 		}
-		// Ensure UTC
-		if ts.Location() != time.UTC {
-			ts = ts.UTC()
+		if issue != nil {
+			issues = append(issues, *issue)
 		}
-		c, err := domain.NewCandle(symbol, timeframe, ts, it.Open, it.High, it.Low, it.Close, it.Volume)
-		if err != nil {
-			return domain.CandleSeries{}, err
+		if ok {
+			candles = append(candles, c)
 		}
-		candles = append(candles, c)
 	}
+	ports.RecordCandleIssues(ctx, issues...)
+	return candles, nil
+}
 
-	return domain.NewCandleSeries(symbol, timeframe, candles)
+// parseRetryAfter reads a Retry-After value given in seconds or as an HTTP date.
+// Missing, malformed or past values yield 0.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
 }
@@ -0,0 +1,85 @@
+package infra
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// IngestPolicy decides what an adapter does with upstream candles that are malformed or
+// violate domain invariants.
+type IngestPolicy int
+
+const (
+	// IngestStrict fails the whole series on the first invalid candle.
+	IngestStrict IngestPolicy = iota
+	// IngestSkipInvalid drops invalid candles.
+	IngestSkipInvalid
+	// IngestRepair clamps high and low around open and close and snaps misaligned
+	// timestamps to the start of their period; candles that cannot be repaired, such as
+	// those with unreadable timestamps or negative values, are dropped.
+	IngestRepair
+)
+
+// String returns the policy's name.
+func (p IngestPolicy) String() string {
+	switch p {
+	case IngestStrict:
+		return "strict"
+	case IngestSkipInvalid:
+		return "skip-invalid"
+	case IngestRepair:
+		return "repair"
+	default:
+		return fmt.Sprintf("IngestPolicy(%d)", int(p))
+	}
+}
+
+// rawCandle is an upstream candle before validation.
+type rawCandle struct {
+	Timestamp string
+	Open      float64
+	High      float64
+	Low       float64
+	Close     float64
+	Volume    float64
+}
+
+// ingest converts raw into a candle according to p. ok reports whether a candle is
+// returned; issue describes a dropped or repaired candle and is nil for valid ones.
+// Errors are only returned under IngestStrict.
+func (p IngestPolicy) ingest(symbol domain.Symbol, tf domain.Timeframe, raw rawCandle) (c domain.Candle, ok bool, issue *ports.CandleIssue, err error) {
+	ts, err := time.Parse(time.RFC3339, raw.Timestamp)
+	if err != nil {
+		if p == IngestStrict {
+			return domain.Candle{}, false, nil, err
+		}
+		return domain.Candle{}, false, &ports.CandleIssue{Symbol: symbol, Timeframe: tf, Reason: fmt.Sprintf("unreadable timestamp %q", raw.Timestamp)}, nil
+	}
+	ts = ts.UTC()
+
+	c, err = domain.NewCandle(symbol, tf, ts, raw.Open, raw.High, raw.Low, raw.Close, raw.Volume)
+	switch {
+	case err == nil:
+		return c, true, nil, nil
+	case p == IngestStrict:
+		return domain.Candle{}, false, nil, err
+	}
+	issue = &ports.CandleIssue{Symbol: symbol, Timeframe: tf, Timestamp: ts, Reason: err.Error()}
+	if p != IngestRepair {
+		return domain.Candle{}, false, issue, nil
+	}
+
+	high := math.Max(raw.High, math.Max(raw.Open, raw.Close))
+	low := math.Min(raw.Low, math.Min(raw.Open, raw.Close))
+	c, err = domain.NewCandle(symbol, tf, ts.Truncate(tf.Duration()), raw.Open, high, low, raw.Close, raw.Volume)
+	if err != nil {
+		issue.Reason = err.Error()
+		return domain.Candle{}, false, issue, nil
+	}
+	issue.Repaired = true
+	return c, true, issue, nil
+}
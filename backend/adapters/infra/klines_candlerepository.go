@@ -0,0 +1,180 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// defaultKlinesPageLimit is the per-request row limit of Binance-style providers.
+const defaultKlinesPageLimit = 1000
+
+// klinesIntervals maps timeframes to klines interval strings.
+var klinesIntervals = map[domain.Timeframe]string{
+	domain.Timeframe1m:  "1m",
+	domain.Timeframe5m:  "5m",
+	domain.Timeframe15m: "15m",
+	domain.Timeframe1h:  "1h",
+	domain.Timeframe4h:  "4h",
+	domain.Timeframe1d:  "1d",
+}
+
+// KlinesCandleRepository implements ports.CandleRepositoryPort over a Binance-style klines
+// endpoint, which answers symbol, interval, startTime, endTime and limit query parameters
+// with rows of [openTime, "open", "high", "low", "close", "volume", closeTime, ...]:
+// epoch-millisecond times and string-encoded decimals. Trailing row fields are ignored.
+//
+// Symbols are sent with '-' and '_' removed, so BTC-USDT is requested as BTCUSDT.
+type KlinesCandleRepository struct {
+	baseURL   *url.URL
+	client    *http.Client
+	pageLimit int
+}
+
+// NewKlinesCandleRepository constructs the adapter. base is the full URL of the klines
+// endpoint and must be valid.
+func NewKlinesCandleRepository(base string, client *http.Client) *KlinesCandleRepository {
+	u, _ := url.Parse(base)
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &KlinesCandleRepository{baseURL: u, client: client, pageLimit: defaultKlinesPageLimit}
+}
+
+// WithPageLimit sets the provider's per-request row limit. n <= 0 keeps the current value.
+// It returns r for chaining.
+func (r *KlinesCandleRepository) WithPageLimit(n int) *KlinesCandleRepository {
+	if n > 0 {
+		r.pageLimit = n
+	}
+	return r
+}
+
+// GetSeries implements CandleRepositoryPort. Ranges longer than one page are fetched
+// with consecutive requests, each starting after the last candle received.
+// Errors are reported as by FreeTierCandleRepository.GetSeries.
+func (r *KlinesCandleRepository) GetSeries(ctx context.Context, symbol domain.Symbol, timeframe domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+	if r.baseURL == nil {
+		return domain.CandleSeries{}, fmt.Errorf("invalid base URL")
+	}
+	interval, ok := klinesIntervals[timeframe]
+	if !ok {
+		return domain.CandleSeries{}, fmt.Errorf("%w: no klines interval for %q", domain.ErrInvalidTimeframe, timeframe)
+	}
+	pair := strings.NewReplacer("-", "", "_", "").Replace(symbol.String())
+
+	var candles []domain.Candle
+	for cursor := from.UTC(); cursor.Before(to); {
+		rows, err := r.fetchPage(ctx, pair, interval, cursor, to)
+		if err != nil {
+			return domain.CandleSeries{}, err
+		}
+		next := cursor
+		for _, row := range rows {
+			c, err := parseKline(symbol, timeframe, row)
+			if err != nil {
+				return domain.CandleSeries{}, err
+			}
+			if c.Timestamp().Before(cursor) || !c.Timestamp().Before(to) {
+				continue
+			}
+			candles = append(candles, c)
+			next = c.Timestamp().Add(timeframe.Duration())
+		}
+		if len(rows) < r.pageLimit || !next.After(cursor) {
+			break
+		}
+		cursor = next
+	}
+
+	return domain.NewCandleSeries(symbol, timeframe, candles)
+}
+
+// fetchPage requests up to pageLimit rows opening in [from, to).
+func (r *KlinesCandleRepository) fetchPage(ctx context.Context, pair, interval string, from, to time.Time) ([][]json.RawMessage, error) {
+	q := r.baseURL.Query()
+	q.Set("symbol", pair)
+	q.Set("interval", interval)
+	q.Set("startTime", strconv.FormatInt(from.UnixMilli(), 10))
+	// endTime is inclusive.
+	q.Set("endTime", strconv.FormatInt(to.UnixMilli()-1, 10))
+	q.Set("limit", strconv.Itoa(r.pageLimit))
+
+	endpoint := *r.baseURL
+	endpoint.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("klines request aborted: %w", ctxErr)
+		}
+		return nil, fmt.Errorf("%w: %w", ports.ErrUpstreamUnavailable, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &ports.UpstreamStatusError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+
+	var rows [][]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&rows); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("klines request aborted: %w", ctxErr)
+		}
+		return nil, err
+	}
+	return rows, nil
+}
+
+// parseKline converts one klines row into a candle.
+func parseKline(symbol domain.Symbol, tf domain.Timeframe, row []json.RawMessage) (domain.Candle, error) {
+	if len(row) < 6 {
+		return domain.Candle{}, fmt.Errorf("klines row has %d fields, want at least 6", len(row))
+	}
+	var openTime int64
+	if err := json.Unmarshal(row[0], &openTime); err != nil {
+		return domain.Candle{}, fmt.Errorf("klines open time: %w", err)
+	}
+	var vals [5]float64
+	for i := range vals {
+		v, err := parseKlineDecimal(row[i+1])
+		if err != nil {
+			return domain.Candle{}, err
+		}
+		vals[i] = v
+	}
+	return domain.NewCandle(symbol, tf, time.UnixMilli(openTime).UTC(), vals[0], vals[1], vals[2], vals[3], vals[4])
+}
+
+// parseKlineDecimal reads a decimal sent as a JSON string or, leniently, a JSON number.
+func parseKlineDecimal(raw json.RawMessage) (float64, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("klines decimal %q: %w", s, err)
+		}
+		return v, nil
+	}
+	var v float64
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return 0, fmt.Errorf("klines decimal %s: %w", raw, err)
+	}
+	return v, nil
+}
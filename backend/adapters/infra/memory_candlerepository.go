@@ -0,0 +1,165 @@
+package infra
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// MemoryCacheStats is a snapshot of a MemoryCandleRepository's counters.
+type MemoryCacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	// Entries and Candles describe the current contents.
+	Entries int
+	Candles int
+}
+
+// MemoryCandleRepository is an in-process LRU caching decorator that implements
+// ports.CandleRepositoryPort. It keeps decoded series keyed by the exact request, so a
+// hit costs neither a network round trip nor decoding.
+//
+// The cache is bounded by the total number of candles held; an empty series counts as
+// one. When adding an entry exceeds the bound, least recently used entries are evicted.
+// Entries expire after the TTL. Errors from the wrapped repository, and series it marked
+// stale with ports.MarkStale, are not cached. The provenance and candle issues the wrapped
+// repository reported for a series are kept with it and reported again on every hit.
+type MemoryCandleRepository struct {
+	wrapped    ports.CandleRepositoryPort
+	maxCandles int
+	ttl        time.Duration
+	now        func() time.Time
+
+	mu      sync.Mutex
+	lru     *list.List // front is most recently used
+	entries map[string]*list.Element
+	candles int
+	stats   MemoryCacheStats
+}
+
+// memoryEntry is one cached series with what was reported about it.
+type memoryEntry struct {
+	key     string
+	series  domain.CandleSeries
+	sources []ports.SourceRange
+	issues  []ports.CandleIssue
+	cost    int
+	expires time.Time
+}
+
+// NewMemoryCandleRepository constructs the decorator. maxCandles and ttl must be > 0.
+func NewMemoryCandleRepository(wrapped ports.CandleRepositoryPort, maxCandles int, ttl time.Duration) *MemoryCandleRepository {
+	if maxCandles <= 0 {
+		panic("maxCandles must be > 0")
+	}
+	if ttl <= 0 {
+		panic("ttl must be > 0")
+	}
+	return &MemoryCandleRepository{
+		wrapped:    wrapped,
+		maxCandles: maxCandles,
+		ttl:        ttl,
+		now:        time.Now,
+		lru:        list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// WithClock replaces the clock used for expiry. It returns r for chaining.
+func (r *MemoryCandleRepository) WithClock(now func() time.Time) *MemoryCandleRepository {
+	r.now = now
+	return r
+}
+
+// Stats returns a snapshot of the cache counters.
+func (r *MemoryCandleRepository) Stats() MemoryCacheStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.stats
+	s.Entries = r.lru.Len()
+	s.Candles = r.candles
+	return s
+}
+
+// GetSeries implements the ports.CandleRepositoryPort interface.
+func (r *MemoryCandleRepository) GetSeries(ctx context.Context, symbol domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+	key := coalesceKey(symbol, tf, from, to)
+	if e, ok := r.lookup(key); ok {
+		ports.RecordProvenance(ctx, e.sources...)
+		ports.RecordCandleIssues(ctx, e.issues...)
+		return e.series, nil
+	}
+
+	tctx, stale := ports.WithStaleTracking(ctx)
+	tctx, provenance := ports.WithProvenance(tctx)
+	tctx, quality := ports.WithQualityReport(tctx)
+	series, err := r.wrapped.GetSeries(tctx, symbol, tf, from, to)
+	sources, issues := provenance(), quality()
+	ports.RecordProvenance(ctx, sources...)
+	ports.RecordCandleIssues(ctx, issues...)
+	if err != nil {
+		return domain.CandleSeries{}, err
+	}
+	if stale() {
+		ports.MarkStale(ctx)
+		return series, nil
+	}
+	r.add(&memoryEntry{key: key, series: series, sources: sources, issues: issues})
+	return series, nil
+}
+
+// lookup returns a live entry and marks it most recently used. Expired entries are dropped.
+func (r *MemoryCandleRepository) lookup(key string) (*memoryEntry, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	el, ok := r.entries[key]
+	if ok {
+		e := el.Value.(*memoryEntry)
+		if r.now().Before(e.expires) {
+			r.lru.MoveToFront(el)
+			r.stats.Hits++
+			return e, true
+		}
+		r.remove(el)
+	}
+	r.stats.Misses++
+	return nil, false
+}
+
+// add stores e, evicting least recently used entries to stay within bounds; it sets the
+// entry's cost and expiry. A series larger than the whole cache is not stored.
+func (r *MemoryCandleRepository) add(e *memoryEntry) {
+	e.cost = e.series.Len()
+	if e.cost == 0 {
+		e.cost = 1
+	}
+	if e.cost > r.maxCandles {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if el, ok := r.entries[e.key]; ok {
+		r.remove(el)
+	}
+	for r.candles+e.cost > r.maxCandles {
+		r.remove(r.lru.Back())
+		r.stats.Evictions++
+	}
+	e.expires = r.now().Add(r.ttl)
+	r.entries[e.key] = r.lru.PushFront(e)
+	r.candles += e.cost
+}
+
+// remove drops el; the caller holds r.mu.
+func (r *MemoryCandleRepository) remove(el *list.Element) {
+	e := el.Value.(*memoryEntry)
+	r.lru.Remove(el)
+	delete(r.entries, e.key)
+	r.candles -= e.cost
+}
@@ -0,0 +1,35 @@
+package infra
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryCheckpointStore implements ports.CheckpointPort in process memory, for progress
+// that need not survive a restart. It is safe for concurrent use.
+type MemoryCheckpointStore struct {
+	mu          sync.Mutex
+	checkpoints map[string]time.Time
+}
+
+// NewMemoryCheckpointStore constructs an empty store.
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{checkpoints: make(map[string]time.Time)}
+}
+
+// Load implements ports.CheckpointPort.
+func (s *MemoryCheckpointStore) Load(_ context.Context, key string) (time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	progress, ok := s.checkpoints[key]
+	return progress, ok, nil
+}
+
+// Save implements ports.CheckpointPort.
+func (s *MemoryCheckpointStore) Save(_ context.Context, key string, progress time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoints[key] = progress
+	return nil
+}
@@ -0,0 +1,170 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// defaultRateLimitBackoff is how long calls are held back after the provider rate-limits
+// without saying for how long.
+const defaultRateLimitBackoff = time.Minute
+
+// RateLimit describes a provider's request quota. Zero fields are unlimited.
+type RateLimit struct {
+	PerMinute int
+	PerDay    int
+	// MaxWait is how long a call may queue for budget; calls that would wait longer are
+	// rejected at once. 0 rejects every call that cannot proceed immediately.
+	MaxWait time.Duration
+}
+
+// RateLimitedCandleRepository is a decorator that keeps calls to the wrapped repository
+// within a RateLimit, using a sliding-window log per quota: a call may start only if
+// fewer than the quota started in the period before it, so no rolling minute or day ever
+// holds more calls than allowed. When the wrapped repository reports
+// ports.ErrRateLimited, all calls are held back for its Retry-After delay (or
+// defaultRateLimitBackoff). Calls refused locally fail with *ports.RateLimitError.
+type RateLimitedCandleRepository struct {
+	wrapped ports.CandleRepositoryPort
+	maxWait time.Duration
+	now     func() time.Time
+
+	mu           sync.Mutex
+	windows      []*callWindow
+	lastStart    time.Time
+	blockedUntil time.Time
+}
+
+// callWindow logs the start times of the calls within the last period, oldest first,
+// including calls reserved to start in the future.
+type callWindow struct {
+	limit  int
+	period time.Duration
+	starts []time.Time
+}
+
+// NewRateLimitedCandleRepository constructs the decorator with empty windows.
+func NewRateLimitedCandleRepository(wrapped ports.CandleRepositoryPort, limit RateLimit) *RateLimitedCandleRepository {
+	r := &RateLimitedCandleRepository{wrapped: wrapped, maxWait: limit.MaxWait, now: time.Now}
+	for _, q := range []struct {
+		n      int
+		period time.Duration
+	}{{limit.PerMinute, time.Minute}, {limit.PerDay, 24 * time.Hour}} {
+		if q.n > 0 {
+			r.windows = append(r.windows, &callWindow{limit: q.n, period: q.period})
+		}
+	}
+	return r
+}
+
+// WithClock replaces the clock used for budgeting. It returns r for chaining.
+func (r *RateLimitedCandleRepository) WithClock(now func() time.Time) *RateLimitedCandleRepository {
+	r.now = now
+	return r
+}
+
+// GetSeries implements the ports.CandleRepositoryPort interface.
+func (r *RateLimitedCandleRepository) GetSeries(ctx context.Context, symbol domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+	start, wait, err := r.reserve()
+	if err != nil {
+		return domain.CandleSeries{}, err
+	}
+	if wait > 0 {
+		t := time.NewTimer(wait)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			r.unreserve(start)
+			return domain.CandleSeries{}, fmt.Errorf("waiting for rate limit: %w", ctx.Err())
+		}
+	}
+
+	series, err := r.wrapped.GetSeries(ctx, symbol, tf, from, to)
+	if errors.Is(err, ports.ErrRateLimited) {
+		r.backOff(err)
+	}
+	return series, err
+}
+
+// reserve records a call at the earliest time every window allows, no earlier than the
+// calls reserved before it, and returns that time and how long the caller must wait for
+// it, or a *ports.RateLimitError if that exceeds maxWait.
+func (r *RateLimitedCandleRepository) reserve() (time.Time, time.Duration, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+
+	start := now
+	for _, at := range []time.Time{r.blockedUntil, r.lastStart} {
+		if at.After(start) {
+			start = at
+		}
+	}
+	for _, w := range r.windows {
+		w.prune(now)
+		if at := w.earliest(); at.After(start) {
+			start = at
+		}
+	}
+	wait := start.Sub(now)
+	if wait > r.maxWait {
+		return time.Time{}, 0, &ports.RateLimitError{RetryAfter: wait}
+	}
+	for _, w := range r.windows {
+		w.starts = append(w.starts, start)
+	}
+	r.lastStart = start
+	return start, wait, nil
+}
+
+// unreserve removes a call abandoned while waiting.
+func (r *RateLimitedCandleRepository) unreserve(start time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, w := range r.windows {
+		for i := len(w.starts) - 1; i >= 0; i-- {
+			if w.starts[i].Equal(start) {
+				w.starts = append(w.starts[:i], w.starts[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// backOff holds calls back after the provider rate-limited one.
+func (r *RateLimitedCandleRepository) backOff(err error) {
+	d := ports.RetryAfter(err)
+	if d <= 0 {
+		d = defaultRateLimitBackoff
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if until := r.now().Add(d); until.After(r.blockedUntil) {
+		r.blockedUntil = until
+	}
+}
+
+// prune forgets the calls that started a whole period or more before now.
+func (w *callWindow) prune(now time.Time) {
+	i := 0
+	for i < len(w.starts) && !w.starts[i].After(now.Add(-w.period)) {
+		i++
+	}
+	w.starts = w.starts[i:]
+}
+
+// earliest is the first time a further call fits in the window: once the limit-th most
+// recent call is a whole period old. Calls are logged in start order.
+func (w *callWindow) earliest() time.Time {
+	if len(w.starts) < w.limit {
+		return time.Time{}
+	}
+	return w.starts[len(w.starts)-w.limit].Add(w.period)
+}
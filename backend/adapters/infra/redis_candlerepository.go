@@ -1,8 +1,11 @@
 package infra
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/akarso/pano_chart/backend/application/ports"
@@ -10,29 +13,152 @@ import (
 )
 
 // MinimalRedisClient is the minimal interface required by the decorator.
+// Implementations must honor ctx cancellation and deadlines.
 type MinimalRedisClient interface {
-	Get(key string) ([]byte, error)
-	Set(key string, value []byte, ttl time.Duration) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
 }
 
+// BatchRedisClient is an optional extension of MinimalRedisClient for clients that can
+// read and write several keys in one round trip, such as RedisClient. The decorator uses
+// it, when implemented, to load and store all the segments of a request at once.
+type BatchRedisClient interface {
+	MinimalRedisClient
+	// GetMany returns the values of keys, aligned with keys; missing keys yield nil.
+	GetMany(ctx context.Context, keys []string) ([][]byte, error)
+	// SetMany stores every item.
+	SetMany(ctx context.Context, items []RedisSetItem) error
+}
+
+const (
+	// segmentCandles is the number of candles covered by one cache segment.
+	segmentCandles = 360
+	// maxCachedSegments bounds the segments looked up per request; wider requests
+	// bypass the cache.
+	maxCachedSegments = 64
+	// defaultClosedTTL is the TTL of segments that can no longer change.
+	defaultClosedTTL = 24 * time.Hour
+	// refreshTimeout bounds a background revalidation.
+	refreshTimeout = 10 * time.Second
+)
+
 // RedisCandleRepository is a caching decorator that implements ports.CandleRepositoryPort.
+//
+// Candles are cached in segments: fixed, aligned time buckets of segmentCandles candles
+// per symbol and timeframe. A request is assembled from the segments it overlaps, and
+// only runs of missing segments are fetched from the wrapped repository. Closed segments,
+// which ended at least the publication lag before the current time, are cached with the
+// closed TTL; the still-forming segment, and one whose last candle may not be published
+// yet, use the shorter TTL passed to the constructor.
+//
+// These TTLs are soft: a segment past them is stale, and is kept in Redis for the longer
+// of the stale-while-revalidate and stale-if-error windows. Within the first window a stale
+// segment is served at once and refreshed in the background; within the second it is
+// refetched, but served if the provider is unavailable or rate-limiting. Serving stale
+// data is reported with ports.MarkStale.
 type RedisCandleRepository struct {
-	client MinimalRedisClient
-	wrapped ports.CandleRepositoryPort
-	ttl    time.Duration
+	client    MinimalRedisClient
+	wrapped   ports.CandleRepositoryPort
+	ttl       time.Duration
+	closedTTL time.Duration
+	swr       time.Duration
+	sie       time.Duration
+	lag       time.Duration
+	opTimeout time.Duration
+	now       func() time.Time
+
+	mu         sync.Mutex
+	refreshing map[string]bool
 }
 
-// NewRedisCandleRepository constructs the decorator. TTL must be > 0.
+// NewRedisCandleRepository constructs the decorator. TTL must be > 0 and applies to the
+// still-forming segment; closed segments default to a TTL of 24h.
 func NewRedisCandleRepository(client MinimalRedisClient, wrapped ports.CandleRepositoryPort, ttl time.Duration) *RedisCandleRepository {
 	if ttl <= 0 {
 		panic("ttl must be > 0")
 	}
-	return &RedisCandleRepository{client: client, wrapped: wrapped, ttl: ttl}
+	closedTTL := defaultClosedTTL
+	if closedTTL < ttl {
+		closedTTL = ttl
+	}
+	return &RedisCandleRepository{
+		client:     client,
+		wrapped:    wrapped,
+		ttl:        ttl,
+		closedTTL:  closedTTL,
+		lag:        DefaultPublicationLag,
+		now:        time.Now,
+		refreshing: make(map[string]bool),
+	}
+}
+
+// WithOperationTimeout bounds each cache Get and Set, or batch of them, to d (in
+// addition to the caller's deadline), so a slow cache degrades to a miss instead of
+// stalling the request. d <= 0 disables the bound. It returns r for chaining.
+func (r *RedisCandleRepository) WithOperationTimeout(d time.Duration) *RedisCandleRepository {
+	r.opTimeout = d
+	return r
+}
+
+// WithClosedTTL sets the TTL of closed segments. d <= 0 keeps the current value.
+// It returns r for chaining.
+func (r *RedisCandleRepository) WithClosedTTL(d time.Duration) *RedisCandleRepository {
+	if d > 0 {
+		r.closedTTL = d
+	}
+	return r
+}
+
+// WithPublicationLag sets how long after its end a segment is still treated as forming,
+// because the provider may not have published its last candle. Negative values are
+// ignored. It returns r for chaining.
+func (r *RedisCandleRepository) WithPublicationLag(d time.Duration) *RedisCandleRepository {
+	if d >= 0 {
+		r.lag = d
+	}
+	return r
+}
+
+// WithStaleWhileRevalidate sets how long past its TTL a segment is served while being
+// refreshed in the background. d <= 0 disables it. It returns r for chaining.
+func (r *RedisCandleRepository) WithStaleWhileRevalidate(d time.Duration) *RedisCandleRepository {
+	r.swr = d
+	return r
+}
+
+// WithStaleIfError sets how long past its TTL a segment is served when refetching it
+// fails with ports.ErrUpstreamUnavailable or ports.ErrRateLimited. d <= 0 disables it.
+// It returns r for chaining.
+func (r *RedisCandleRepository) WithStaleIfError(d time.Duration) *RedisCandleRepository {
+	r.sie = d
+	return r
+}
+
+// WithClock replaces the clock used to tell closed segments from the current one and
+// fresh segments from stale ones. It returns r for chaining.
+func (r *RedisCandleRepository) WithClock(now func() time.Time) *RedisCandleRepository {
+	r.now = now
+	return r
+}
+
+// cacheContext derives the context used for a single cache operation.
+func (r *RedisCandleRepository) cacheContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.opTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, r.opTimeout)
+}
+
+// cacheKey builds a deterministic cache key for the segment starting at start.
+func cacheKey(symbol domain.Symbol, tf domain.Timeframe, start time.Time) string {
+	return fmt.Sprintf("%s|%s|%s", symbol.String(), tf.String(), start.Format(time.RFC3339))
 }
 
-// cacheKey builds a deterministic cache key for the request.
-func cacheKey(symbol domain.Symbol, tf domain.Timeframe, from, to time.Time) string {
-	return fmt.Sprintf("%s|%s|%s|%s", symbol.String(), tf.String(), from.Format(time.RFC3339), to.Format(time.RFC3339))
+// segmentPayload is the serialized form of a segment in the cache.
+type segmentPayload struct {
+	// FreshUntil is the end of the segment's soft TTL, in epoch milliseconds.
+	FreshUntil int64         `json:"fresh_until"`
+	Candles    []payloadItem `json:"candles"`
 }
 
 // payloadItem is the serialized form for a candle in the cache.
@@ -45,51 +171,291 @@ type payloadItem struct {
 	Volume    float64 `json:"volume"`
 }
 
+// segmentState classifies a segment loaded from the cache.
+type segmentState int
+
+const (
+	segmentMissing      segmentState = iota
+	segmentFresh                     // within its TTL
+	segmentRevalidate                // stale; serve and refresh in the background
+	segmentStaleOnError              // stale; refetch, but serve on upstream failure
+)
+
+// segment is one cache bucket overlapped by a request.
+type segment struct {
+	start   time.Time
+	candles []domain.Candle
+	state   segmentState
+}
+
 // GetSeries implements the ports.CandleRepositoryPort interface.
-func (r *RedisCandleRepository) GetSeries(symbol domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
-	key := cacheKey(symbol, tf, from.UTC(), to.UTC())
-
-	// Try cache
-	if r.client != nil {
-		b, err := r.client.Get(key)
-		if err == nil && len(b) > 0 {
-			// unmarshal and reconstruct
-			var items []payloadItem
-			if err := json.Unmarshal(b, &items); err == nil {
-				candles := make([]domain.Candle, 0, len(items))
-				for _, it := range items {
-					ts, perr := time.Parse(time.RFC3339, it.Timestamp)
-					if perr != nil {
-						// treat as cache miss/fallback
-						break
-					}
-					c, cerr := domain.NewCandle(symbol, tf, ts.UTC(), it.Open, it.High, it.Low, it.Close, it.Volume)
-					if cerr != nil {
-						// treat as cache miss/fallback
-						break
-					}
-					candles = append(candles, c)
-				}
-				// If successfully reconstructed all candles, return series
-				if len(candles) == len(items) {
-					return domain.NewCandleSeries(symbol, tf, candles)
-				}
+// Cache errors, including timeouts, are treated as misses; errors from the wrapped
+// repository are returned unchanged unless stale data can stand in for the result.
+func (r *RedisCandleRepository) GetSeries(ctx context.Context, symbol domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+	from, to = from.UTC(), to.UTC()
+	span := tf.Duration() * segmentCandles
+	if r.client == nil || span <= 0 || !from.Before(to) || to.Sub(from.Truncate(span)) > span*maxCachedSegments {
+		return r.wrapped.GetSeries(ctx, symbol, tf, from, to)
+	}
+
+	var starts []time.Time
+	for start := from.Truncate(span); start.Before(to); start = start.Add(span) {
+		starts = append(starts, start)
+	}
+	segments := r.load(ctx, symbol, tf, starts)
+
+	var stale, revalidate bool
+	for i := 0; i < len(segments); {
+		switch segments[i].state {
+		case segmentFresh:
+			i++
+			continue
+		case segmentRevalidate:
+			stale, revalidate = true, true
+			i++
+			continue
+		}
+
+		// Fetch each run of segments that must be refetched with a single call.
+		j := i
+		fallback := true
+		for j < len(segments) && (segments[j].state == segmentMissing || segments[j].state == segmentStaleOnError) {
+			fallback = fallback && segments[j].state == segmentStaleOnError
+			j++
+		}
+		fetched, err := r.wrapped.GetSeries(ctx, symbol, tf, segments[i].start, segments[j-1].start.Add(span))
+		switch {
+		case err == nil:
+			for k := i; k < j; k++ {
+				start := segments[k].start
+				segments[k].candles = fetched.Between(start, start.Add(span)).All()
 			}
+			r.store(ctx, symbol, tf, span, segments[i:j])
+		case fallback && ctx.Err() == nil && isUpstreamFailure(err):
+			stale = true
+		default:
+			return domain.CandleSeries{}, err
 		}
+		i = j
+	}
+
+	if revalidate {
+		r.refreshInBackground(ctx, symbol, tf, span, segments)
+	}
+	if stale {
+		ports.MarkStale(ctx)
 	}
 
-	// Cache miss or client absent -> delegate to wrapped repository
-	series, err := r.wrapped.GetSeries(symbol, tf, from, to)
+	var candles []domain.Candle
+	for _, s := range segments {
+		candles = append(candles, s.candles...)
+	}
+	series, err := domain.NewCandleSeries(symbol, tf, candles)
 	if err != nil {
 		return domain.CandleSeries{}, err
 	}
+	return series.Between(from, to), nil
+}
+
+// Refresh implements ports.CandleRefresherPort: [from, to) is refetched from the wrapped
+// repository with a single call and merged into the segments it overlaps, whatever their
+// state in the cache. A partly overlapped segment that is not cached and fresh is
+// refetched whole instead, so no segment is ever stored with only part of its candles.
+// Of a range wider than the cache handles, only the last maxCachedSegments segments are
+// refreshed.
+func (r *RedisCandleRepository) Refresh(ctx context.Context, symbol domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) error {
+	from, to = from.UTC(), to.UTC()
+	span := tf.Duration() * segmentCandles
+	if r.client == nil || span <= 0 || !from.Before(to) {
+		return nil
+	}
+	if oldest := to.Add(span - 1).Truncate(span).Add(-span * maxCachedSegments); from.Before(oldest) {
+		from = oldest
+	}
+	first, last := from.Truncate(span), to.Add(-1).Truncate(span)
 
-	// Attempt to cache the result; ignore cache errors
-	if r.client != nil {
-		all := series.All()
-		items := make([]payloadItem, 0, len(all))
-		for _, c := range all {
-			items = append(items, payloadItem{
+	edges := []time.Time{first}
+	if last.After(first) {
+		edges = append(edges, last)
+	}
+	loaded := r.load(ctx, symbol, tf, edges)
+	head, tail := loaded[0], loaded[len(loaded)-1]
+	fetchFrom, fetchTo := from, to
+	if head.state != segmentFresh {
+		head.candles, fetchFrom = nil, first
+	}
+	if tail.state != segmentFresh {
+		tail.candles, fetchTo = nil, last.Add(span)
+	}
+
+	fetched, err := r.wrapped.GetSeries(ctx, symbol, tf, fetchFrom, fetchTo)
+	if err != nil {
+		return err
+	}
+	var segments []segment
+	for seg := first; !seg.After(last); seg = seg.Add(span) {
+		var kept []domain.Candle
+		if seg.Equal(first) {
+			kept = head.candles
+		} else if seg.Equal(last) {
+			kept = tail.candles
+		}
+		var candles []domain.Candle
+		for _, c := range kept {
+			if c.Timestamp().Before(fetchFrom) {
+				candles = append(candles, c)
+			}
+		}
+		candles = append(candles, fetched.Between(seg, seg.Add(span)).All()...)
+		for _, c := range kept {
+			if !c.Timestamp().Before(fetchTo) {
+				candles = append(candles, c)
+			}
+		}
+		segments = append(segments, segment{start: seg, candles: candles})
+	}
+	r.store(ctx, symbol, tf, span, segments)
+	return nil
+}
+
+// isUpstreamFailure reports whether err means the provider could not serve the request.
+func isUpstreamFailure(err error) bool {
+	return errors.Is(err, ports.ErrUpstreamUnavailable) || errors.Is(err, ports.ErrRateLimited)
+}
+
+// refreshInBackground refetches each run of segments awaiting revalidation, detached from
+// the caller's cancellation. A segment already being refreshed is skipped.
+func (r *RedisCandleRepository) refreshInBackground(ctx context.Context, symbol domain.Symbol, tf domain.Timeframe, span time.Duration, segments []segment) {
+	var starts []time.Time
+	r.mu.Lock()
+	for _, s := range segments {
+		key := cacheKey(symbol, tf, s.start)
+		if s.state == segmentRevalidate && !r.refreshing[key] {
+			r.refreshing[key] = true
+			starts = append(starts, s.start)
+		}
+	}
+	r.mu.Unlock()
+	if len(starts) == 0 {
+		return
+	}
+
+	go func() {
+		bctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), refreshTimeout)
+		defer cancel()
+		defer func() {
+			r.mu.Lock()
+			for _, start := range starts {
+				delete(r.refreshing, cacheKey(symbol, tf, start))
+			}
+			r.mu.Unlock()
+		}()
+
+		for i := 0; i < len(starts); {
+			j := i + 1
+			for j < len(starts) && starts[j].Equal(starts[j-1].Add(span)) {
+				j++
+			}
+			fetched, err := r.wrapped.GetSeries(bctx, symbol, tf, starts[i], starts[j-1].Add(span))
+			if err == nil {
+				segments := make([]segment, 0, j-i)
+				for _, start := range starts[i:j] {
+					segments = append(segments, segment{start: start, candles: fetched.Between(start, start.Add(span)).All()})
+				}
+				r.store(bctx, symbol, tf, span, segments)
+			}
+			i = j
+		}
+	}()
+}
+
+// load reads the segments starting at starts, in one round trip if the client supports
+// batches, and classifies them. Cache errors, undecodable payloads and segments past
+// every stale window are reported as missing.
+func (r *RedisCandleRepository) load(ctx context.Context, symbol domain.Symbol, tf domain.Timeframe, starts []time.Time) []segment {
+	keys := make([]string, len(starts))
+	for i, start := range starts {
+		keys[i] = cacheKey(symbol, tf, start)
+	}
+	values := make([][]byte, len(keys))
+	if batch, ok := r.client.(BatchRedisClient); ok {
+		cctx, cancel := r.cacheContext(ctx)
+		if got, err := batch.GetMany(cctx, keys); err == nil && len(got) == len(keys) {
+			values = got
+		}
+		cancel()
+	} else {
+		for i, key := range keys {
+			cctx, cancel := r.cacheContext(ctx)
+			if b, err := r.client.Get(cctx, key); err == nil {
+				values[i] = b
+			}
+			cancel()
+		}
+	}
+
+	segments := make([]segment, len(starts))
+	for i, start := range starts {
+		candles, state := r.decode(symbol, tf, values[i])
+		segments[i] = segment{start: start, candles: candles, state: state}
+	}
+	return segments
+}
+
+// decode parses and classifies one cached segment.
+func (r *RedisCandleRepository) decode(symbol domain.Symbol, tf domain.Timeframe, b []byte) ([]domain.Candle, segmentState) {
+	if len(b) == 0 {
+		return nil, segmentMissing
+	}
+	var payload segmentPayload
+	if err := json.Unmarshal(b, &payload); err != nil {
+		return nil, segmentMissing
+	}
+
+	state := segmentFresh
+	if over := r.now().Sub(time.UnixMilli(payload.FreshUntil)); over >= 0 {
+		switch {
+		case over < r.swr:
+			state = segmentRevalidate
+		case over < r.sie:
+			state = segmentStaleOnError
+		default:
+			return nil, segmentMissing
+		}
+	}
+
+	candles := make([]domain.Candle, 0, len(payload.Candles))
+	for _, it := range payload.Candles {
+		ts, err := time.Parse(time.RFC3339, it.Timestamp)
+		if err != nil {
+			return nil, segmentMissing
+		}
+		c, err := domain.NewCandle(symbol, tf, ts.UTC(), it.Open, it.High, it.Low, it.Close, it.Volume)
+		if err != nil {
+			return nil, segmentMissing
+		}
+		candles = append(candles, c)
+	}
+	return candles, state
+}
+
+// store writes segments, each spanning span, in one round trip if the client supports
+// batches, ignoring cache errors. Empty segments are stored only once closed, past the
+// publication lag, so a not-yet-published candle is not cached as absent. The Redis TTL
+// extends each segment's TTL by the longer stale window.
+func (r *RedisCandleRepository) store(ctx context.Context, symbol domain.Symbol, tf domain.Timeframe, span time.Duration, segments []segment) {
+	now := r.now()
+	items := make([]RedisSetItem, 0, len(segments))
+	for _, s := range segments {
+		ttl := r.ttl
+		if !s.start.Add(span + r.lag).After(now) {
+			ttl = r.closedTTL
+		} else if len(s.candles) == 0 {
+			continue
+		}
+		payload := segmentPayload{FreshUntil: now.Add(ttl).UnixMilli(), Candles: make([]payloadItem, 0, len(s.candles))}
+		for _, c := range s.candles {
+			payload.Candles = append(payload.Candles, payloadItem{
 				Timestamp: c.Timestamp().Format(time.RFC3339),
 				Open:      c.Open(),
 				High:      c.High(),
@@ -98,12 +464,25 @@ func (r *RedisCandleRepository) GetSeries(symbol domain.Symbol, tf domain.Timefr
 				Volume:    c.Volume(),
 			})
 		}
-		if len(items) > 0 {
-			if b, merr := json.Marshal(items); merr == nil {
-				_ = r.client.Set(key, b, r.ttl)
-			}
+		b, err := json.Marshal(payload)
+		if err != nil {
+			continue
 		}
+		items = append(items, RedisSetItem{Key: cacheKey(symbol, tf, s.start), Value: b, TTL: ttl + max(0, r.swr, r.sie)})
+	}
+	if len(items) == 0 {
+		return
 	}
 
-	return series, nil
+	if batch, ok := r.client.(BatchRedisClient); ok {
+		cctx, cancel := r.cacheContext(ctx)
+		_ = batch.SetMany(cctx, items)
+		cancel()
+		return
+	}
+	for _, it := range items {
+		cctx, cancel := r.cacheContext(ctx)
+		_ = r.client.Set(cctx, it.Key, it.Value, it.TTL)
+		cancel()
+	}
 }
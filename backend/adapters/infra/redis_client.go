@@ -0,0 +1,336 @@
+package infra
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrRedisNil is returned by Get for a key that does not exist.
+var ErrRedisNil = errors.New("redis: nil")
+
+// ErrRedisClosed is returned by operations on a closed RedisClient.
+var ErrRedisClosed = errors.New("redis: client closed")
+
+// RedisClientOptions configures a RedisClient. Zero values select the defaults noted per field.
+type RedisClientOptions struct {
+	// Addr is the server's host:port.
+	Addr string
+	// Username is sent with AUTH when set (Redis 6 ACLs); otherwise only Password is.
+	Username string
+	// Password enables AUTH when set.
+	Password string
+	// DB is selected on every new connection when non-zero.
+	DB int
+	// Protocol is 2 (default) or 3; 3 negotiates RESP3 with HELLO.
+	Protocol int
+	// PoolSize bounds open connections; default 10.
+	PoolSize int
+	// DialTimeout bounds connection setup, including AUTH and SELECT; default 5s.
+	DialTimeout time.Duration
+	// ReadTimeout bounds each command's round trip (write and read); default 3s.
+	ReadTimeout time.Duration
+}
+
+// RedisSetItem is one entry of a SetMany batch. TTL <= 0 stores the value without expiry.
+type RedisSetItem struct {
+	Key   string
+	Value []byte
+	TTL   time.Duration
+}
+
+// RedisClient is a dependency-free Redis client speaking RESP2 or RESP3 over a bounded
+// connection pool. It implements MinimalRedisClient and is safe for concurrent use.
+//
+// Every operation honors ctx: its deadline and cancellation abort the round trip. A
+// connection that failed at the I/O or protocol level is discarded, never reused.
+type RedisClient struct {
+	opts RedisClientOptions
+
+	slots chan struct{} // one token per open or opening connection
+	idle  chan *redisConn
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// redisConn is a pooled connection.
+type redisConn struct {
+	nc net.Conn
+	br *bufio.Reader
+	bw *bufio.Writer
+}
+
+// NewRedisClient constructs the client. Connections are dialed lazily.
+func NewRedisClient(opts RedisClientOptions) *RedisClient {
+	if opts.PoolSize <= 0 {
+		opts.PoolSize = 10
+	}
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = 5 * time.Second
+	}
+	if opts.ReadTimeout <= 0 {
+		opts.ReadTimeout = 3 * time.Second
+	}
+	if opts.Protocol == 0 {
+		opts.Protocol = 2
+	}
+	return &RedisClient{
+		opts:  opts,
+		slots: make(chan struct{}, opts.PoolSize),
+		idle:  make(chan *redisConn, opts.PoolSize),
+	}
+}
+
+// Get implements MinimalRedisClient. A missing key is reported as ErrRedisNil.
+func (c *RedisClient) Get(ctx context.Context, key string) ([]byte, error) {
+	values, err := c.GetMany(ctx, []string{key})
+	if err != nil {
+		return nil, err
+	}
+	if values[0] == nil {
+		return nil, ErrRedisNil
+	}
+	return values[0], nil
+}
+
+// Set implements MinimalRedisClient. ttl <= 0 stores the value without expiry.
+func (c *RedisClient) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.SetMany(ctx, []RedisSetItem{{Key: key, Value: value, TTL: ttl}})
+}
+
+// GetMany pipelines one GET per key over a single connection. The result is aligned with
+// keys; missing keys yield nil entries.
+func (c *RedisClient) GetMany(ctx context.Context, keys []string) ([][]byte, error) {
+	cmds := make([][][]byte, len(keys))
+	for i, k := range keys {
+		cmds[i] = [][]byte{[]byte("GET"), []byte(k)}
+	}
+	replies, err := c.pipeline(ctx, cmds)
+	if err != nil {
+		return nil, err
+	}
+	values := make([][]byte, len(replies))
+	for i, r := range replies {
+		switch v := r.(type) {
+		case nil:
+		case []byte:
+			values[i] = v
+		case *RedisError:
+			return nil, v
+		default:
+			return nil, fmt.Errorf("%w: unexpected GET reply %T", errRESPProtocol, r)
+		}
+	}
+	return values, nil
+}
+
+// SetMany pipelines one SET per item over a single connection. The first error reply is
+// returned after all replies have been read.
+func (c *RedisClient) SetMany(ctx context.Context, items []RedisSetItem) error {
+	cmds := make([][][]byte, len(items))
+	for i, it := range items {
+		cmd := [][]byte{[]byte("SET"), []byte(it.Key), it.Value}
+		if it.TTL > 0 {
+			ms := it.TTL.Milliseconds()
+			if ms == 0 {
+				ms = 1
+			}
+			cmd = append(cmd, []byte("PX"), []byte(strconv.FormatInt(ms, 10)))
+		}
+		cmds[i] = cmd
+	}
+	replies, err := c.pipeline(ctx, cmds)
+	if err != nil {
+		return err
+	}
+	for _, r := range replies {
+		if rerr, ok := r.(*RedisError); ok {
+			return rerr
+		}
+	}
+	return nil
+}
+
+// Close closes idle connections and makes further operations fail with ErrRedisClosed.
+// Connections in use are closed when they are released.
+func (c *RedisClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	for {
+		select {
+		case cn := <-c.idle:
+			_ = cn.nc.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// pipeline writes all commands, flushes once, and reads one reply per command.
+func (c *RedisClient) pipeline(ctx context.Context, cmds [][][]byte) ([]any, error) {
+	if len(cmds) == 0 {
+		return nil, nil
+	}
+	cn, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	replies, err := cn.roundTrip(ctx, c.opts.ReadTimeout, cmds)
+	c.release(cn, err == nil)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("redis: %w", ctxErr)
+		}
+		return nil, err
+	}
+	return replies, nil
+}
+
+// acquire takes an idle connection or, if a slot is free, dials a new one.
+func (c *RedisClient) acquire(ctx context.Context) (*redisConn, error) {
+	if c.isClosed() {
+		return nil, ErrRedisClosed
+	}
+	select {
+	case cn := <-c.idle:
+		return cn, nil
+	default:
+	}
+	select {
+	case cn := <-c.idle:
+		return cn, nil
+	case c.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("redis: %w", ctx.Err())
+	}
+	cn, err := c.dial(ctx)
+	if err != nil {
+		<-c.slots
+		return nil, err
+	}
+	return cn, nil
+}
+
+// release returns a healthy connection to the pool and discards any other.
+func (c *RedisClient) release(cn *redisConn, healthy bool) {
+	if healthy && !c.isClosed() {
+		select {
+		case c.idle <- cn:
+			return
+		default:
+		}
+	}
+	_ = cn.nc.Close()
+	<-c.slots
+}
+
+func (c *RedisClient) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+// dial opens a connection and runs the handshake: HELLO (RESP3) or AUTH (RESP2), then SELECT.
+func (c *RedisClient) dial(ctx context.Context) (*redisConn, error) {
+	dctx, cancel := context.WithTimeout(ctx, c.opts.DialTimeout)
+	defer cancel()
+
+	var d net.Dialer
+	nc, err := d.DialContext(dctx, "tcp", c.opts.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("redis: dial %s: %w", c.opts.Addr, err)
+	}
+	cn := &redisConn{nc: nc, br: bufio.NewReader(nc), bw: bufio.NewWriter(nc)}
+
+	var cmds [][][]byte
+	switch {
+	case c.opts.Protocol == 3:
+		hello := [][]byte{[]byte("HELLO"), []byte("3")}
+		if c.opts.Password != "" {
+			user := c.opts.Username
+			if user == "" {
+				user = "default"
+			}
+			hello = append(hello, []byte("AUTH"), []byte(user), []byte(c.opts.Password))
+		}
+		cmds = append(cmds, hello)
+	case c.opts.Password != "":
+		auth := [][]byte{[]byte("AUTH")}
+		if c.opts.Username != "" {
+			auth = append(auth, []byte(c.opts.Username))
+		}
+		cmds = append(cmds, append(auth, []byte(c.opts.Password)))
+	}
+	if c.opts.DB != 0 {
+		cmds = append(cmds, [][]byte{[]byte("SELECT"), []byte(strconv.Itoa(c.opts.DB))})
+	}
+	if len(cmds) == 0 {
+		return cn, nil
+	}
+
+	replies, err := cn.roundTrip(dctx, c.opts.DialTimeout, cmds)
+	if err == nil {
+		for _, r := range replies {
+			if rerr, ok := r.(*RedisError); ok {
+				err = fmt.Errorf("redis: handshake: %w", rerr)
+				break
+			}
+		}
+	}
+	if err != nil {
+		_ = nc.Close()
+		return nil, err
+	}
+	return cn, nil
+}
+
+// roundTrip sends cmds and reads their replies within timeout, aborting early when ctx ends.
+// Once ctx has ended the connection's deadline may have been expired at any point, so the
+// call fails and the caller discards the connection, even if every reply was read.
+func (cn *redisConn) roundTrip(ctx context.Context, timeout time.Duration, cmds [][][]byte) ([]any, error) {
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := cn.nc.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	// Cancellation without a deadline unblocks I/O by expiring the connection's deadline.
+	stop := context.AfterFunc(ctx, func() { _ = cn.nc.SetDeadline(time.Unix(1, 0)) })
+	replies, err := cn.exchange(cmds)
+	if !stop() {
+		return nil, fmt.Errorf("redis: %w", ctx.Err())
+	}
+	return replies, err
+}
+
+// exchange writes cmds, flushes once, and reads one reply per command.
+func (cn *redisConn) exchange(cmds [][][]byte) ([]any, error) {
+	for _, args := range cmds {
+		if err := writeCommand(cn.bw, args...); err != nil {
+			return nil, err
+		}
+	}
+	if err := cn.bw.Flush(); err != nil {
+		return nil, err
+	}
+	replies := make([]any, len(cmds))
+	for i := range cmds {
+		r, err := readReply(cn.br)
+		if err != nil {
+			return nil, err
+		}
+		replies[i] = r
+	}
+	return replies, nil
+}
@@ -0,0 +1,50 @@
+package infra
+
+import (
+	"context"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// ResamplingCandleRepository is a decorator that serves coarser timeframes by fetching a
+// single base timeframe from the wrapped repository and aggregating it locally.
+// Requests for the base timeframe, or for timeframes that cannot be derived from it,
+// are passed through unchanged.
+type ResamplingCandleRepository struct {
+	wrapped ports.CandleRepositoryPort
+	base    domain.Timeframe
+	policy  domain.ResamplePolicy
+}
+
+// NewResamplingCandleRepository constructs the decorator.
+func NewResamplingCandleRepository(wrapped ports.CandleRepositoryPort, base domain.Timeframe, policy domain.ResamplePolicy) *ResamplingCandleRepository {
+	return &ResamplingCandleRepository{wrapped: wrapped, base: base, policy: policy}
+}
+
+// GetSeries implements the ports.CandleRepositoryPort interface.
+// For derivable timeframes the base series is fetched over [from, to) widened to whole
+// target buckets, resampled, and trimmed back to candles with from <= timestamp < to.
+func (r *ResamplingCandleRepository) GetSeries(ctx context.Context, symbol domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+	src, dst := r.base.Duration(), tf.Duration()
+	if src == 0 || dst <= src || dst%src != 0 {
+		return r.wrapped.GetSeries(ctx, symbol, tf, from, to)
+	}
+
+	start := from.UTC().Truncate(dst)
+	end := to.UTC().Truncate(dst)
+	if end.Before(to.UTC()) {
+		end = end.Add(dst)
+	}
+
+	base, err := r.wrapped.GetSeries(ctx, symbol, r.base, start, end)
+	if err != nil {
+		return domain.CandleSeries{}, err
+	}
+	resampled, err := base.Resample(tf, r.policy)
+	if err != nil {
+		return domain.CandleSeries{}, err
+	}
+	return resampled.Between(from.UTC(), to.UTC()), nil
+}
@@ -0,0 +1,241 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// ErrCircuitOpen is returned without calling the wrapped repository while the circuit is
+// open. It wraps ports.ErrUpstreamUnavailable.
+var ErrCircuitOpen = fmt.Errorf("%w: circuit open", ports.ErrUpstreamUnavailable)
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets calls through.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails calls fast.
+	CircuitOpen
+	// CircuitHalfOpen lets a probe through to test whether the upstream recovered.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+}
+
+// ResiliencePolicy configures a ResilientCandleRepository. Zero fields select the
+// defaults noted per field.
+type ResiliencePolicy struct {
+	// MaxAttempts bounds the attempts per call, the first included; default 3.
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry, doubled for each further one;
+	// default 100ms. Each delay is jittered to between half and all of its value.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff; default 2s.
+	MaxDelay time.Duration
+	// FailureThreshold is the number of consecutive failed calls that opens the circuit;
+	// default 5.
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before a probe is let through;
+	// default 30s.
+	OpenTimeout time.Duration
+}
+
+// ResilientCandleRepository is a decorator that retries transient failures of the wrapped
+// repository with jittered exponential backoff, behind a circuit breaker.
+//
+// Only errors wrapping ports.ErrUpstreamUnavailable are retried and count as failures;
+// rate limits, invalid data and the caller's cancellation are returned at once. After
+// FailureThreshold consecutive failed calls the circuit opens and calls fail with
+// ErrCircuitOpen. Once OpenTimeout has passed the circuit half-opens: a single probe,
+// made without retries, closes it on success or reopens it on failure.
+type ResilientCandleRepository struct {
+	wrapped  ports.CandleRepositoryPort
+	policy   ResiliencePolicy
+	now      func() time.Time
+	onChange func(from, to CircuitState)
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewResilientCandleRepository constructs the decorator with the circuit closed.
+func NewResilientCandleRepository(wrapped ports.CandleRepositoryPort, policy ResiliencePolicy) *ResilientCandleRepository {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 3
+	}
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = 100 * time.Millisecond
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = 2 * time.Second
+	}
+	if policy.FailureThreshold <= 0 {
+		policy.FailureThreshold = 5
+	}
+	if policy.OpenTimeout <= 0 {
+		policy.OpenTimeout = 30 * time.Second
+	}
+	return &ResilientCandleRepository{wrapped: wrapped, policy: policy, now: time.Now}
+}
+
+// WithClock replaces the clock used for the open timeout. It returns r for chaining.
+func (r *ResilientCandleRepository) WithClock(now func() time.Time) *ResilientCandleRepository {
+	r.now = now
+	return r
+}
+
+// OnStateChange registers f to be called on every circuit transition. f runs while the
+// circuit is locked and must not call back into r. It returns r for chaining.
+func (r *ResilientCandleRepository) OnStateChange(f func(from, to CircuitState)) *ResilientCandleRepository {
+	r.onChange = f
+	return r
+}
+
+// State returns the current circuit state.
+func (r *ResilientCandleRepository) State() CircuitState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.state == CircuitOpen && !r.now().Before(r.openedAt.Add(r.policy.OpenTimeout)) {
+		return CircuitHalfOpen
+	}
+	return r.state
+}
+
+// GetSeries implements the ports.CandleRepositoryPort interface.
+func (r *ResilientCandleRepository) GetSeries(ctx context.Context, symbol domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+	probe, err := r.admit()
+	if err != nil {
+		return domain.CandleSeries{}, err
+	}
+
+	attempts := r.policy.MaxAttempts
+	if probe {
+		attempts = 1
+	}
+	var series domain.CandleSeries
+	for attempt := 1; ; attempt++ {
+		series, err = r.wrapped.GetSeries(ctx, symbol, tf, from, to)
+		if err == nil || attempt == attempts || !r.retryable(ctx, err) {
+			break
+		}
+		if werr := r.backoff(ctx, attempt); werr != nil {
+			break
+		}
+	}
+
+	if ctx.Err() != nil {
+		// The caller gave up; that says nothing about the upstream's health.
+		r.release(probe)
+	} else {
+		r.record(probe, !errors.Is(err, ports.ErrUpstreamUnavailable))
+	}
+	return series, err
+}
+
+// retryable reports whether err is a transient upstream failure rather than the
+// caller's own cancellation.
+func (r *ResilientCandleRepository) retryable(ctx context.Context, err error) bool {
+	return ctx.Err() == nil && errors.Is(err, ports.ErrUpstreamUnavailable)
+}
+
+// backoff sleeps before the retry following attempt, or returns ctx's error.
+func (r *ResilientCandleRepository) backoff(ctx context.Context, attempt int) error {
+	d := r.policy.BaseDelay << (attempt - 1)
+	if d > r.policy.MaxDelay || d <= 0 {
+		d = r.policy.MaxDelay
+	}
+	d = d/2 + rand.N(d/2+1)
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// admit decides whether a call may proceed and whether it is the half-open probe.
+func (r *ResilientCandleRepository) admit() (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch r.state {
+	case CircuitClosed:
+		return false, nil
+	case CircuitOpen:
+		if r.now().Before(r.openedAt.Add(r.policy.OpenTimeout)) {
+			return false, ErrCircuitOpen
+		}
+		r.transition(CircuitHalfOpen)
+	}
+	if r.probing {
+		return false, ErrCircuitOpen
+	}
+	r.probing = true
+	return true, nil
+}
+
+// release ends a call without recording an outcome.
+func (r *ResilientCandleRepository) release(probe bool) {
+	if probe {
+		r.mu.Lock()
+		r.probing = false
+		r.mu.Unlock()
+	}
+}
+
+// record updates the circuit with a call's outcome. Calls that did not fail with a
+// transient upstream error count as successes for the circuit.
+func (r *ResilientCandleRepository) record(probe, ok bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if probe {
+		r.probing = false
+	}
+	if ok {
+		r.failures = 0
+		if r.state != CircuitClosed && probe {
+			r.transition(CircuitClosed)
+		}
+		return
+	}
+	r.failures++
+	if probe || (r.state == CircuitClosed && r.failures >= r.policy.FailureThreshold) {
+		r.openedAt = r.now()
+		r.transition(CircuitOpen)
+	}
+}
+
+// transition moves the circuit to state; the caller holds r.mu.
+func (r *ResilientCandleRepository) transition(state CircuitState) {
+	if state == r.state {
+		return
+	}
+	from := r.state
+	r.state = state
+	if r.onChange != nil {
+		r.onChange(from, state)
+	}
+}
@@ -0,0 +1,169 @@
+package infra
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// RedisError is an error reply sent by the Redis server. It leaves the connection usable.
+type RedisError struct {
+	Message string
+}
+
+func (e *RedisError) Error() string {
+	return "redis: " + e.Message
+}
+
+// errRESPProtocol reports a reply that does not follow RESP2/RESP3.
+var errRESPProtocol = errors.New("redis: protocol error")
+
+const (
+	// maxBulkLen is the largest bulk string accepted, Redis's own proto-max-bulk-len.
+	maxBulkLen = 512 << 20
+	// maxAggregateLen is the largest element count accepted in an aggregate.
+	maxAggregateLen = 1 << 20
+	// maxAggregatePrealloc bounds the elements allocated up front for an aggregate, so a
+	// count the server never follows up on costs no memory.
+	maxAggregatePrealloc = 1024
+)
+
+// writeCommand encodes args as a RESP array of bulk strings.
+func writeCommand(w *bufio.Writer, args ...[]byte) error {
+	if _, err := fmt.Fprintf(w, "*%d\r\n", len(args)); err != nil {
+		return err
+	}
+	for _, a := range args {
+		if _, err := fmt.Fprintf(w, "$%d\r\n", len(a)); err != nil {
+			return err
+		}
+		if _, err := w.Write(a); err != nil {
+			return err
+		}
+		if _, err := w.WriteString("\r\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readReply decodes one RESP2 or RESP3 reply. Strings of every kind decode to []byte,
+// integers to int64, nulls to nil, and aggregates (arrays, sets, maps flattened to
+// key/value pairs) to []any. Error replies decode to *RedisError values rather than
+// being returned as err, which is reserved for I/O and protocol failures.
+// Out-of-band push messages and attributes are skipped.
+func readReply(r *bufio.Reader) (any, error) {
+	for {
+		kind, line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		switch kind {
+		case '+':
+			return line, nil
+		case '-':
+			return &RedisError{Message: string(line)}, nil
+		case ':':
+			return parseInt(line)
+		case ',', '(':
+			// Doubles and big numbers are kept in their textual form.
+			return line, nil
+		case '#':
+			return len(line) == 1 && line[0] == 't', nil
+		case '_':
+			return nil, nil
+		case '$', '=', '!':
+			v, err := readBulk(r, line)
+			if err != nil || kind != '!' || v == nil {
+				return v, err
+			}
+			return &RedisError{Message: string(v.([]byte))}, nil
+		case '*', '~':
+			return readAggregate(r, line, 1)
+		case '%':
+			return readAggregate(r, line, 2)
+		case '>', '|':
+			// Push data and attributes accompany, but are not, the reply.
+			factor := int64(1)
+			if kind == '|' {
+				factor = 2
+			}
+			if _, err := readAggregate(r, line, factor); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("%w: unexpected type byte %q", errRESPProtocol, kind)
+		}
+	}
+}
+
+// readLine reads one CRLF-terminated header and splits off its type byte.
+func readLine(r *bufio.Reader) (byte, []byte, error) {
+	line, err := r.ReadSlice('\n')
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return 0, nil, fmt.Errorf("%w: malformed line", errRESPProtocol)
+	}
+	body := make([]byte, len(line)-3)
+	copy(body, line[1:len(line)-2])
+	return line[0], body, nil
+}
+
+// readBulk reads a length-prefixed payload; a length of -1 is the RESP2 null.
+func readBulk(r *bufio.Reader, header []byte) (any, error) {
+	n, err := parseInt(header)
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 {
+		return nil, nil
+	}
+	if n > maxBulkLen {
+		return nil, fmt.Errorf("%w: bulk string of %d bytes", errRESPProtocol, n)
+	}
+	buf := make([]byte, n+2)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	if buf[n] != '\r' || buf[n+1] != '\n' {
+		return nil, fmt.Errorf("%w: malformed bulk string", errRESPProtocol)
+	}
+	return buf[:n], nil
+}
+
+// readAggregate reads an aggregate of header*factor elements; a count of -1 is the
+// RESP2 null array.
+func readAggregate(r *bufio.Reader, header []byte, factor int64) (any, error) {
+	n, err := parseInt(header)
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 {
+		return nil, nil
+	}
+	if n > maxAggregateLen {
+		return nil, fmt.Errorf("%w: aggregate of %d elements", errRESPProtocol, n)
+	}
+	n *= factor
+	elems := make([]any, 0, min(n, maxAggregatePrealloc))
+	for i := int64(0); i < n; i++ {
+		v, err := readReply(r)
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, v)
+	}
+	return elems, nil
+}
+
+func parseInt(b []byte) (int64, error) {
+	n, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid integer %q", errRESPProtocol, b)
+	}
+	return n, nil
+}
@@ -0,0 +1,157 @@
+package infra
+
+import (
+	"context"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// DefaultPublicationLag is how long after a candle's period ends the provider is assumed
+// to take to publish its final values.
+const DefaultPublicationLag = 5 * time.Second
+
+// StoredCandleRepository implements ports.CandleRepositoryPort by serving history from a
+// durable ports.CandleStorePort and fetching from the wrapped repository only what the
+// store does not cover.
+//
+// Closed candles (those whose period ended at least the publication lag before now) are
+// written to the store together with the range they were fetched for, so later requests
+// for that range never reach the wrapped repository. Gaps in closed history are final:
+// once the provider has answered for a range, the candles it lacks before the last one
+// it returned are recorded as missing for good, so the range is covered up to the end of
+// its last candle. The tail after the last candle is covered only when stored history
+// follows it; otherwise it is indistinguishable from a truncated response and is fetched
+// again, as is an empty response. Newer candles are always fetched and never stored. The
+// missing part of a request is fetched in one call spanning its first to last uncovered
+// range.
+//
+// Store failures are not fatal: a failed write only means the range is fetched again next
+// time, and a failed read falls back to the wrapped repository.
+type StoredCandleRepository struct {
+	store ports.CandleStorePort
+	next  ports.CandleRepositoryPort
+	lag   time.Duration
+	now   func() time.Time
+}
+
+// NewStoredCandleRepository constructs the decorator. Both arguments are required.
+func NewStoredCandleRepository(store ports.CandleStorePort, next ports.CandleRepositoryPort) *StoredCandleRepository {
+	if store == nil || next == nil {
+		panic("store and next repository are required")
+	}
+	return &StoredCandleRepository{store: store, next: next, lag: DefaultPublicationLag, now: time.Now}
+}
+
+// WithPublicationLag sets how long after its period ends a candle is still treated as
+// unpublished and neither stored nor served from the store. Negative values are
+// ignored. It returns r for chaining.
+func (r *StoredCandleRepository) WithPublicationLag(d time.Duration) *StoredCandleRepository {
+	if d >= 0 {
+		r.lag = d
+	}
+	return r
+}
+
+// WithClock overrides the clock that decides which candles are closed; intended for
+// tests. It returns r for chaining.
+func (r *StoredCandleRepository) WithClock(now func() time.Time) *StoredCandleRepository {
+	r.now = now
+	return r
+}
+
+// GetSeries implements the ports.CandleRepositoryPort interface.
+func (r *StoredCandleRepository) GetSeries(ctx context.Context, symbol domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+	// Candles opening before closed have ended and been published; they can no longer change.
+	closed := r.now().Add(-r.lag).Truncate(tf.Duration())
+	if closed.After(to) {
+		closed = to
+	}
+	if closed.Before(from) {
+		closed = from
+	}
+
+	covered, err := r.store.Covered(ctx, symbol, tf, from, closed)
+	if err != nil {
+		return r.next.GetSeries(ctx, symbol, tf, from, to)
+	}
+	fetchFrom, fetchTo, missing := uncoveredSpan(covered, from, closed)
+	if closed.Before(to) {
+		if !missing {
+			fetchFrom = closed
+		}
+		fetchTo, missing = to, true
+	}
+
+	var fetched domain.CandleSeries
+	if missing {
+		if fetched, err = r.next.GetSeries(ctx, symbol, tf, fetchFrom, fetchTo); err != nil {
+			return domain.CandleSeries{}, err
+		}
+		if fetchFrom.Before(closed) {
+			span := ports.TimeRange{From: fetchFrom, To: fetchTo}
+			if span.To.After(closed) {
+				span.To = closed
+			}
+			candles := fetched.Between(span.From, span.To)
+			// A span ending before closed ends where stored history begins, so nothing in it
+			// can be a truncated tail.
+			_ = r.store.Upsert(ctx, candles, answered(candles, span, span.To.Before(closed)))
+		}
+	}
+
+	stored, err := r.store.GetSeries(ctx, symbol, tf, from, closed)
+	if err != nil {
+		return r.next.GetSeries(ctx, symbol, tf, from, to)
+	}
+	if !missing {
+		return stored, nil
+	}
+	// The fetched span is authoritative, whether or not it could be stored.
+	candles := append(stored.Between(from, fetchFrom).All(), stored.Between(fetchTo, closed).All()...)
+	candles = append(candles, fetched.Between(fetchFrom, fetchTo).All()...)
+	return domain.NewCandleSeries(symbol, tf, candles)
+}
+
+// Refresh implements ports.CandleRefresherPort by loading [from, to) through GetSeries,
+// which stores the closed candles the store does not yet cover.
+func (r *StoredCandleRepository) Refresh(ctx context.Context, symbol domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) error {
+	_, err := r.GetSeries(ctx, symbol, tf, from, to)
+	return err
+}
+
+// answered returns the part of span the provider's answer candles settles: up to the end
+// of the last candle, or all of span if followed is set because stored history follows
+// it. It returns the zero range if nothing is settled.
+func answered(candles domain.CandleSeries, span ports.TimeRange, followed bool) ports.TimeRange {
+	if followed {
+		return span
+	}
+	last, err := candles.Last()
+	if err != nil {
+		return ports.TimeRange{}
+	}
+	return ports.TimeRange{From: span.From, To: last.Timestamp().Add(candles.Timeframe().Duration())}
+}
+
+// uncoveredSpan returns the span from the start of the first part of [from, to) not in
+// covered to the end of the last one, and whether there is any. covered must be ordered
+// and disjoint.
+func uncoveredSpan(covered []ports.TimeRange, from, to time.Time) (time.Time, time.Time, bool) {
+	var start, end time.Time
+	found := false
+	cursor := from
+	for _, c := range append(covered, ports.TimeRange{From: to, To: to}) {
+		if c.From.After(cursor) {
+			if !found {
+				start, found = cursor, true
+			}
+			end = c.From
+		}
+		if c.To.After(cursor) {
+			cursor = c.To
+		}
+	}
+	return start, end, found
+}
@@ -0,0 +1,134 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// WindowedCandleRepository is a decorator that splits ranges longer than a provider's
+// per-response row cap into windows of at most that many candles, one wrapped call each,
+// so providers that cap rows do not silently truncate long ranges.
+//
+// It belongs above RateLimitedCandleRepository and ResilientCandleRepository: every
+// window then takes its own rate-limit token, and a retry resends only the window that
+// failed.
+type WindowedCandleRepository struct {
+	wrapped     ports.CandleRepositoryPort
+	maxRows     int
+	concurrency int
+}
+
+// NewWindowedCandleRepository constructs the decorator. maxRows <= 0 passes every range
+// through as one call.
+func NewWindowedCandleRepository(wrapped ports.CandleRepositoryPort, maxRows int) *WindowedCandleRepository {
+	if wrapped == nil {
+		panic("wrapped repository is required")
+	}
+	return &WindowedCandleRepository{wrapped: wrapped, maxRows: maxRows, concurrency: 1}
+}
+
+// WithConcurrency bounds the number of windows fetched in parallel; n <= 0 keeps the
+// current value (1 by default). It returns r for chaining.
+func (r *WindowedCandleRepository) WithConcurrency(n int) *WindowedCandleRepository {
+	if n > 0 {
+		r.concurrency = n
+	}
+	return r
+}
+
+// GetSeries implements the ports.CandleRepositoryPort interface. Candles repeated at
+// window boundaries are kept once. If any window fails, the others are abandoned and
+// the first error is returned.
+func (r *WindowedCandleRepository) GetSeries(ctx context.Context, symbol domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+	windows := r.windows(tf, from, to)
+	if len(windows) == 1 {
+		return r.wrapped.GetSeries(ctx, symbol, tf, from, to)
+	}
+
+	pages := make([]domain.CandleSeries, len(windows))
+	if err := r.fetchWindows(ctx, symbol, tf, windows, pages); err != nil {
+		return domain.CandleSeries{}, err
+	}
+
+	seen := make(map[int64]bool)
+	var candles []domain.Candle
+	for _, page := range pages {
+		for _, c := range page.All() {
+			if ts := c.Timestamp().UnixNano(); !seen[ts] {
+				seen[ts] = true
+				candles = append(candles, c)
+			}
+		}
+	}
+	return domain.NewCandleSeries(symbol, tf, candles)
+}
+
+// windows splits [from, to) into consecutive windows of at most maxRows candles.
+func (r *WindowedCandleRepository) windows(tf domain.Timeframe, from, to time.Time) [][2]time.Time {
+	span := time.Duration(r.maxRows) * tf.Duration()
+	if r.maxRows <= 0 || span <= 0 || !to.After(from.Add(span)) {
+		return [][2]time.Time{{from, to}}
+	}
+	var windows [][2]time.Time
+	for start := from; start.Before(to); start = start.Add(span) {
+		end := start.Add(span)
+		if end.After(to) {
+			end = to
+		}
+		windows = append(windows, [2]time.Time{start, end})
+	}
+	return windows
+}
+
+// fetchWindows fetches windows into pages with at most r.concurrency calls in flight.
+func (r *WindowedCandleRepository) fetchWindows(ctx context.Context, symbol domain.Symbol, tf domain.Timeframe, windows [][2]time.Time, pages []domain.CandleSeries) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	sem := make(chan struct{}, r.concurrency)
+	for i, w := range windows {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int, from, to time.Time) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			series, err := r.wrapped.GetSeries(ctx, symbol, tf, from, to)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+				cancel()
+				return
+			}
+			pages[i] = series
+		}(i, w[0], w[1])
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	// The parent context ended before every window was started.
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("windowed request aborted: %w", err)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package ports
 
 import (
+	"context"
 	"time"
 
 	"github.com/akarso/pano_chart/backend/domain"
@@ -13,6 +14,8 @@ type CandleRepositoryPort interface {
 	// GetSeries retrieves a CandleSeries for a given symbol and timeframe within a time range.
 	//
 	// Parameters:
+	//   - ctx: carries the caller's deadline and cancellation; implementations must stop
+	//     work and return an error wrapping ctx.Err() once it is done
 	//   - symbol: the tradable instrument
 	//   - timeframe: the candle aggregation interval
 	//   - from: inclusive start time (UTC)
@@ -24,6 +27,7 @@ type CandleRepositoryPort interface {
 	//
 	// The returned series may contain gaps; this is expected when data is not available.
 	GetSeries(
+		ctx context.Context,
 		symbol domain.Symbol,
 		timeframe domain.Timeframe,
 		from time.Time,
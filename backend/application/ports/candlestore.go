@@ -0,0 +1,30 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// TimeRange is the half-open interval [From, To).
+type TimeRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// CandleStorePort is the write side of candle persistence: a durable store that
+// accumulates candles and remembers which ranges it holds completely.
+// GetSeries returns whatever has been stored within the range.
+type CandleStorePort interface {
+	CandleRepositoryPort
+
+	// Upsert stores the candles of series, replacing stored candles with the same
+	// timestamps, and records covered as completely known: candles absent from it do
+	// not exist upstream. The zero TimeRange records no coverage.
+	Upsert(ctx context.Context, series domain.CandleSeries, covered TimeRange) error
+
+	// Covered returns, in order, the maximal ranges recorded by Upsert for symbol and
+	// timeframe, clipped to [from, to).
+	Covered(ctx context.Context, symbol domain.Symbol, timeframe domain.Timeframe, from time.Time, to time.Time) ([]TimeRange, error)
+}
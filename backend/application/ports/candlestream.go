@@ -0,0 +1,51 @@
+package ports
+
+import (
+	"context"
+	"errors"
+
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// ErrStreamFull is returned by CandleStreamPort.Subscribe when accepting the subscription
+// would exceed the stream's limits on subscribers or watched series.
+var ErrStreamFull = errors.New("stream subscription limit reached")
+
+// SeriesKey identifies one candle series.
+type SeriesKey struct {
+	Symbol    domain.Symbol
+	Timeframe domain.Timeframe
+}
+
+// CandleUpdate is the latest state of one candle. Closed is false while the candle is
+// still forming, in which case later updates for the same timestamp replace it.
+type CandleUpdate struct {
+	Candle domain.Candle
+	Closed bool
+}
+
+// Key returns the series the update belongs to.
+func (u CandleUpdate) Key() SeriesKey {
+	return SeriesKey{Symbol: u.Candle.Symbol(), Timeframe: u.Candle.Timeframe()}
+}
+
+// CandlePublisherPort is implemented by the pub/sub hub that ingestion publishes into.
+type CandlePublisherPort interface {
+	// Publish passes update to the current subscribers of its series. It never blocks.
+	Publish(update CandleUpdate)
+
+	// Watched returns the series that currently have at least one subscriber, so
+	// publishers can limit polling to them.
+	Watched() []SeriesKey
+}
+
+// CandleStreamPort is a CandlePublisherPort that clients can subscribe to.
+type CandleStreamPort interface {
+	CandlePublisherPort
+
+	// Subscribe returns a channel carrying the updates for keys until ctx is done. The
+	// channel is closed when the subscription ends; if that happens before ctx is done,
+	// the subscriber fell too far behind and updates were lost. It fails with an error
+	// wrapping ErrStreamFull when the stream cannot take the subscription.
+	Subscribe(ctx context.Context, keys []SeriesKey) (<-chan CandleUpdate, error)
+}
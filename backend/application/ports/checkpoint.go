@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// CheckpointPort persists the progress of long-running jobs so they can resume after
+// a restart. Keys are chosen by the job; progress is the time up to which work is done.
+type CheckpointPort interface {
+	// Load returns the progress saved for key and whether there is any.
+	Load(ctx context.Context, key string) (time.Time, bool, error)
+
+	// Save records progress for key, replacing any earlier value.
+	Save(ctx context.Context, key string, progress time.Time) error
+}
@@ -0,0 +1,70 @@
+package ports
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Sentinel errors that CandleRepositoryPort implementations may return (wrapped).
+// Callers should match them with errors.Is.
+var (
+	// ErrRateLimited indicates the upstream provider rejected the call because a quota was exhausted.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrUpstreamUnavailable indicates the upstream provider failed or could not be reached.
+	ErrUpstreamUnavailable = errors.New("upstream unavailable")
+)
+
+// UpstreamStatusError reports a non-success HTTP status returned by an upstream provider.
+// It unwraps to ErrRateLimited for 429 and to ErrUpstreamUnavailable for 5xx responses.
+type UpstreamStatusError struct {
+	StatusCode int
+	// RetryAfter is the delay the provider asked for via Retry-After; 0 if none was given.
+	RetryAfter time.Duration
+}
+
+func (e *UpstreamStatusError) Error() string {
+	return fmt.Sprintf("unexpected upstream status: %d", e.StatusCode)
+}
+
+// Unwrap maps the status code to the matching sentinel error, if any.
+func (e *UpstreamStatusError) Unwrap() error {
+	switch {
+	case e.StatusCode == 429:
+		return ErrRateLimited
+	case e.StatusCode >= 500:
+		return ErrUpstreamUnavailable
+	default:
+		return nil
+	}
+}
+
+// RateLimitError reports a call refused before reaching the upstream provider because
+// the local request budget is exhausted. It unwraps to ErrRateLimited.
+type RateLimitError struct {
+	// RetryAfter is when the budget allows the call again.
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("request budget exhausted, retry after %v", e.RetryAfter)
+}
+
+// Unwrap reports the error as ErrRateLimited.
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// RetryAfter returns the retry delay carried by a *RateLimitError or *UpstreamStatusError
+// in err's chain, or 0 if there is none.
+func RetryAfter(err error) time.Duration {
+	var rle *RateLimitError
+	if errors.As(err, &rle) {
+		return rle.RetryAfter
+	}
+	var use *UpstreamStatusError
+	if errors.As(err, &use) {
+		return use.RetryAfter
+	}
+	return 0
+}
@@ -0,0 +1,44 @@
+package ports
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SourceRange records that the candles with From <= timestamp < To came from Source.
+type SourceRange struct {
+	Source string
+	From   time.Time
+	To     time.Time
+}
+
+// provenanceKey is the context key of the provenance recorder.
+type provenanceKey struct{}
+
+// provenanceRecorder collects SourceRanges; it is safe for concurrent use.
+type provenanceRecorder struct {
+	mu     sync.Mutex
+	ranges []SourceRange
+}
+
+// WithProvenance returns a context in which CandleRepositoryPort implementations that
+// combine several sources can report, via RecordProvenance, where candles came from.
+// The returned function lists the ranges recorded so far.
+func WithProvenance(ctx context.Context) (context.Context, func() []SourceRange) {
+	rec := &provenanceRecorder{}
+	return context.WithValue(ctx, provenanceKey{}, rec), func() []SourceRange {
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		return append([]SourceRange(nil), rec.ranges...)
+	}
+}
+
+// RecordProvenance records ranges on ctx. It is a no-op when ctx does not track provenance.
+func RecordProvenance(ctx context.Context, ranges ...SourceRange) {
+	if rec, ok := ctx.Value(provenanceKey{}).(*provenanceRecorder); ok {
+		rec.mu.Lock()
+		rec.ranges = append(rec.ranges, ranges...)
+		rec.mu.Unlock()
+	}
+}
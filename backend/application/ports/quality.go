@@ -0,0 +1,49 @@
+package ports
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// CandleIssue describes an upstream candle that was rejected or repaired on ingestion.
+// Timestamp is zero when the candle's timestamp could not be read.
+type CandleIssue struct {
+	Symbol    domain.Symbol
+	Timeframe domain.Timeframe
+	Timestamp time.Time
+	Reason    string
+	Repaired  bool
+}
+
+// qualityKey is the context key of the data-quality report.
+type qualityKey struct{}
+
+// qualityReport collects CandleIssues; it is safe for concurrent use.
+type qualityReport struct {
+	mu     sync.Mutex
+	issues []CandleIssue
+}
+
+// WithQualityReport returns a context in which CandleRepositoryPort implementations can
+// report, via RecordCandleIssues, upstream candles they rejected or repaired.
+// The returned function lists the issues recorded so far.
+func WithQualityReport(ctx context.Context) (context.Context, func() []CandleIssue) {
+	rep := &qualityReport{}
+	return context.WithValue(ctx, qualityKey{}, rep), func() []CandleIssue {
+		rep.mu.Lock()
+		defer rep.mu.Unlock()
+		return append([]CandleIssue(nil), rep.issues...)
+	}
+}
+
+// RecordCandleIssues records issues on ctx. It is a no-op when ctx has no quality report.
+func RecordCandleIssues(ctx context.Context, issues ...CandleIssue) {
+	if rep, ok := ctx.Value(qualityKey{}).(*qualityReport); ok {
+		rep.mu.Lock()
+		rep.issues = append(rep.issues, issues...)
+		rep.mu.Unlock()
+	}
+}
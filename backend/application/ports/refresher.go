@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// CandleRefresherPort is implemented by caching or storing CandleRepositoryPort
+// implementations that can reload a range from their source ahead of requests, so that
+// later reads of it are served locally.
+type CandleRefresherPort interface {
+	CandleRepositoryPort
+
+	// Refresh reloads the candles of [from, to) from the source, replacing what is held.
+	Refresh(ctx context.Context, symbol domain.Symbol, timeframe domain.Timeframe, from time.Time, to time.Time) error
+}
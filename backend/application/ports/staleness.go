@@ -0,0 +1,25 @@
+package ports
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// staleKey is the context key of the staleness tracker.
+type staleKey struct{}
+
+// WithStaleTracking returns a context in which CandleRepositoryPort implementations can
+// report, via MarkStale, that they served data past its freshness lifetime. The returned
+// function reports whether any did. It is safe for concurrent use.
+func WithStaleTracking(ctx context.Context) (context.Context, func() bool) {
+	flag := new(atomic.Bool)
+	return context.WithValue(ctx, staleKey{}, flag), flag.Load
+}
+
+// MarkStale records on ctx that stale data was served. It is a no-op when ctx does not
+// track staleness.
+func MarkStale(ctx context.Context) {
+	if flag, ok := ctx.Value(staleKey{}).(*atomic.Bool); ok {
+		flag.Store(true)
+	}
+}
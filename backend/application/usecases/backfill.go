@@ -0,0 +1,184 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// DefaultBackfillChunkCandles is the number of candles requested per repository call
+// when NewBackfill is given a non-positive chunk size.
+const DefaultBackfillChunkCandles = 1000
+
+const (
+	// backfillRateLimitRetries bounds the consecutive rate-limited attempts for one chunk.
+	backfillRateLimitRetries = 5
+	// defaultBackfillPause is the wait after a rate-limit error that carries no Retry-After.
+	defaultBackfillPause = time.Minute
+)
+
+// BackfillRequest selects the history to load: every timeframe of every symbol over
+// [From, To). The part of the range after the last closed candle is skipped; a candle
+// counts as closed once DefaultSyncDelay has passed since its period ended, the same
+// publication lag the stored repository waits for by default.
+type BackfillRequest struct {
+	Symbols    []domain.Symbol
+	Timeframes []domain.Timeframe
+	From       time.Time
+	To         time.Time
+}
+
+// BackfillResult is the outcome for one symbol and timeframe.
+// Through is the time up to which history is stored and covered, including earlier runs:
+// the end of the last candle the provider returned. Candles missing before it are gaps the
+// provider has answered for and are covered as such; the range after it is not, since it
+// cannot be told apart from a truncated response, and a later run resumes from Through.
+// Missing counts the candles of this run the provider did not return.
+type BackfillResult struct {
+	Symbol    domain.Symbol
+	Timeframe domain.Timeframe
+	Through   time.Time
+	Candles   int
+	Missing   int
+	Resumed   bool
+	Err       error
+}
+
+// Backfill defines the use case interface for loading history into a store.
+type Backfill interface {
+	Execute(ctx context.Context, req BackfillRequest) ([]BackfillResult, error)
+}
+
+// backfill is the concrete implementation of the use case.
+type backfill struct {
+	repo         ports.CandleRepositoryPort
+	store        ports.CandleStorePort
+	checkpoints  ports.CheckpointPort
+	chunkCandles int
+	now          func() time.Time
+}
+
+// NewBackfill constructs the use case with injected dependencies. repo should be the
+// provider, rate-limited as needed; chunkCandles is the provider's per-request row limit,
+// and values <= 0 use DefaultBackfillChunkCandles.
+func NewBackfill(repo ports.CandleRepositoryPort, store ports.CandleStorePort, checkpoints ports.CheckpointPort, chunkCandles int) Backfill {
+	if chunkCandles <= 0 {
+		chunkCandles = DefaultBackfillChunkCandles
+	}
+	return &backfill{repo: repo, store: store, checkpoints: checkpoints, chunkCandles: chunkCandles, now: time.Now}
+}
+
+// Execute walks each series one chunk at a time, oldest first, storing every chunk that has
+// candles with the range from Through to its last candle as covered and checkpointing
+// there, so an interrupted run resumes where it stopped. Empty chunks are skipped without
+// covering anything. Series are processed one after another to stay within the provider's quota;
+// rate-limit errors are waited out (honoring Retry-After) and the chunk is retried.
+// Per-series failures are recorded on the corresponding result and do not fail the call.
+// An error is returned only when the request is unusable or ctx ends; the results of
+// the series processed so far are returned with it.
+func (b *backfill) Execute(ctx context.Context, req BackfillRequest) ([]BackfillResult, error) {
+	if len(req.Symbols) == 0 {
+		return nil, fmt.Errorf("%w: at least one symbol is required", domain.ErrInvalidSymbol)
+	}
+	if len(req.Timeframes) == 0 {
+		return nil, fmt.Errorf("%w: at least one timeframe is required", domain.ErrInvalidTimeframe)
+	}
+	if err := domain.ValidateRange(req.From, req.To); err != nil {
+		return nil, err
+	}
+
+	results := make([]BackfillResult, 0, len(req.Symbols)*len(req.Timeframes))
+	for _, sym := range req.Symbols {
+		for _, tf := range req.Timeframes {
+			res := b.run(ctx, sym, tf, req.From, req.To)
+			results = append(results, res)
+			if err := ctx.Err(); err != nil {
+				return results, err
+			}
+		}
+	}
+	return results, nil
+}
+
+// run backfills one series.
+func (b *backfill) run(ctx context.Context, sym domain.Symbol, tf domain.Timeframe, from, to time.Time) BackfillResult {
+	res := BackfillResult{Symbol: sym, Timeframe: tf, Through: from}
+	step := tf.Duration()
+	end := to
+	if closed := b.now().Add(-DefaultSyncDelay).Truncate(step); closed.Before(end) {
+		end = closed
+	}
+
+	key := backfillKey(sym, tf, from)
+	if done, ok, err := b.checkpoints.Load(ctx, key); err != nil {
+		res.Err = err
+		return res
+	} else if ok && done.After(res.Through) {
+		res.Through, res.Resumed = done, true
+	}
+
+	span := time.Duration(b.chunkCandles) * step
+	for cursor := res.Through; cursor.Before(end); {
+		next := cursor.Add(span)
+		if next.After(end) {
+			next = end
+		}
+		series, err := b.fetch(ctx, sym, tf, cursor, next)
+		if err != nil {
+			res.Err = err
+			return res
+		}
+		chunk := series.Between(cursor, next)
+		res.Candles += chunk.Len()
+		res.Missing += int(next.Sub(cursor)/step) - chunk.Len()
+		cursor = next
+		last, err := chunk.Last()
+		if err != nil {
+			// An empty chunk is not proof that its candles do not exist.
+			continue
+		}
+		through := last.Timestamp().Add(step)
+		if err := b.store.Upsert(ctx, chunk, ports.TimeRange{From: res.Through, To: through}); err != nil {
+			res.Err = err
+			return res
+		}
+		if err := b.checkpoints.Save(ctx, key, through); err != nil {
+			res.Err = err
+			return res
+		}
+		res.Through = through
+	}
+	return res
+}
+
+// fetch loads one chunk, waiting out rate-limit errors.
+func (b *backfill) fetch(ctx context.Context, sym domain.Symbol, tf domain.Timeframe, from, to time.Time) (domain.CandleSeries, error) {
+	for attempt := 1; ; attempt++ {
+		series, err := b.repo.GetSeries(ctx, sym, tf, from, to)
+		if err == nil || !errors.Is(err, ports.ErrRateLimited) || attempt >= backfillRateLimitRetries {
+			return series, err
+		}
+		wait := ports.RetryAfter(err)
+		if wait <= 0 {
+			wait = defaultBackfillPause
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return domain.CandleSeries{}, ctx.Err()
+		}
+	}
+}
+
+// backfillKey identifies the checkpoint of one series walked from from. The end of the
+// range is left out so that extending it, as repeated runs up to now do, resumes rather
+// than restarts.
+func backfillKey(sym domain.Symbol, tf domain.Timeframe, from time.Time) string {
+	return fmt.Sprintf("backfill|%s|%s|%s", sym, tf, from.UTC().Format(time.RFC3339))
+}
@@ -0,0 +1,34 @@
+package usecases
+
+import (
+	"context"
+	"sync"
+
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// forEachSymbol calls fn for every symbol with at most concurrency calls in flight
+// and returns once all started calls have finished. fn receives the symbol's index so
+// results can be written to a pre-sized slice without further synchronisation.
+// Once ctx is done no further calls are started; callers should check ctx.Err().
+func forEachSymbol(ctx context.Context, symbols []domain.Symbol, concurrency int, fn func(i int, sym domain.Symbol)) {
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, sym := range symbols {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int, sym domain.Symbol) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i, sym)
+		}(i, sym)
+	}
+	wg.Wait()
+}
@@ -1,6 +1,7 @@
 package usecases
 
 import (
+	"context"
 	"time"
 
 	"github.com/akarso/pano_chart/backend/application/ports"
@@ -9,7 +10,7 @@ import (
 
 // GetCandleSeries defines the use case interface.
 type GetCandleSeries interface {
-	Execute(symbol domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error)
+	Execute(ctx context.Context, symbol domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error)
 }
 
 // getCandleSeries is the concrete implementation of the use case.
@@ -22,7 +23,11 @@ func NewGetCandleSeries(repo ports.CandleRepositoryPort) GetCandleSeries {
 	return &getCandleSeries{repo: repo}
 }
 
-// Execute delegates retrieval to the CandleRepositoryPort and returns the result unchanged.
-func (g *getCandleSeries) Execute(symbol domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
-	return g.repo.GetSeries(symbol, tf, from, to)
+// Execute validates the requested range, then delegates retrieval to the CandleRepositoryPort
+// and returns the result unchanged. An inverted or empty range yields an error wrapping domain.ErrInvalidRange.
+func (g *getCandleSeries) Execute(ctx context.Context, symbol domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+	if err := domain.ValidateRange(from, to); err != nil {
+		return domain.CandleSeries{}, err
+	}
+	return g.repo.GetSeries(ctx, symbol, tf, from, to)
 }
@@ -0,0 +1,74 @@
+package usecases
+
+import (
+	"context"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+	"github.com/akarso/pano_chart/backend/domain/indicators"
+)
+
+// IndicatorOutput pairs an indicator spec with its computed output lines.
+type IndicatorOutput struct {
+	Spec  indicators.Spec
+	Lines []indicators.Series
+}
+
+// IndicatorSeries is the result of the GetIndicators use case.
+// Series holds the candles within the requested range and every output line is
+// aligned with those candles.
+type IndicatorSeries struct {
+	Series     domain.CandleSeries
+	Indicators []IndicatorOutput
+}
+
+// GetIndicators defines the use case interface for computing indicators over a candle range.
+type GetIndicators interface {
+	Execute(ctx context.Context, symbol domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time, specs []indicators.Spec, gaps indicators.GapMode) (IndicatorSeries, error)
+}
+
+// getIndicators is the concrete implementation of the use case.
+type getIndicators struct {
+	repo ports.CandleRepositoryPort
+}
+
+// NewGetIndicators constructs the use case with injected dependencies.
+func NewGetIndicators(repo ports.CandleRepositoryPort) GetIndicators {
+	return &getIndicators{repo: repo}
+}
+
+// Execute loads the requested range plus enough earlier candles to cover the longest
+// indicator warm-up, computes every indicator, and trims outputs back to [from, to).
+// Values are therefore valid from the first requested candle whenever history allows.
+func (g *getIndicators) Execute(ctx context.Context, symbol domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time, specs []indicators.Spec, gaps indicators.GapMode) (IndicatorSeries, error) {
+	if err := domain.ValidateRange(from, to); err != nil {
+		return IndicatorSeries{}, err
+	}
+
+	warmUp := 0
+	for _, s := range specs {
+		if w := s.WarmUp(); w > warmUp {
+			warmUp = w
+		}
+	}
+
+	series, err := g.repo.GetSeries(ctx, symbol, tf, from.Add(-time.Duration(warmUp)*tf.Duration()), to)
+	if err != nil {
+		return IndicatorSeries{}, err
+	}
+
+	outputs := make([]IndicatorOutput, 0, len(specs))
+	for _, s := range specs {
+		lines, err := s.Compute(series, gaps)
+		if err != nil {
+			return IndicatorSeries{}, err
+		}
+		for i := range lines {
+			lines[i] = lines[i].Since(from)
+		}
+		outputs = append(outputs, IndicatorOutput{Spec: s, Lines: lines})
+	}
+
+	return IndicatorSeries{Series: series.Between(from, to), Indicators: outputs}, nil
+}
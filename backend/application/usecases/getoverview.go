@@ -0,0 +1,83 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+	"github.com/akarso/pano_chart/backend/domain/analysis"
+)
+
+// DefaultOverviewConcurrency is the number of concurrent repository calls used
+// when NewGetOverview is given a non-positive limit.
+const DefaultOverviewConcurrency = 8
+
+// OverviewEntry holds the outcome of loading a single symbol.
+// Exactly one of Series or Err is meaningful: when Err is non-nil the series is empty.
+// Range is nil when the symbol failed or the series is too short to analyse.
+type OverviewEntry struct {
+	Symbol domain.Symbol
+	Series domain.CandleSeries
+	Range  *analysis.RangeAnalysis
+	Err    error
+}
+
+// Overview is the result of the GetOverview use case.
+// Entries are returned in the same order as the requested symbols.
+type Overview struct {
+	Timeframe domain.Timeframe
+	Entries   []OverviewEntry
+}
+
+// GetOverview defines the use case interface for loading many symbols at once.
+type GetOverview interface {
+	Execute(ctx context.Context, symbols []domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (Overview, error)
+}
+
+// getOverview is the concrete implementation of the use case.
+type getOverview struct {
+	repo        ports.CandleRepositoryPort
+	concurrency int
+}
+
+// NewGetOverview constructs the use case with injected dependencies.
+// concurrency bounds the number of in-flight repository calls; values <= 0 use DefaultOverviewConcurrency.
+func NewGetOverview(repo ports.CandleRepositoryPort, concurrency int) GetOverview {
+	if concurrency <= 0 {
+		concurrency = DefaultOverviewConcurrency
+	}
+	return &getOverview{repo: repo, concurrency: concurrency}
+}
+
+// Execute fans out over the repository with bounded parallelism and scores each
+// loaded series with analysis.AnalyzeRange.
+// Per-symbol failures are recorded on the corresponding entry and do not fail the call.
+// An error is returned only when the request itself is unusable or ctx ends before
+// every symbol has loaded.
+func (g *getOverview) Execute(ctx context.Context, symbols []domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (Overview, error) {
+	if len(symbols) == 0 {
+		return Overview{}, fmt.Errorf("%w: at least one symbol is required", domain.ErrInvalidSymbol)
+	}
+	if err := domain.ValidateRange(from, to); err != nil {
+		return Overview{}, err
+	}
+
+	entries := make([]OverviewEntry, len(symbols))
+	forEachSymbol(ctx, symbols, g.concurrency, func(i int, sym domain.Symbol) {
+		series, err := g.repo.GetSeries(ctx, sym, tf, from, to)
+		entry := OverviewEntry{Symbol: sym, Series: series, Err: err}
+		if err == nil {
+			if ra, aerr := analysis.AnalyzeRange(series, analysis.DefaultRangeConfig()); aerr == nil {
+				entry.Range = &ra
+			}
+		}
+		entries[i] = entry
+	})
+	if err := ctx.Err(); err != nil {
+		return Overview{}, err
+	}
+
+	return Overview{Timeframe: tf, Entries: entries}, nil
+}
@@ -0,0 +1,92 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+	"github.com/akarso/pano_chart/backend/domain/analysis"
+	"github.com/akarso/pano_chart/backend/domain/indicators"
+)
+
+// VolatilityValue is the realized volatility of one estimator.
+// Valid is false when the series is too short (or unusable) for the estimator.
+type VolatilityValue struct {
+	Estimator indicators.Estimator
+	Value     float64
+	Valid     bool
+}
+
+// VolatilityEntry holds the volatility of a single symbol over the requested range.
+// Values follow the order of the requested estimators; it is empty when Err is non-nil.
+type VolatilityEntry struct {
+	Symbol  domain.Symbol
+	Candles int
+	Values  []VolatilityValue
+	Err     error
+}
+
+// VolatilityReport is the result of the GetVolatility use case.
+// Entries are returned in the same order as the requested symbols.
+type VolatilityReport struct {
+	Timeframe domain.Timeframe
+	Entries   []VolatilityEntry
+}
+
+// GetVolatility defines the use case interface for comparing realized volatility across symbols.
+type GetVolatility interface {
+	Execute(ctx context.Context, symbols []domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time, estimators []indicators.Estimator) (VolatilityReport, error)
+}
+
+// getVolatility is the concrete implementation of the use case.
+type getVolatility struct {
+	repo        ports.CandleRepositoryPort
+	concurrency int
+}
+
+// NewGetVolatility constructs the use case with injected dependencies.
+// concurrency bounds the number of in-flight repository calls; values <= 0 use DefaultOverviewConcurrency.
+func NewGetVolatility(repo ports.CandleRepositoryPort, concurrency int) GetVolatility {
+	if concurrency <= 0 {
+		concurrency = DefaultOverviewConcurrency
+	}
+	return &getVolatility{repo: repo, concurrency: concurrency}
+}
+
+// Execute loads every symbol with bounded parallelism and computes the annualized
+// realized volatility of each requested estimator over [from, to).
+// Per-symbol failures are recorded on the corresponding entry and do not fail the call;
+// ctx ending before every symbol has loaded does.
+func (g *getVolatility) Execute(ctx context.Context, symbols []domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time, estimators []indicators.Estimator) (VolatilityReport, error) {
+	if len(symbols) == 0 {
+		return VolatilityReport{}, fmt.Errorf("%w: at least one symbol is required", domain.ErrInvalidSymbol)
+	}
+	if len(estimators) == 0 {
+		return VolatilityReport{}, fmt.Errorf("%w: at least one volatility estimator is required", indicators.ErrInvalidParameter)
+	}
+	if err := domain.ValidateRange(from, to); err != nil {
+		return VolatilityReport{}, err
+	}
+
+	entries := make([]VolatilityEntry, len(symbols))
+	forEachSymbol(ctx, symbols, g.concurrency, func(i int, sym domain.Symbol) {
+		series, err := g.repo.GetSeries(ctx, sym, tf, from, to)
+		if err != nil {
+			entries[i] = VolatilityEntry{Symbol: sym, Err: err}
+			return
+		}
+		values := make([]VolatilityValue, len(estimators))
+		for j, est := range estimators {
+			v, verr := analysis.RealizedVolatility(series, est)
+			values[j] = VolatilityValue{Estimator: est, Value: v, Valid: verr == nil}
+		}
+		entries[i] = VolatilityEntry{Symbol: sym, Candles: series.Len(), Values: values}
+	})
+	if err := ctx.Err(); err != nil {
+		return VolatilityReport{}, err
+	}
+
+	return VolatilityReport{Timeframe: tf, Entries: entries}, nil
+}
@@ -0,0 +1,138 @@
+package usecases
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// DefaultPollFormingInterval is how often PollForming polls when NewPollForming is given a
+// non-positive interval.
+const DefaultPollFormingInterval = 5 * time.Second
+
+// PollForming defines the use case interface for publishing the candles of watched
+// series as they form.
+type PollForming interface {
+	// Execute polls every watched series once, at now.
+	Execute(ctx context.Context, now time.Time) error
+	// Run polls every interval until ctx is done.
+	Run(ctx context.Context)
+}
+
+// pollForming is the concrete implementation of the use case.
+type pollForming struct {
+	repo        ports.CandleRepositoryPort
+	publisher   ports.CandlePublisherPort
+	interval    time.Duration
+	concurrency int
+	now         func() time.Time
+
+	mu   sync.Mutex
+	last map[ports.SeriesKey]ports.CandleUpdate
+}
+
+// NewPollForming constructs the use case with injected dependencies. repo should reach
+// the provider without caching, as forming candles change until they close; only the
+// series publisher reports as watched are polled, so the provider is not queried for
+// series nobody follows. concurrency <= 0 uses DefaultOverviewConcurrency.
+func NewPollForming(repo ports.CandleRepositoryPort, publisher ports.CandlePublisherPort, interval time.Duration, concurrency int) PollForming {
+	if interval <= 0 {
+		interval = DefaultPollFormingInterval
+	}
+	if concurrency <= 0 {
+		concurrency = DefaultOverviewConcurrency
+	}
+	return &pollForming{
+		repo:        repo,
+		publisher:   publisher,
+		interval:    interval,
+		concurrency: concurrency,
+		now:         time.Now,
+		last:        make(map[ports.SeriesKey]ports.CandleUpdate),
+	}
+}
+
+// Execute fetches the current bucket of every watched series and publishes its candle
+// when it changed since the previous poll. When a series has moved to a new bucket, the
+// candle published last is fetched again and published as closed, so subscribers see
+// its final state.
+// Per-series failures are skipped and retried on the next poll. An error is returned only
+// when ctx ends before every series has been polled.
+func (p *pollForming) Execute(ctx context.Context, now time.Time) error {
+	watched := p.publisher.Watched()
+	byTimeframe := make(map[domain.Timeframe][]domain.Symbol)
+	keep := make(map[ports.SeriesKey]bool, len(watched))
+	for _, key := range watched {
+		byTimeframe[key.Timeframe] = append(byTimeframe[key.Timeframe], key.Symbol)
+		keep[key] = true
+	}
+
+	p.mu.Lock()
+	for key := range p.last {
+		if !keep[key] {
+			delete(p.last, key)
+		}
+	}
+	p.mu.Unlock()
+
+	for tf, symbols := range byTimeframe {
+		forEachSymbol(ctx, symbols, p.concurrency, func(_ int, sym domain.Symbol) {
+			p.poll(ctx, ports.SeriesKey{Symbol: sym, Timeframe: tf}, now)
+		})
+	}
+	return ctx.Err()
+}
+
+// poll fetches and publishes one series.
+func (p *pollForming) poll(ctx context.Context, key ports.SeriesKey, now time.Time) {
+	step := key.Timeframe.Duration()
+	bucket := now.UTC().Truncate(step)
+	from := bucket
+	p.mu.Lock()
+	last, seen := p.last[key]
+	p.mu.Unlock()
+	if seen && !last.Closed && last.Candle.Timestamp().Before(bucket) {
+		from = last.Candle.Timestamp()
+	}
+
+	series, err := p.repo.GetSeries(ctx, key.Symbol, key.Timeframe, from, bucket.Add(step))
+	if err != nil {
+		return
+	}
+	for _, c := range series.Between(from, bucket.Add(step)).All() {
+		update := ports.CandleUpdate{Candle: c, Closed: c.Timestamp().Before(bucket)}
+		if seen && update.Closed == last.Closed && sameValues(c, last.Candle) {
+			continue
+		}
+		p.publisher.Publish(update)
+		last, seen = update, true
+	}
+	p.mu.Lock()
+	if seen {
+		p.last[key] = last
+	}
+	p.mu.Unlock()
+}
+
+// sameValues reports whether a and b are the same candle with the same prices and volume.
+func sameValues(a, b domain.Candle) bool {
+	return a.Equals(b) && a.Open() == b.Open() && a.High() == b.High() && a.Low() == b.Low() &&
+		a.Close() == b.Close() && a.Volume() == b.Volume()
+}
+
+// Run implements PollForming.
+func (p *pollForming) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = p.Execute(ctx, p.now())
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+	"github.com/akarso/pano_chart/backend/domain/analysis"
+	"github.com/akarso/pano_chart/backend/domain/indicators"
+)
+
+// Metric names a value computed per symbol by the screener.
+type Metric string
+
+// Supported screener metrics.
+const (
+	// MetricPrice is the last close.
+	MetricPrice Metric = "price"
+	// MetricChange is the percent change from the first open to the last close of the lookback.
+	MetricChange Metric = "change"
+	// MetricChange24h is the percent change of the last close versus the close 24 hours earlier.
+	MetricChange24h Metric = "change_24h"
+	// MetricVolatility is the annualized close-to-close volatility over the lookback.
+	MetricVolatility Metric = "volatility"
+	// MetricRangeScore is analysis.RangeAnalysis.Score (higher is more sideways).
+	MetricRangeScore Metric = "range_score"
+	// MetricADX is the latest ADX(14).
+	MetricADX Metric = "adx"
+	// MetricVolumeSurge is the last candle's volume divided by the mean volume of the
+	// preceding candles in the lookback.
+	MetricVolumeSurge Metric = "volume_surge"
+)
+
+// Metrics lists every supported metric in canonical order.
+var Metrics = []Metric{MetricPrice, MetricChange, MetricChange24h, MetricVolatility, MetricRangeScore, MetricADX, MetricVolumeSurge}
+
+// ParseMetric parses a metric name case-insensitively.
+func ParseMetric(s string) (Metric, error) {
+	m := Metric(strings.ToLower(strings.TrimSpace(s)))
+	for _, known := range Metrics {
+		if m == known {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("%w: unknown metric %q", ErrInvalidScreenerQuery, s)
+}
+
+// ScreenerQuery describes a screener run.
+type ScreenerQuery struct {
+	Symbols   []domain.Symbol
+	Timeframe domain.Timeframe
+	// Lookback is the number of candles loaded per symbol; at least 2.
+	Lookback int
+	// To is the exclusive end of the lookback window. The zero value selects the
+	// current, still-forming candle as the last one.
+	To     time.Time
+	Filter Filter
+	// SortBy orders rows by a metric, descending unless Ascending is set. Rows missing
+	// the metric sort last. The zero value keeps the universe order.
+	SortBy    Metric
+	Ascending bool
+	// Limit caps the number of returned rows after filtering and sorting; 0 means no limit.
+	Limit int
+}
+
+// ScreenerRow holds the metrics of one symbol. Metrics that cannot be computed
+// (e.g. a lookback too short for the range score) are absent from the map.
+type ScreenerRow struct {
+	Symbol  domain.Symbol
+	Metrics map[Metric]float64
+}
+
+// ScreenerFailure records a symbol whose series could not be loaded.
+type ScreenerFailure struct {
+	Symbol domain.Symbol
+	Err    error
+}
+
+// ScreenerResult is the result of the Screener use case.
+type ScreenerResult struct {
+	Timeframe domain.Timeframe
+	From      time.Time
+	To        time.Time
+	Rows      []ScreenerRow
+	Failures  []ScreenerFailure
+}
+
+// Screener defines the use case interface for ranking a symbol universe by metrics.
+type Screener interface {
+	Execute(ctx context.Context, q ScreenerQuery) (ScreenerResult, error)
+}
+
+// screener is the concrete implementation of the use case.
+type screener struct {
+	repo        ports.CandleRepositoryPort
+	concurrency int
+	now         func() time.Time
+}
+
+// NewScreener constructs the use case with injected dependencies.
+// concurrency bounds the number of in-flight repository calls; values <= 0 use DefaultOverviewConcurrency.
+func NewScreener(repo ports.CandleRepositoryPort, concurrency int) Screener {
+	if concurrency <= 0 {
+		concurrency = DefaultOverviewConcurrency
+	}
+	return &screener{repo: repo, concurrency: concurrency, now: time.Now}
+}
+
+// Execute loads the lookback window of every symbol with bounded parallelism, computes
+// all metrics, then filters, sorts and truncates the rows. Symbols that fail to load are
+// reported in Failures and do not fail the call; ctx ending before every symbol has
+// loaded does.
+func (s *screener) Execute(ctx context.Context, q ScreenerQuery) (ScreenerResult, error) {
+	if len(q.Symbols) == 0 {
+		return ScreenerResult{}, fmt.Errorf("%w: at least one symbol is required", domain.ErrInvalidSymbol)
+	}
+	if q.Timeframe.Duration() == 0 {
+		return ScreenerResult{}, fmt.Errorf("%w: unsupported value %q", domain.ErrInvalidTimeframe, q.Timeframe)
+	}
+	if q.Lookback < 2 {
+		return ScreenerResult{}, fmt.Errorf("%w: lookback must be at least 2 candles, got %d", ErrInvalidScreenerQuery, q.Lookback)
+	}
+	if q.Limit < 0 {
+		return ScreenerResult{}, fmt.Errorf("%w: limit must be non-negative, got %d", ErrInvalidScreenerQuery, q.Limit)
+	}
+	if q.SortBy != "" {
+		if _, err := ParseMetric(string(q.SortBy)); err != nil {
+			return ScreenerResult{}, err
+		}
+	}
+
+	tf := q.Timeframe.Duration()
+	to := q.To
+	if to.IsZero() {
+		to = s.now().UTC().Truncate(tf).Add(tf)
+	}
+	from := to.Add(-time.Duration(q.Lookback) * tf)
+
+	type outcome struct {
+		row ScreenerRow
+		err error
+	}
+	outcomes := make([]outcome, len(q.Symbols))
+	forEachSymbol(ctx, q.Symbols, s.concurrency, func(i int, sym domain.Symbol) {
+		series, err := s.repo.GetSeries(ctx, sym, q.Timeframe, from, to)
+		outcomes[i] = outcome{row: ScreenerRow{Symbol: sym, Metrics: computeMetrics(series)}, err: err}
+	})
+	if err := ctx.Err(); err != nil {
+		return ScreenerResult{}, err
+	}
+
+	res := ScreenerResult{Timeframe: q.Timeframe, From: from, To: to, Rows: []ScreenerRow{}}
+	for i, o := range outcomes {
+		if o.err != nil {
+			res.Failures = append(res.Failures, ScreenerFailure{Symbol: q.Symbols[i], Err: o.err})
+			continue
+		}
+		if q.Filter.Matches(o.row.Metrics) {
+			res.Rows = append(res.Rows, o.row)
+		}
+	}
+
+	if q.SortBy != "" {
+		sort.SliceStable(res.Rows, func(i, j int) bool {
+			a, aok := res.Rows[i].Metrics[q.SortBy]
+			b, bok := res.Rows[j].Metrics[q.SortBy]
+			if !aok || !bok {
+				return aok && !bok
+			}
+			if q.Ascending {
+				return a < b
+			}
+			return a > b
+		})
+	}
+	if q.Limit > 0 && len(res.Rows) > q.Limit {
+		res.Rows = res.Rows[:q.Limit]
+	}
+	return res, nil
+}
+
+// computeMetrics derives every metric that the series supports.
+func computeMetrics(cs domain.CandleSeries) map[Metric]float64 {
+	m := map[Metric]float64{}
+	candles := cs.All()
+	if len(candles) == 0 {
+		return m
+	}
+	first, last := candles[0], candles[len(candles)-1]
+
+	m[MetricPrice] = last.Close()
+	if first.Open() > 0 {
+		m[MetricChange] = percentChange(first.Open(), last.Close())
+	}
+	dayAgo := last.Timestamp().Add(-24 * time.Hour)
+	for i := len(candles) - 2; i >= 0; i-- {
+		if !candles[i].Timestamp().After(dayAgo) {
+			if candles[i].Close() > 0 {
+				m[MetricChange24h] = percentChange(candles[i].Close(), last.Close())
+			}
+			break
+		}
+	}
+	if v, err := analysis.RealizedVolatility(cs, indicators.CloseToClose); err == nil {
+		m[MetricVolatility] = v
+	}
+	if ra, err := analysis.AnalyzeRange(cs, analysis.DefaultRangeConfig()); err == nil {
+		m[MetricRangeScore] = ra.Score
+		m[MetricADX] = ra.ADX
+	}
+	if len(candles) > 1 {
+		sum := 0.0
+		for _, c := range candles[:len(candles)-1] {
+			sum += c.Volume()
+		}
+		if mean := sum / float64(len(candles)-1); mean > 0 {
+			m[MetricVolumeSurge] = last.Volume() / mean
+		}
+	}
+	return m
+}
+
+func percentChange(from, to float64) float64 {
+	return (to/from - 1) * 100
+}
@@ -0,0 +1,104 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidScreenerQuery is returned (wrapped) when a screener filter, sort key or
+// limit cannot be used.
+var ErrInvalidScreenerQuery = errors.New("invalid screener query")
+
+// Comparison is a filter operator.
+type Comparison string
+
+// Supported filter operators.
+const (
+	OpGreater      Comparison = ">"
+	OpGreaterEqual Comparison = ">="
+	OpLess         Comparison = "<"
+	OpLessEqual    Comparison = "<="
+	OpEqual        Comparison = "="
+	OpNotEqual     Comparison = "!="
+)
+
+// Condition compares one metric against a constant, e.g. "volatility > 0.5".
+type Condition struct {
+	Metric Metric
+	Op     Comparison
+	Value  float64
+}
+
+// Matches reports whether the condition holds for the given metrics.
+// A condition on a metric that is not available never matches.
+func (c Condition) Matches(metrics map[Metric]float64) bool {
+	v, ok := metrics[c.Metric]
+	if !ok {
+		return false
+	}
+	switch c.Op {
+	case OpGreater:
+		return v > c.Value
+	case OpGreaterEqual:
+		return v >= c.Value
+	case OpLess:
+		return v < c.Value
+	case OpLessEqual:
+		return v <= c.Value
+	case OpEqual:
+		return v == c.Value
+	case OpNotEqual:
+		return v != c.Value
+	default:
+		return false
+	}
+}
+
+// Filter is a conjunction of conditions; the zero value matches everything.
+type Filter []Condition
+
+// Matches reports whether every condition holds.
+func (f Filter) Matches(metrics map[Metric]float64) bool {
+	for _, c := range f {
+		if !c.Matches(metrics) {
+			return false
+		}
+	}
+	return true
+}
+
+// conditionPattern matches "metric op number" with optional surrounding whitespace.
+var conditionPattern = regexp.MustCompile(`^\s*([a-z0-9_]+)\s*(>=|<=|!=|>|<|=)\s*(\S+)\s*$`)
+
+// andPattern splits conditions on a case-insensitive AND keyword.
+var andPattern = regexp.MustCompile(`(?i)\s+and\s+`)
+
+// ParseFilter parses expressions such as "volatility > 0.5 AND change_24h < 2".
+// Conditions are joined with AND (case-insensitive); OR and parentheses are not supported.
+// An empty expression yields an empty filter.
+func ParseFilter(s string) (Filter, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var f Filter
+	for _, part := range andPattern.Split(strings.TrimSpace(s), -1) {
+		m := conditionPattern.FindStringSubmatch(strings.ToLower(part))
+		if m == nil {
+			return nil, fmt.Errorf("%w: cannot parse condition %q (want \"metric op number\")", ErrInvalidScreenerQuery, part)
+		}
+		metric, err := ParseMetric(m[1])
+		if err != nil {
+			return nil, err
+		}
+		v, err := strconv.ParseFloat(m[3], 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("%w: %q is not a number", ErrInvalidScreenerQuery, m[3])
+		}
+		f = append(f, Condition{Metric: metric, Op: Comparison(m[2]), Value: v})
+	}
+	return f, nil
+}
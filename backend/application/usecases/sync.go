@@ -0,0 +1,188 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+const (
+	// DefaultSyncDelay is how long after a bucket boundary SyncLatest waits, when
+	// SyncConfig.Delay is not positive, so the provider has published the closed candle.
+	DefaultSyncDelay = 5 * time.Second
+	// DefaultSyncLookback is the number of candles loaded by the first sync of a series
+	// when SyncConfig.Lookback is not positive.
+	DefaultSyncLookback = 360
+)
+
+// SyncConfig selects the series kept fresh by SyncLatest.
+type SyncConfig struct {
+	Universe   []domain.Symbol
+	Timeframes []domain.Timeframe
+	Delay      time.Duration
+	Lookback   int
+	// Concurrency bounds parallel refreshes; values <= 0 use DefaultOverviewConcurrency.
+	Concurrency int
+	// Publisher, if set, receives every newly synced candle as closed.
+	Publisher ports.CandlePublisherPort
+}
+
+// SyncResult is the outcome of syncing one series. Watermark is the end of the synced
+// history: the end of the last candle loaded. When Err is non-nil the watermark was not
+// advanced.
+type SyncResult struct {
+	Symbol    domain.Symbol
+	Timeframe domain.Timeframe
+	Watermark time.Time
+	Err       error
+}
+
+// SyncLatest defines the use case interface for keeping the latest candles loaded.
+type SyncLatest interface {
+	// Execute syncs every symbol for tf up to the last candle closed at now.
+	Execute(ctx context.Context, tf domain.Timeframe, now time.Time) ([]SyncResult, error)
+	// Run syncs every timeframe at once and then shortly after each of its bucket
+	// boundaries, until ctx is done.
+	Run(ctx context.Context)
+}
+
+// syncLatest is the concrete implementation of the use case.
+type syncLatest struct {
+	refresher  ports.CandleRefresherPort
+	watermarks ports.CheckpointPort
+	cfg        SyncConfig
+	now        func() time.Time
+}
+
+// NewSyncLatest constructs the use case with injected dependencies. refresher is the
+// cache or store to load candles into; watermarks records per-series progress.
+func NewSyncLatest(refresher ports.CandleRefresherPort, watermarks ports.CheckpointPort, cfg SyncConfig) SyncLatest {
+	if cfg.Delay <= 0 {
+		cfg.Delay = DefaultSyncDelay
+	}
+	if cfg.Lookback <= 0 {
+		cfg.Lookback = DefaultSyncLookback
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = DefaultOverviewConcurrency
+	}
+	return &syncLatest{refresher: refresher, watermarks: watermarks, cfg: cfg, now: time.Now}
+}
+
+// Execute refreshes, for every symbol, the range from its watermark (or, on the first
+// sync, from Lookback candles back) to the last boundary of tf at or before now, and
+// advances the watermark to the end of the last candle loaded, publishing the new candles
+// if configured. A candle the provider has not returned yet is refreshed again next time.
+// Per-symbol failures are recorded on the corresponding result and do not fail the call.
+// An error is returned only when ctx ends before every symbol has been synced.
+func (s *syncLatest) Execute(ctx context.Context, tf domain.Timeframe, now time.Time) ([]SyncResult, error) {
+	step := tf.Duration()
+	closed := now.UTC().Truncate(step)
+
+	results := make([]SyncResult, len(s.cfg.Universe))
+	forEachSymbol(ctx, s.cfg.Universe, s.cfg.Concurrency, func(i int, sym domain.Symbol) {
+		res := SyncResult{Symbol: sym, Timeframe: tf}
+		key := syncKey(sym, tf)
+		from, ok, err := s.watermarks.Load(ctx, key)
+		if err != nil {
+			res.Err = err
+			results[i] = res
+			return
+		}
+		if !ok {
+			from = closed.Add(-time.Duration(s.cfg.Lookback) * step)
+		}
+		res.Watermark = from
+		if from.Before(closed) {
+			res.Watermark, res.Err = s.sync(ctx, key, sym, tf, from, closed, ok)
+		}
+		results[i] = res
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// sync refreshes [from, to) of one series and advances its watermark to the end of the
+// last candle loaded, returning the watermark.
+func (s *syncLatest) sync(ctx context.Context, key string, sym domain.Symbol, tf domain.Timeframe, from, to time.Time, resumed bool) (time.Time, error) {
+	if err := s.refresher.Refresh(ctx, sym, tf, from, to); err != nil {
+		return from, err
+	}
+	series, err := s.refresher.GetSeries(ctx, sym, tf, from, to)
+	if err != nil {
+		return from, err
+	}
+	series = series.Between(from, to)
+	last, err := series.Last()
+	if err != nil {
+		// Nothing has been published yet.
+		return from, nil
+	}
+	through := last.Timestamp().Add(tf.Duration())
+	if err := s.watermarks.Save(ctx, key, through); err != nil {
+		return from, err
+	}
+	s.publish(series, resumed)
+	return through, nil
+}
+
+// Run implements SyncLatest. Results are recorded in the watermarks only; a series that
+// fails is retried at its timeframe's next boundary, from its unchanged watermark.
+func (s *syncLatest) Run(ctx context.Context) {
+	synced := make(map[domain.Timeframe]time.Time, len(s.cfg.Timeframes))
+	for _, tf := range s.cfg.Timeframes {
+		now := s.now()
+		_, _ = s.Execute(ctx, tf, now)
+		synced[tf] = now.Truncate(tf.Duration())
+	}
+
+	for ctx.Err() == nil && len(synced) > 0 {
+		// Sleep until the earliest next boundary, plus the delay.
+		var due time.Time
+		for tf, boundary := range synced {
+			if at := boundary.Add(tf.Duration() + s.cfg.Delay); due.IsZero() || at.Before(due) {
+				due = at
+			}
+		}
+		timer := time.NewTimer(due.Sub(s.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		for _, tf := range s.cfg.Timeframes {
+			now := s.now()
+			if boundary := now.Add(-s.cfg.Delay).Truncate(tf.Duration()); boundary.After(synced[tf]) {
+				_, _ = s.Execute(ctx, tf, now)
+				synced[tf] = boundary
+			}
+		}
+	}
+}
+
+// publish passes the newly synced candles to the publisher. The first sync of a series
+// loads history nobody is waiting for, so only its last candle is published.
+func (s *syncLatest) publish(series domain.CandleSeries, resumed bool) {
+	if s.cfg.Publisher == nil {
+		return
+	}
+	candles := series.All()
+	if !resumed {
+		candles = candles[len(candles)-1:]
+	}
+	for _, c := range candles {
+		s.cfg.Publisher.Publish(ports.CandleUpdate{Candle: c, Closed: true})
+	}
+}
+
+// syncKey identifies the watermark of one series.
+func syncKey(sym domain.Symbol, tf domain.Timeframe) string {
+	return fmt.Sprintf("sync|%s|%s", sym, tf)
+}
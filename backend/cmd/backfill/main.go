@@ -0,0 +1,170 @@
+// Command backfill loads candle history from the provider into a durable store.
+//
+// It walks every requested symbol and timeframe in provider-sized chunks, within the
+// configured quota, and checkpoints after each chunk; rerunning the same command after
+// an interruption resumes where it stopped. For example:
+//
+//	backfill -api-base-url https://api.example.com/candles -symbols BTC,ETH \
+//	    -timeframes 1h,1d -from 2022-01-01 -to 2026-01-01 -per-minute 30
+//
+// History is written to Postgres when -database-url (default $PC_DATABASE_URL) is set,
+// and to the file store in -store-dir otherwise.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/adapters/infra"
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/application/usecases"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "backfill:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var (
+		baseURL     = flag.String("api-base-url", "", "provider endpoint (required)")
+		symbols     = flag.String("symbols", "", "comma-separated symbols (required)")
+		timeframes  = flag.String("timeframes", "1h,1d", "comma-separated timeframes")
+		from        = flag.String("from", "", "range start, RFC 3339 or YYYY-MM-DD (required)")
+		to          = flag.String("to", "", "range end, RFC 3339 or YYYY-MM-DD; default now")
+		maxRows     = flag.Int("max-rows", usecases.DefaultBackfillChunkCandles, "provider's per-request row limit")
+		perMinute   = flag.Int("per-minute", 0, "provider calls allowed per minute; 0 is unlimited")
+		perDay      = flag.Int("per-day", 0, "provider calls allowed per day; 0 is unlimited")
+		databaseURL = flag.String("database-url", os.Getenv("PC_DATABASE_URL"), "Postgres store")
+		storeDir    = flag.String("store-dir", "data/candles", "file store, used without -database-url")
+		checkpoints = flag.String("checkpoints", "", "checkpoint file; default backfill-checkpoints.json in -store-dir")
+	)
+	flag.Parse()
+
+	req, err := parseRequest(*symbols, *timeframes, *from, *to)
+	if err != nil {
+		return err
+	}
+	if *baseURL == "" {
+		return errors.New("-api-base-url is required")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var repo ports.CandleRepositoryPort = infra.NewFreeTierCandleRepository(*baseURL, http.DefaultClient)
+	// Wait for budget rather than fail: a backfill has no caller waiting on it.
+	if *perMinute > 0 || *perDay > 0 {
+		repo = infra.NewRateLimitedCandleRepository(repo, infra.RateLimit{PerMinute: *perMinute, PerDay: *perDay, MaxWait: 24 * time.Hour})
+	}
+	repo = infra.NewResilientCandleRepository(repo, infra.ResiliencePolicy{})
+	repo = infra.NewWindowedCandleRepository(repo, *maxRows)
+
+	var store ports.CandleStorePort
+	if *databaseURL != "" {
+		pg, err := infra.OpenPostgresCandleStore(ctx, *databaseURL)
+		if err != nil {
+			return err
+		}
+		defer pg.Close()
+		if err := pg.Migrate(ctx); err != nil {
+			return err
+		}
+		store = pg
+	} else {
+		fileStore, err := infra.NewFileCandleStore(*storeDir)
+		if err != nil {
+			return err
+		}
+		store = fileStore
+	}
+
+	if *checkpoints == "" {
+		*checkpoints = filepath.Join(*storeDir, "backfill-checkpoints.json")
+	}
+	cp, err := infra.NewFileCheckpointStore(*checkpoints)
+	if err != nil {
+		return err
+	}
+
+	results, err := usecases.NewBackfill(repo, store, cp, *maxRows).Execute(ctx, req)
+	failed := 0
+	for _, res := range results {
+		status := "ok"
+		if res.Err != nil {
+			status, failed = res.Err.Error(), failed+1
+		}
+		fmt.Printf("%s %s: %d candles, %d missing, through %s: %s\n", res.Symbol, res.Timeframe, res.Candles, res.Missing, res.Through.Format(time.RFC3339), status)
+	}
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d series incomplete; rerun to resume", failed)
+	}
+	return nil
+}
+
+// parseRequest builds the backfill request from the command-line values.
+func parseRequest(symbols, timeframes, from, to string) (usecases.BackfillRequest, error) {
+	var req usecases.BackfillRequest
+	for _, s := range splitList(symbols) {
+		sym, err := domain.NewSymbol(s)
+		if err != nil {
+			return req, err
+		}
+		req.Symbols = append(req.Symbols, sym)
+	}
+	for _, s := range splitList(timeframes) {
+		tf, err := domain.NewTimeframe(s)
+		if err != nil {
+			return req, err
+		}
+		req.Timeframes = append(req.Timeframes, tf)
+	}
+
+	if from == "" {
+		return req, errors.New("-from is required")
+	}
+	var err error
+	if req.From, err = parseTime(from); err != nil {
+		return req, fmt.Errorf("-from: %w", err)
+	}
+	req.To = time.Now().UTC()
+	if to != "" {
+		if req.To, err = parseTime(to); err != nil {
+			return req, fmt.Errorf("-to: %w", err)
+		}
+	}
+	return req, nil
+}
+
+// splitList splits a comma-separated list, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseTime reads an RFC 3339 time or a YYYY-MM-DD date, taken as UTC midnight.
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC(), nil
+	}
+	return time.Parse(time.DateOnly, s)
+}
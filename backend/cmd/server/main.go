@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"time"
@@ -9,6 +10,7 @@ import (
 	"github.com/akarso/pano_chart/backend/adapters/infra"
 	"github.com/akarso/pano_chart/backend/application/ports"
 	"github.com/akarso/pano_chart/backend/application/usecases"
+	"github.com/akarso/pano_chart/backend/domain"
 )
 
 // Config holds composition inputs. Fields are minimal and injectable for tests.
@@ -17,9 +19,85 @@ type Config struct {
 	APIBaseURL string
 	// Optional: if Repo is set, it will be used as the underlying repository instead of creating a FreeTier one.
 	Repo ports.CandleRepositoryPort
+	// ProviderMaxRows is the primary provider's per-response row cap; longer ranges are
+	// fetched in windows, each one rate-limited and retried on its own. 0 sends every
+	// range as one request.
+	ProviderMaxRows int
+	// ProviderConcurrency bounds the windows of one range fetched in parallel; 0 fetches
+	// them one at a time.
+	ProviderConcurrency int
+	// ProviderIngestPolicy decides whether invalid free-tier candles fail the series (the
+	// default), are skipped or are repaired.
+	ProviderIngestPolicy infra.IngestPolicy
 	// Optional Redis client; if nil, no caching decorator is used.
 	RedisClient infra.MinimalRedisClient
-	CacheTTL   time.Duration
+	// CacheTTL applies to the still-forming cache segment.
+	CacheTTL time.Duration
+	// ClosedCacheTTL applies to cache segments that can no longer change; 0 uses the default.
+	ClosedCacheTTL time.Duration
+	// CacheStaleWhileRevalidate is how long past its TTL a cache segment is served while
+	// being refreshed in the background; 0 disables it.
+	CacheStaleWhileRevalidate time.Duration
+	// CacheStaleIfError is how long past its TTL a cache segment is served when the
+	// provider is unavailable or rate-limiting; 0 disables it.
+	CacheStaleIfError time.Duration
+	// CacheTimeout bounds each Redis operation; a slower cache is treated as a miss.
+	CacheTimeout time.Duration
+	// RequestTimeout bounds the handling of every API request, including upstream calls.
+	RequestTimeout time.Duration
+	// MemoryCacheCandles bounds the in-process cache in front of Redis by candle count;
+	// 0 disables it.
+	MemoryCacheCandles int
+	// MemoryCacheTTL applies to in-process cache entries; 0 uses CacheTTL.
+	MemoryCacheTTL time.Duration
+	// ProviderRateLimit keeps calls to the provider within its quota; the zero value is unlimited.
+	ProviderRateLimit infra.RateLimit
+	// Optional provider resilience policy; if nil, transient failures are not retried and
+	// no circuit breaker is used.
+	ProviderResilience *infra.ResiliencePolicy
+	// FallbackProviders are consulted in order when the primary repository fails or has
+	// no data; see infra.FailoverCandleRepository.
+	FallbackProviders []infra.NamedRepository
+	// MergeProviders fills gaps in a provider's series from the providers after it.
+	MergeProviders bool
+	// Optional durable candle store consulted before the provider, such as an
+	// infra.PostgresCandleStore; closed candles accumulate there and survive restarts.
+	CandleStore ports.CandleStorePort
+	// CandleStoreDir, if set and CandleStore is nil, is the directory of a file-backed
+	// CandleStore.
+	CandleStoreDir string
+	// CoalesceRequests collapses concurrent identical repository calls, both in front of the
+	// provider (so a cache miss triggers one upstream call) and in front of the cache.
+	CoalesceRequests bool
+	// Optional base timeframe; if set, coarser timeframes are resampled locally from it
+	// instead of being requested from the provider.
+	ResampleBase domain.Timeframe
+	// OverviewConcurrency bounds parallel repository calls per overview, volatility or screener request;
+	// 0 uses the default.
+	OverviewConcurrency int
+	// ScreenerUniverse is scanned by /api/v1/screener when a request names no symbols.
+	ScreenerUniverse []domain.Symbol
+	// SyncUniverse, if non-empty, is kept fresh in the Redis cache (or, without one, the
+	// candle store) by a background scheduler that loads newly closed candles of every
+	// SyncTimeframes entry shortly after each bucket boundary.
+	SyncUniverse   []domain.Symbol
+	SyncTimeframes []domain.Timeframe
+	// SyncDelay is the wait after each boundary; 0 uses the default.
+	SyncDelay time.Duration
+	// SyncWatermarks records per-series sync progress; if nil, progress is kept in memory.
+	SyncWatermarks ports.CheckpointPort
+	// Stream, if set, serves /api/v1/stream, such as an infra.CandleHub. Candles loaded by
+	// the sync scheduler are published into it, and the forming candles of subscribed
+	// series are polled from the provider every StreamPollInterval (0 uses the default).
+	Stream             ports.CandleStreamPort
+	StreamPollInterval time.Duration
+	// StreamRateLimit is the share of the provider quota that polling may use, on top of
+	// ProviderRateLimit; the zero value uses a quarter of ProviderRateLimit. Polls over
+	// budget are skipped rather than queued, so they never starve API requests.
+	StreamRateLimit infra.RateLimit
+	// Background bounds the background jobs started for SyncUniverse and Stream; it is
+	// required when either is set, and they stop once it is done.
+	Background context.Context
 }
 
 // NewApp wires the application components and returns an http.Handler that can be used by a server.
@@ -32,8 +110,30 @@ func NewApp(cfg Config) (http.Handler, error) {
 	if cfg.CacheTTL == 0 {
 		cfg.CacheTTL = 5 * time.Minute
 	}
+	if cfg.CacheTimeout == 0 {
+		cfg.CacheTimeout = 200 * time.Millisecond
+	}
+	if cfg.MemoryCacheTTL == 0 {
+		cfg.MemoryCacheTTL = cfg.CacheTTL
+	}
+	if cfg.Background == nil && (len(cfg.SyncUniverse) > 0 || cfg.Stream != nil) {
+		return nil, fmt.Errorf("background jobs require a Background context")
+	}
+	if cfg.RequestTimeout == 0 {
+		// Below StartServer's WriteTimeout so the timeout error can still be written.
+		cfg.RequestTimeout = 8 * time.Second
+	}
+
+	// The sync scheduler waits out the provider's publication lag after each boundary;
+	// until then, the newest candle is not treated as final.
+	publicationLag := cfg.SyncDelay
+	if publicationLag <= 0 {
+		publicationLag = usecases.DefaultSyncDelay
+	}
 
 	var repo ports.CandleRepositoryPort
+	// refresher is the outermost cache or store the sync scheduler loads candles into.
+	var refresher ports.CandleRefresherPort
 	if cfg.Repo != nil {
 		repo = cfg.Repo
 	} else {
@@ -41,24 +141,148 @@ func NewApp(cfg Config) (http.Handler, error) {
 			return nil, fmt.Errorf("API base URL required when no Repo provided")
 		}
 		// create free-tier repository using default http client
-		repo = infra.NewFreeTierCandleRepository(cfg.APIBaseURL, http.DefaultClient)
+		repo = infra.NewFreeTierCandleRepository(cfg.APIBaseURL, http.DefaultClient).
+			WithIngestPolicy(cfg.ProviderIngestPolicy)
+	}
+
+	// Optionally keep provider calls within the request budget
+	if cfg.ProviderRateLimit.PerMinute > 0 || cfg.ProviderRateLimit.PerDay > 0 {
+		repo = infra.NewRateLimitedCandleRepository(repo, cfg.ProviderRateLimit)
+	}
+
+	// Optionally retry transient provider failures behind a circuit breaker
+	if cfg.ProviderResilience != nil {
+		repo = infra.NewResilientCandleRepository(repo, *cfg.ProviderResilience)
+	}
+
+	// Optionally split long ranges into provider-sized windows, each within the budget
+	if cfg.ProviderMaxRows > 0 {
+		repo = infra.NewWindowedCandleRepository(repo, cfg.ProviderMaxRows).WithConcurrency(cfg.ProviderConcurrency)
+	}
+
+	// Optionally fail over to (and fill gaps from) further providers
+	if len(cfg.FallbackProviders) > 0 {
+		providers := append([]infra.NamedRepository{{Name: "primary", Repo: repo}}, cfg.FallbackProviders...)
+		repo = infra.NewFailoverCandleRepository(providers...).WithMerge(cfg.MergeProviders)
+	}
+
+	// Forming candles are polled past the store and caches below.
+	upstream := repo
+
+	// Optionally accumulate closed candles in a durable store
+	if cfg.CandleStore == nil && cfg.CandleStoreDir != "" {
+		store, err := infra.NewFileCandleStore(cfg.CandleStoreDir)
+		if err != nil {
+			return nil, err
+		}
+		cfg.CandleStore = store
+	}
+	if cfg.CandleStore != nil {
+		stored := infra.NewStoredCandleRepository(cfg.CandleStore, repo).WithPublicationLag(publicationLag)
+		refresher, repo = stored, stored
+	}
+
+	// Optionally collapse identical concurrent upstream calls
+	if cfg.CoalesceRequests {
+		repo = infra.NewCoalescingCandleRepository(repo)
 	}
 
 	// Optionally wrap with Redis decorator
 	if cfg.RedisClient != nil {
-		repo = infra.NewRedisCandleRepository(cfg.RedisClient, repo, cfg.CacheTTL)
+		cached := infra.NewRedisCandleRepository(cfg.RedisClient, repo, cfg.CacheTTL).
+			WithClosedTTL(cfg.ClosedCacheTTL).
+			WithStaleWhileRevalidate(cfg.CacheStaleWhileRevalidate).
+			WithStaleIfError(cfg.CacheStaleIfError).
+			WithPublicationLag(publicationLag).
+			WithOperationTimeout(cfg.CacheTimeout)
+		refresher, repo = cached, cached
+	}
+
+	// Optionally derive coarser timeframes from the (cached) base series
+	if cfg.ResampleBase != "" {
+		policy := domain.ResamplePolicy{Edges: domain.KeepPartial, Gaps: domain.KeepPartial}
+		repo = infra.NewResamplingCandleRepository(repo, cfg.ResampleBase, policy)
+	}
+
+	// Optionally collapse identical concurrent requests before they reach the cache
+	if cfg.CoalesceRequests && (cfg.RedisClient != nil || cfg.ResampleBase != "") {
+		repo = infra.NewCoalescingCandleRepository(repo)
 	}
 
-	// Create use case
+	// Optionally serve hot requests from decoded series held in process
+	if cfg.MemoryCacheCandles > 0 {
+		repo = infra.NewMemoryCandleRepository(repo, cfg.MemoryCacheCandles, cfg.MemoryCacheTTL)
+	}
+
+	// Create use cases
 	uc := usecases.NewGetCandleSeries(repo)
+	overview := usecases.NewGetOverview(repo, cfg.OverviewConcurrency)
+	indicatorsUC := usecases.NewGetIndicators(repo)
+	volatility := usecases.NewGetVolatility(repo, cfg.OverviewConcurrency)
+	screener := usecases.NewScreener(repo, cfg.OverviewConcurrency)
 
-	// Create HTTP handler
+	// Create HTTP handlers
 	h := adhttp.NewGetCandleSeriesHandler(uc)
+	oh := adhttp.NewGetOverviewHandler(overview)
+	ih := adhttp.NewGetIndicatorsHandler(indicatorsUC)
+	vh := adhttp.NewGetVolatilityHandler(volatility)
+	sh := adhttp.NewScreenerHandler(screener, cfg.ScreenerUniverse)
 
 	mux := http.NewServeMux()
 	mux.Handle("/api/v1/candles", h)
+	mux.Handle("/api/v1/overview", oh)
+	mux.Handle("/api/v1/indicators", ih)
+	mux.Handle("/api/v1/volatility", vh)
+	mux.Handle("/api/v1/screener", sh)
+
+	// Optionally keep the watch universe fresh in the background
+	if len(cfg.SyncUniverse) > 0 {
+		if refresher == nil {
+			return nil, fmt.Errorf("sync requires a Redis cache or a candle store")
+		}
+		if cfg.SyncWatermarks == nil {
+			cfg.SyncWatermarks = infra.NewMemoryCheckpointStore()
+		}
+		syncLatest := usecases.NewSyncLatest(refresher, cfg.SyncWatermarks, usecases.SyncConfig{
+			Universe:    cfg.SyncUniverse,
+			Timeframes:  cfg.SyncTimeframes,
+			Delay:       cfg.SyncDelay,
+			Concurrency: cfg.OverviewConcurrency,
+			Publisher:   cfg.Stream,
+		})
+		go syncLatest.Run(cfg.Background)
+	}
+
+	handler := adhttp.WithRequestTimeout(adhttp.WithSourcesHeader(adhttp.WithQualityHeader(adhttp.WithStaleWarning(mux))), cfg.RequestTimeout)
+
+	// Optionally stream live candles; streams outlive the request timeout
+	if cfg.Stream != nil {
+		budget := cfg.StreamRateLimit
+		if budget.PerMinute <= 0 && budget.PerDay <= 0 {
+			budget = infra.RateLimit{PerMinute: quarter(cfg.ProviderRateLimit.PerMinute), PerDay: quarter(cfg.ProviderRateLimit.PerDay)}
+		}
+		polled := upstream
+		if budget.PerMinute > 0 || budget.PerDay > 0 {
+			polled = infra.NewRateLimitedCandleRepository(upstream, budget)
+		}
+		poll := usecases.NewPollForming(polled, cfg.Stream, cfg.StreamPollInterval, cfg.OverviewConcurrency)
+		go poll.Run(cfg.Background)
 
-	return mux, nil
+		root := http.NewServeMux()
+		root.Handle("/api/v1/stream", adhttp.NewStreamCandlesHandler(cfg.Stream))
+		root.Handle("/", handler)
+		handler = root
+	}
+
+	return handler, nil
+}
+
+// quarter returns a quarter of quota, but at least 1 for a positive quota.
+func quarter(quota int) int {
+	if quota <= 0 {
+		return 0
+	}
+	return (quota + 3) / 4
 }
 
 // StartServer is a convenience to start the HTTP server using the provided handler and address.
@@ -0,0 +1,188 @@
+// Package analysis derives summary metrics from a domain.CandleSeries,
+// such as how range-bound (sideways) a market currently is.
+package analysis
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/akarso/pano_chart/backend/domain"
+	"github.com/akarso/pano_chart/backend/domain/indicators"
+)
+
+// ErrInsufficientData is returned (wrapped) when a series is too short for an analysis.
+var ErrInsufficientData = errors.New("insufficient data")
+
+// RangeConfig tunes AnalyzeRange.
+type RangeConfig struct {
+	// Lookback is the number of most recent candles analysed; 0 uses the whole series.
+	Lookback int
+	// ATRPeriod and ADXPeriod are the indicator periods used for channel width and trend strength.
+	ATRPeriod int
+	ADXPeriod int
+	// BoundaryQuantile trims wicks when detecting boundaries: the upper boundary is the
+	// (1-q) quantile of highs and the lower boundary the q quantile of lows.
+	BoundaryQuantile float64
+}
+
+// DefaultRangeConfig returns the configuration used by the overview.
+func DefaultRangeConfig() RangeConfig {
+	return RangeConfig{ATRPeriod: 14, ADXPeriod: 14, BoundaryQuantile: 0.05}
+}
+
+// Score thresholds. A channel no wider than narrowChannelATR ATRs scores fully sideways
+// and one wider than wideChannelATR not at all; ADX is mapped similarly.
+const (
+	narrowChannelATR = 4.0
+	wideChannelATR   = 12.0
+	rangingADX       = 20.0
+	trendingADX      = 40.0
+	// maxSlopeT caps the slope t-statistic so a perfectly linear series stays finite.
+	maxSlopeT = 1000.0
+)
+
+// RangeAnalysis describes how range-bound a series is.
+type RangeAnalysis struct {
+	// Score is in [0, 1]; higher means more sideways. It is the mean of the three
+	// component scores below.
+	Score float64
+	// Upper and Lower are the detected range boundaries.
+	Upper float64
+	Lower float64
+	// WidthATR is the channel width (Upper-Lower) expressed in ATRs.
+	WidthATR float64
+	// ADX is the latest Average Directional Index (trend strength, 0..100).
+	ADX float64
+	// SlopeT is the t-statistic of the least-squares slope of closes, capped at ±1000;
+	// |SlopeT| < 2 means no statistically significant drift.
+	SlopeT float64
+	// SlopeR2 is the fraction of close variance explained by a linear trend.
+	SlopeR2 float64
+	// Candles is the number of candles analysed.
+	Candles int
+}
+
+// AnalyzeRange scores how range-bound the most recent candles of cs are by combining
+// channel width versus ATR, ADX trend strength and the significance of a linear trend.
+// Gaps are ignored: the analysed candles are treated as contiguous.
+func AnalyzeRange(cs domain.CandleSeries, cfg RangeConfig) (RangeAnalysis, error) {
+	if cfg.ATRPeriod < 1 || cfg.ADXPeriod < 1 || cfg.BoundaryQuantile < 0 || cfg.BoundaryQuantile >= 0.5 {
+		return RangeAnalysis{}, fmt.Errorf("%w: invalid range config %+v", indicators.ErrInvalidParameter, cfg)
+	}
+	candles := cs.All()
+	if cfg.Lookback > 0 && len(candles) > cfg.Lookback {
+		candles = candles[len(candles)-cfg.Lookback:]
+	}
+	need := 2 * cfg.ADXPeriod
+	if cfg.ATRPeriod > need {
+		need = cfg.ATRPeriod
+	}
+	if len(candles) < need || len(candles) < 3 {
+		return RangeAnalysis{}, fmt.Errorf("%w: range analysis needs %d candles, got %d", ErrInsufficientData, need, len(candles))
+	}
+	window, err := domain.NewCandleSeries(cs.Symbol(), cs.Timeframe(), candles)
+	if err != nil {
+		return RangeAnalysis{}, err
+	}
+
+	atr, err := lastValid(indicators.ATR(window, cfg.ATRPeriod, indicators.GapIgnore))
+	if err != nil {
+		return RangeAnalysis{}, err
+	}
+	adx, err := lastValid(indicators.ADX(window, cfg.ADXPeriod, indicators.GapIgnore))
+	if err != nil {
+		return RangeAnalysis{}, err
+	}
+
+	highs := make([]float64, len(candles))
+	lows := make([]float64, len(candles))
+	closes := make([]float64, len(candles))
+	for i, c := range candles {
+		highs[i], lows[i], closes[i] = c.High(), c.Low(), c.Close()
+	}
+	upper := quantile(highs, 1-cfg.BoundaryQuantile)
+	lower := quantile(lows, cfg.BoundaryQuantile)
+	slopeT, r2 := linearTrend(closes)
+
+	// ATR only reaches zero when every analysed candle is flat at the same price.
+	widthATR := 0.0
+	if atr > 0 {
+		widthATR = (upper - lower) / atr
+	}
+
+	widthScore := clamp01((wideChannelATR - widthATR) / (wideChannelATR - narrowChannelATR))
+	adxScore := clamp01((trendingADX - adx) / (trendingADX - rangingADX))
+	slopeScore := 1 - r2
+
+	return RangeAnalysis{
+		Score:    (widthScore + adxScore + slopeScore) / 3,
+		Upper:    upper,
+		Lower:    lower,
+		WidthATR: widthATR,
+		ADX:      adx,
+		SlopeT:   slopeT,
+		SlopeR2:  r2,
+		Candles:  len(candles),
+	}, nil
+}
+
+// lastValid returns the last valid value of an indicator series.
+func lastValid(s indicators.Series, err error) (float64, error) {
+	if err != nil {
+		return 0, err
+	}
+	for i := len(s.Points) - 1; i >= 0; i-- {
+		if s.Points[i].Valid {
+			return s.Points[i].Value, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %s has no valid value", ErrInsufficientData, s.Name)
+}
+
+// quantile returns the q-quantile of values using linear interpolation.
+func quantile(values []float64, q float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(pos-float64(lo))
+}
+
+// linearTrend fits y = a + b*x (x = 0..n-1) by least squares and returns the
+// t-statistic of b (capped at ±maxSlopeT) and the coefficient of determination R².
+// A perfectly flat series yields t = 0 and R² = 0.
+func linearTrend(y []float64) (t, r2 float64) {
+	n := float64(len(y))
+	var sx, sy float64
+	for i, v := range y {
+		sx += float64(i)
+		sy += v
+	}
+	mx, my := sx/n, sy/n
+	var sxx, sxy, syy float64
+	for i, v := range y {
+		dx, dy := float64(i)-mx, v-my
+		sxx += dx * dx
+		sxy += dx * dy
+		syy += dy * dy
+	}
+	if syy == 0 {
+		return 0, 0
+	}
+	b := sxy / sxx
+	r2 = (sxy * sxy) / (sxx * syy)
+	sse := syy - b*sxy
+	if sse <= 0 {
+		return math.Copysign(maxSlopeT, b), 1
+	}
+	se := math.Sqrt(sse / (n - 2) / sxx)
+	return math.Max(-maxSlopeT, math.Min(maxSlopeT, b/se)), r2
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
@@ -0,0 +1,24 @@
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/akarso/pano_chart/backend/domain"
+	"github.com/akarso/pano_chart/backend/domain/indicators"
+)
+
+// RealizedVolatility returns the annualized volatility of the whole series using est,
+// i.e. the last point of indicators.Volatility with a window spanning every candle.
+// Gaps are ignored: the candles are treated as contiguous.
+// Close-to-close needs at least 3 candles and the range-based estimators at least 2;
+// shorter series yield an error wrapping ErrInsufficientData.
+func RealizedVolatility(cs domain.CandleSeries, est indicators.Estimator) (float64, error) {
+	window := cs.Len()
+	if est == indicators.CloseToClose {
+		window--
+	}
+	if window < 2 {
+		return 0, fmt.Errorf("%w: %s volatility needs more candles, got %d", ErrInsufficientData, est, cs.Len())
+	}
+	return lastValid(indicators.Volatility(cs, est, window, indicators.GapIgnore))
+}
@@ -27,6 +27,7 @@ type alignRule struct {
 }
 
 // NewCandle constructs a Candle and enforces invariants.
+// Violations are reported as errors wrapping ErrInvalidCandle.
 func NewCandle(symbol Symbol, tf Timeframe, ts time.Time, open, high, low, close, volume float64) (Candle, error) {
 	// Validate basic invariants via helper functions to reduce cyclomatic complexity
 	if err := validateTimestampUTC(ts); err != nil {
@@ -57,7 +58,7 @@ func NewCandle(symbol Symbol, tf Timeframe, ts time.Time, open, high, low, close
 
 func validateTimestampUTC(ts time.Time) error {
 	if ts.Location() != time.UTC {
-		return fmt.Errorf("timestamp must be in UTC")
+		return fmt.Errorf("%w: timestamp must be in UTC", ErrInvalidCandle)
 	}
 	return nil
 }
@@ -65,7 +66,7 @@ func validateTimestampUTC(ts time.Time) error {
 func validateNonNegative(vals ...float64) error {
 	for _, v := range vals {
 		if v < 0 {
-			return fmt.Errorf("prices and volume must be non-negative")
+			return fmt.Errorf("%w: prices and volume must be non-negative", ErrInvalidCandle)
 		}
 	}
 	return nil
@@ -73,13 +74,13 @@ func validateNonNegative(vals ...float64) error {
 
 func validatePriceInvariants(open, high, low, close float64) error {
 	if high < open || high < close {
-		return fmt.Errorf("high must be >= max(open, close)")
+		return fmt.Errorf("%w: high must be >= max(open, close)", ErrInvalidCandle)
 	}
 	if low > open || low > close {
-		return fmt.Errorf("low must be <= min(open, close)")
+		return fmt.Errorf("%w: low must be <= min(open, close)", ErrInvalidCandle)
 	}
 	if high < low {
-		return fmt.Errorf("high must be >= low")
+		return fmt.Errorf("%w: high must be >= low", ErrInvalidCandle)
 	}
 	return nil
 }
@@ -95,7 +96,7 @@ func validateTemporalAlignment(tf Timeframe, ts time.Time) error {
 	}
 	rule, ok := rules[tf]
 	if !ok {
-		return fmt.Errorf("unsupported timeframe: %v", tf)
+		return fmt.Errorf("%w: unsupported timeframe: %v", ErrInvalidCandle, tf)
 	}
 	if err := checkSecond(rule, tf, ts); err != nil {
 		return err
@@ -111,27 +112,27 @@ func validateTemporalAlignment(tf Timeframe, ts time.Time) error {
 
 func checkSecond(rule alignRule, tf Timeframe, ts time.Time) error {
 	if rule.secondZero && ts.Second() != 0 {
-		return fmt.Errorf("%v timeframe requires second == 0", tf)
+		return fmt.Errorf("%w: %v timeframe requires second == 0", ErrInvalidCandle, tf)
 	}
 	return nil
 }
 
 func checkMinute(rule alignRule, tf Timeframe, ts time.Time) error {
 	if rule.minuteZero && ts.Minute() != 0 {
-		return fmt.Errorf("%v timeframe requires minute == 0", tf)
+		return fmt.Errorf("%w: %v timeframe requires minute == 0", ErrInvalidCandle, tf)
 	}
 	if rule.minuteMod != 0 && ts.Minute()%rule.minuteMod != 0 {
-		return fmt.Errorf("%v timeframe requires minute divisible by %d", tf, rule.minuteMod)
+		return fmt.Errorf("%w: %v timeframe requires minute divisible by %d", ErrInvalidCandle, tf, rule.minuteMod)
 	}
 	return nil
 }
 
 func checkHour(rule alignRule, tf Timeframe, ts time.Time) error {
 	if rule.hourZero && ts.Hour() != 0 {
-		return fmt.Errorf("%v timeframe requires hour == 0", tf)
+		return fmt.Errorf("%w: %v timeframe requires hour == 0", ErrInvalidCandle, tf)
 	}
 	if rule.hourMod != 0 && ts.Hour()%rule.hourMod != 0 {
-		return fmt.Errorf("%v timeframe requires hour divisible by %d", tf, rule.hourMod)
+		return fmt.Errorf("%w: %v timeframe requires hour divisible by %d", ErrInvalidCandle, tf, rule.hourMod)
 	}
 	return nil
 }
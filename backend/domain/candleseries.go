@@ -3,6 +3,7 @@ package domain
 import (
 	"fmt"
 	"sort"
+	"time"
 )
 
 // CandleSeries is an ordered, immutable collection of Candle value objects.
@@ -57,6 +58,16 @@ func NewCandleSeries(symbol Symbol, tf Timeframe, candles []Candle) (CandleSerie
 	}, nil
 }
 
+// Symbol returns the symbol the series is bound to.
+func (cs CandleSeries) Symbol() Symbol {
+	return cs.symbol
+}
+
+// Timeframe returns the timeframe the series is bound to.
+func (cs CandleSeries) Timeframe() Timeframe {
+	return cs.tf
+}
+
 // Len returns the number of candles in the series.
 func (cs CandleSeries) Len() int {
 	return len(cs.candles)
@@ -109,3 +120,20 @@ func (cs CandleSeries) HasGapAfter(index int) bool {
 	expectedNextTimestamp := current.Timestamp().Add(cs.tf.Duration())
 	return !next.Timestamp().Equal(expectedNextTimestamp)
 }
+
+// Between returns the sub-series of candles with from <= timestamp < to.
+// The receiver is not modified.
+func (cs CandleSeries) Between(from, to time.Time) CandleSeries {
+	lo := sort.Search(len(cs.candles), func(i int) bool {
+		return !cs.candles[i].Timestamp().Before(from)
+	})
+	hi := sort.Search(len(cs.candles), func(i int) bool {
+		return !cs.candles[i].Timestamp().Before(to)
+	})
+	if hi < lo {
+		hi = lo
+	}
+	sub := make([]Candle, hi-lo)
+	copy(sub, cs.candles[lo:hi])
+	return CandleSeries{symbol: cs.symbol, tf: cs.tf, candles: sub, isSorted: true}
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Sentinel errors returned (wrapped) by domain constructors.
+// Callers should match them with errors.Is.
+var (
+	// ErrInvalidSymbol is returned when a symbol violates the COMMON.md symbol rules.
+	ErrInvalidSymbol = errors.New("invalid symbol")
+	// ErrInvalidTimeframe is returned when a timeframe is empty or unsupported.
+	ErrInvalidTimeframe = errors.New("invalid timeframe")
+	// ErrInvalidCandle is returned when a candle violates its invariants.
+	ErrInvalidCandle = errors.New("invalid candle")
+	// ErrInvalidRange is returned when a time range is empty or inverted.
+	ErrInvalidRange = errors.New("invalid time range")
+)
+
+// ValidateRange checks that [from, to) is a non-empty, forward time range.
+func ValidateRange(from, to time.Time) error {
+	if from.IsZero() || to.IsZero() {
+		return fmt.Errorf("%w: from and to are required", ErrInvalidRange)
+	}
+	if !from.Before(to) {
+		return fmt.Errorf("%w: from must be before to", ErrInvalidRange)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// ADX computes Wilder's Average Directional Index, a 0..100 measure of trend strength
+// regardless of direction. Values below ~20 indicate a ranging market.
+// The first 2*period-1 points of each segment are invalid.
+func ADX(cs domain.CandleSeries, period int, gaps GapMode) (Series, error) {
+	if period < 1 {
+		return Series{}, fmt.Errorf("%w: ADX period must be >= 1, got %d", ErrInvalidParameter, period)
+	}
+	lines := apply(cs, gaps, []string{"adx"}, func(seg []domain.Candle, out [][]Point) {
+		copy(out[0], adxValues(seg, period))
+	})
+	return lines[0], nil
+}
+
+// adxValues computes ADX over a contiguous run of candles.
+func adxValues(seg []domain.Candle, period int) []Point {
+	out := make([]Point, len(seg))
+	if len(seg) < 2*period {
+		return out
+	}
+	tr := trueRanges(seg)
+	p := float64(period)
+
+	var sTR, sPlus, sMinus, adx float64
+	for i := 1; i < len(seg); i++ {
+		up := seg[i].High() - seg[i-1].High()
+		down := seg[i-1].Low() - seg[i].Low()
+		plusDM, minusDM := 0.0, 0.0
+		if up > down && up > 0 {
+			plusDM = up
+		}
+		if down > up && down > 0 {
+			minusDM = down
+		}
+
+		if i <= period {
+			sTR += tr[i]
+			sPlus += plusDM
+			sMinus += minusDM
+			if i < period {
+				continue
+			}
+		} else {
+			sTR = sTR - sTR/p + tr[i]
+			sPlus = sPlus - sPlus/p + plusDM
+			sMinus = sMinus - sMinus/p + minusDM
+		}
+
+		dx := directionalIndex(sPlus, sMinus, sTR)
+		switch {
+		case i < 2*period-1:
+			adx += dx
+		case i == 2*period-1:
+			adx = (adx + dx) / p
+			out[i] = Point{Value: adx, Valid: true}
+		default:
+			adx = (adx*(p-1) + dx) / p
+			out[i] = Point{Value: adx, Valid: true}
+		}
+	}
+	return out
+}
+
+// directionalIndex returns DX from smoothed directional movement and true range.
+func directionalIndex(sPlus, sMinus, sTR float64) float64 {
+	if sTR == 0 {
+		return 0
+	}
+	plusDI := 100 * sPlus / sTR
+	minusDI := 100 * sMinus / sTR
+	if plusDI+minusDI == 0 {
+		return 0
+	}
+	return 100 * math.Abs(plusDI-minusDI) / (plusDI + minusDI)
+}
@@ -0,0 +1,59 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// ATR computes Wilder's Average True Range. The true range of the first candle in a
+// segment is its high-low range; later candles also account for the previous close.
+// The first period-1 points of each segment are invalid.
+func ATR(cs domain.CandleSeries, period int, gaps GapMode) (Series, error) {
+	if period < 1 {
+		return Series{}, fmt.Errorf("%w: ATR period must be >= 1, got %d", ErrInvalidParameter, period)
+	}
+	lines := apply(cs, gaps, []string{"atr"}, func(seg []domain.Candle, out [][]Point) {
+		copy(out[0], atrValues(seg, period))
+	})
+	return lines[0], nil
+}
+
+// trueRanges returns the true range of each candle in seg.
+func trueRanges(seg []domain.Candle) []float64 {
+	tr := make([]float64, len(seg))
+	for i, c := range seg {
+		tr[i] = c.High() - c.Low()
+		if i > 0 {
+			prev := seg[i-1].Close()
+			tr[i] = math.Max(tr[i], math.Max(math.Abs(c.High()-prev), math.Abs(c.Low()-prev)))
+		}
+	}
+	return tr
+}
+
+// atrValues applies Wilder smoothing to the true ranges of seg.
+func atrValues(seg []domain.Candle, period int) []Point {
+	return wilderValues(trueRanges(seg), period)
+}
+
+// wilderValues seeds with the mean of the first period values and then applies
+// Wilder smoothing: avg = (avg*(period-1) + v) / period.
+func wilderValues(values []float64, period int) []Point {
+	out := make([]Point, len(values))
+	if len(values) < period {
+		return out
+	}
+	avg := 0.0
+	for _, v := range values[:period] {
+		avg += v
+	}
+	avg /= float64(period)
+	out[period-1] = Point{Value: avg, Valid: true}
+	for i := period; i < len(values); i++ {
+		avg = (avg*float64(period-1) + values[i]) / float64(period)
+		out[i] = Point{Value: avg, Valid: true}
+	}
+	return out
+}
@@ -0,0 +1,51 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// BollingerResult holds the three Bollinger Band output lines.
+type BollingerResult struct {
+	Middle Series
+	Upper  Series
+	Lower  Series
+}
+
+// Bollinger computes Bollinger Bands over closes: the SMA(period) middle band and
+// upper/lower bands k population standard deviations away.
+// The first period-1 points of each segment are invalid.
+func Bollinger(cs domain.CandleSeries, period int, k float64, gaps GapMode) (BollingerResult, error) {
+	if period < 1 {
+		return BollingerResult{}, fmt.Errorf("%w: Bollinger period must be >= 1, got %d", ErrInvalidParameter, period)
+	}
+	if k <= 0 || math.IsNaN(k) || math.IsInf(k, 0) {
+		return BollingerResult{}, fmt.Errorf("%w: Bollinger width must be > 0, got %v", ErrInvalidParameter, k)
+	}
+	lines := apply(cs, gaps, []string{"middle", "upper", "lower"}, func(seg []domain.Candle, out [][]Point) {
+		c := closes(seg)
+		mid := smaValues(c, period)
+		for i := range seg {
+			if !mid[i].Valid {
+				continue
+			}
+			sd := stddev(c[i-period+1:i+1], mid[i].Value)
+			out[0][i] = mid[i]
+			out[1][i] = Point{Value: mid[i].Value + k*sd, Valid: true}
+			out[2][i] = Point{Value: mid[i].Value - k*sd, Valid: true}
+		}
+	})
+	return BollingerResult{Middle: lines[0], Upper: lines[1], Lower: lines[2]}, nil
+}
+
+// stddev returns the population standard deviation of values around mean.
+func stddev(values []float64, mean float64) float64 {
+	ss := 0.0
+	for _, v := range values {
+		d := v - mean
+		ss += d * d
+	}
+	return math.Sqrt(ss / float64(len(values)))
+}
@@ -0,0 +1,102 @@
+// Package indicators computes technical indicators over a domain.CandleSeries.
+//
+// Every indicator returns output aligned 1:1 with the input candles: one Point per
+// candle, carrying the candle timestamp. Points that cannot be computed yet
+// (warm-up) are marked invalid rather than omitted, so consumers never have to
+// re-align outputs with candles. Non-finite values, which extreme prices can produce,
+// are marked invalid as well.
+package indicators
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// ErrInvalidParameter is returned (wrapped) when an indicator parameter is out of range.
+var ErrInvalidParameter = errors.New("invalid indicator parameter")
+
+// GapMode controls how gaps in the candle series (see CandleSeries.HasGapAfter) are treated.
+type GapMode int
+
+const (
+	// GapReset restarts every indicator after a gap, so values never blend
+	// candles separated by missing data. Warm-up applies again after each gap.
+	GapReset GapMode = iota
+	// GapIgnore treats the series as contiguous.
+	GapIgnore
+)
+
+// Point is a single indicator value aligned to a candle timestamp.
+type Point struct {
+	Timestamp time.Time
+	Value     float64
+	// Valid is false while the indicator is warming up or if Value is not finite.
+	Valid bool
+}
+
+// Series is a named indicator output line.
+type Series struct {
+	Name   string
+	Points []Point
+}
+
+// Since returns the points with timestamp >= from.
+func (s Series) Since(from time.Time) Series {
+	i := 0
+	for i < len(s.Points) && s.Points[i].Timestamp.Before(from) {
+		i++
+	}
+	pts := make([]Point, len(s.Points)-i)
+	copy(pts, s.Points[i:])
+	return Series{Name: s.Name, Points: pts}
+}
+
+// segmentFunc computes indicator lines for a contiguous run of candles.
+// out has one slice per line, each pre-sized to len(seg); fn fills Value and Valid.
+type segmentFunc func(seg []domain.Candle, out [][]Point)
+
+// apply runs fn over each contiguous segment of the series (or over the whole series
+// when gaps are ignored) and stitches the results back together with timestamps set and
+// non-finite values marked invalid.
+func apply(cs domain.CandleSeries, gaps GapMode, names []string, fn segmentFunc) []Series {
+	candles := cs.All()
+	lines := make([]Series, len(names))
+	for l, name := range names {
+		lines[l] = Series{Name: name, Points: make([]Point, 0, len(candles))}
+	}
+
+	start := 0
+	for i := range candles {
+		if i < len(candles)-1 && !(gaps == GapReset && cs.HasGapAfter(i)) {
+			continue
+		}
+		seg := candles[start : i+1]
+		out := make([][]Point, len(names))
+		for l := range out {
+			out[l] = make([]Point, len(seg))
+		}
+		fn(seg, out)
+		for l := range out {
+			for j := range out[l] {
+				out[l][j].Timestamp = seg[j].Timestamp()
+				if v := out[l][j].Value; math.IsNaN(v) || math.IsInf(v, 0) {
+					out[l][j] = Point{Timestamp: seg[j].Timestamp()}
+				}
+			}
+			lines[l].Points = append(lines[l].Points, out[l]...)
+		}
+		start = i + 1
+	}
+	return lines
+}
+
+func closes(seg []domain.Candle) []float64 {
+	v := make([]float64, len(seg))
+	for i, c := range seg {
+		v[i] = c.Close()
+	}
+	return v
+}
@@ -0,0 +1,48 @@
+package indicators
+
+import (
+	"fmt"
+
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// MACDResult holds the three MACD output lines.
+type MACDResult struct {
+	MACD      Series
+	Signal    Series
+	Histogram Series
+}
+
+// MACD computes the Moving Average Convergence Divergence of closes:
+// MACD = EMA(fast) - EMA(slow), Signal = EMA(signal) of MACD, Histogram = MACD - Signal.
+// MACD is invalid for the first slow-1 points of each segment; Signal and Histogram
+// for the first slow+signal-2 points.
+func MACD(cs domain.CandleSeries, fast, slow, signal int, gaps GapMode) (MACDResult, error) {
+	if fast < 1 || slow < 1 || signal < 1 {
+		return MACDResult{}, fmt.Errorf("%w: MACD periods must be >= 1, got %d/%d/%d", ErrInvalidParameter, fast, slow, signal)
+	}
+	if fast >= slow {
+		return MACDResult{}, fmt.Errorf("%w: MACD fast period must be < slow period, got %d/%d", ErrInvalidParameter, fast, slow)
+	}
+	lines := apply(cs, gaps, []string{"macd", "signal", "histogram"}, func(seg []domain.Candle, out [][]Point) {
+		c := closes(seg)
+		fastEMA := emaValues(c, 0, fast)
+		slowEMA := emaValues(c, 0, slow)
+
+		macd := make([]float64, len(seg))
+		for i := range seg {
+			if slowEMA[i].Valid {
+				macd[i] = fastEMA[i].Value - slowEMA[i].Value
+				out[0][i] = Point{Value: macd[i], Valid: true}
+			}
+		}
+		sig := emaValues(macd, slow-1, signal)
+		for i := range seg {
+			if sig[i].Valid {
+				out[1][i] = sig[i]
+				out[2][i] = Point{Value: macd[i] - sig[i].Value, Valid: true}
+			}
+		}
+	})
+	return MACDResult{MACD: lines[0], Signal: lines[1], Histogram: lines[2]}, nil
+}
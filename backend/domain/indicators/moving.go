@@ -0,0 +1,71 @@
+package indicators
+
+import (
+	"fmt"
+
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// SMA computes the simple moving average of closes over period candles.
+// The first period-1 points of each segment are invalid.
+func SMA(cs domain.CandleSeries, period int, gaps GapMode) (Series, error) {
+	if period < 1 {
+		return Series{}, fmt.Errorf("%w: SMA period must be >= 1, got %d", ErrInvalidParameter, period)
+	}
+	lines := apply(cs, gaps, []string{"sma"}, func(seg []domain.Candle, out [][]Point) {
+		copy(out[0], smaValues(closes(seg), period))
+	})
+	return lines[0], nil
+}
+
+// EMA computes the exponential moving average of closes with smoothing 2/(period+1).
+// It is seeded with the SMA of the first period closes, so the first period-1 points
+// of each segment are invalid.
+func EMA(cs domain.CandleSeries, period int, gaps GapMode) (Series, error) {
+	if period < 1 {
+		return Series{}, fmt.Errorf("%w: EMA period must be >= 1, got %d", ErrInvalidParameter, period)
+	}
+	lines := apply(cs, gaps, []string{"ema"}, func(seg []domain.Candle, out [][]Point) {
+		copy(out[0], emaValues(closes(seg), 0, period))
+	})
+	return lines[0], nil
+}
+
+// smaValues returns the rolling mean of values; points before index period-1 are invalid.
+func smaValues(values []float64, period int) []Point {
+	out := make([]Point, len(values))
+	sum := 0.0
+	for i, v := range values {
+		sum += v
+		if i >= period {
+			sum -= values[i-period]
+		}
+		if i >= period-1 {
+			out[i] = Point{Value: sum / float64(period), Valid: true}
+		}
+	}
+	return out
+}
+
+// emaValues computes an EMA over values[start:], seeded by the SMA of the first period
+// values from start. Points before start+period-1 are invalid.
+func emaValues(values []float64, start, period int) []Point {
+	out := make([]Point, len(values))
+	seedAt := start + period - 1
+	if seedAt >= len(values) {
+		return out
+	}
+	sum := 0.0
+	for _, v := range values[start : seedAt+1] {
+		sum += v
+	}
+	ema := sum / float64(period)
+	out[seedAt] = Point{Value: ema, Valid: true}
+
+	k := 2.0 / float64(period+1)
+	for i := seedAt + 1; i < len(values); i++ {
+		ema = values[i]*k + ema*(1-k)
+		out[i] = Point{Value: ema, Valid: true}
+	}
+	return out
+}
@@ -0,0 +1,59 @@
+package indicators
+
+import (
+	"fmt"
+
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// RSI computes Wilder's Relative Strength Index of closes.
+// The first period points of each segment are invalid, since period price changes
+// are needed to seed the average gain and loss.
+func RSI(cs domain.CandleSeries, period int, gaps GapMode) (Series, error) {
+	if period < 1 {
+		return Series{}, fmt.Errorf("%w: RSI period must be >= 1, got %d", ErrInvalidParameter, period)
+	}
+	lines := apply(cs, gaps, []string{"rsi"}, func(seg []domain.Candle, out [][]Point) {
+		if len(seg) <= period {
+			return
+		}
+		var avgGain, avgLoss float64
+		for i := 1; i <= period; i++ {
+			gain, loss := change(seg[i-1].Close(), seg[i].Close())
+			avgGain += gain
+			avgLoss += loss
+		}
+		avgGain /= float64(period)
+		avgLoss /= float64(period)
+		out[0][period] = Point{Value: rsiValue(avgGain, avgLoss), Valid: true}
+
+		for i := period + 1; i < len(seg); i++ {
+			gain, loss := change(seg[i-1].Close(), seg[i].Close())
+			avgGain = (avgGain*float64(period-1) + gain) / float64(period)
+			avgLoss = (avgLoss*float64(period-1) + loss) / float64(period)
+			out[0][i] = Point{Value: rsiValue(avgGain, avgLoss), Valid: true}
+		}
+	})
+	return lines[0], nil
+}
+
+// change splits a price move into its gain and loss components.
+func change(prev, cur float64) (gain, loss float64) {
+	d := cur - prev
+	if d > 0 {
+		return d, 0
+	}
+	return 0, -d
+}
+
+// rsiValue maps average gain/loss to the 0..100 RSI scale.
+// A flat market (no gains and no losses) is reported as the neutral 50.
+func rsiValue(avgGain, avgLoss float64) float64 {
+	if avgLoss == 0 {
+		if avgGain == 0 {
+			return 50
+		}
+		return 100
+	}
+	return 100 - 100/(1+avgGain/avgLoss)
+}
@@ -0,0 +1,175 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// Kind names a supported indicator.
+type Kind string
+
+// Supported indicator kinds.
+const (
+	KindSMA       Kind = "sma"
+	KindEMA       Kind = "ema"
+	KindRSI       Kind = "rsi"
+	KindMACD      Kind = "macd"
+	KindBollinger Kind = "bb"
+	KindATR       Kind = "atr"
+	KindADX       Kind = "adx"
+
+	// Rolling annualized volatility, one kind per Estimator.
+	KindVolCC        Kind = "vol_cc"
+	KindVolParkinson Kind = "vol_parkinson"
+	KindVolGK        Kind = "vol_gk"
+	KindVolRS        Kind = "vol_rs"
+)
+
+// volatilityKinds maps each volatility kind to its estimator.
+var volatilityKinds = map[Kind]Estimator{
+	KindVolCC:        CloseToClose,
+	KindVolParkinson: Parkinson,
+	KindVolGK:        GarmanKlass,
+	KindVolRS:        RogersSatchell,
+}
+
+// defaultParams lists the parameters used when a spec omits them.
+var defaultParams = map[Kind][]float64{
+	KindSMA:          {20},
+	KindEMA:          {20},
+	KindRSI:          {14},
+	KindMACD:         {12, 26, 9},
+	KindBollinger:    {20, 2},
+	KindATR:          {14},
+	KindADX:          {14},
+	KindVolCC:        {20},
+	KindVolParkinson: {20},
+	KindVolGK:        {20},
+	KindVolRS:        {20},
+}
+
+// Spec is a parsed indicator request such as "sma:20", "macd:12:26:9" or "bb:20:2".
+type Spec struct {
+	Kind   Kind
+	Params []float64
+}
+
+// ParseSpec parses "kind[:param...]". Omitted parameters take the conventional defaults
+// (sma/ema 20, rsi/atr/adx 14, macd 12/26/9, bb 20/2, vol_* 20).
+func ParseSpec(s string) (Spec, error) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(s)), ":")
+	kind := Kind(parts[0])
+	defaults, ok := defaultParams[kind]
+	if !ok {
+		return Spec{}, fmt.Errorf("%w: unknown indicator %q", ErrInvalidParameter, parts[0])
+	}
+	if len(parts)-1 > len(defaults) {
+		return Spec{}, fmt.Errorf("%w: %s takes at most %d parameters", ErrInvalidParameter, kind, len(defaults))
+	}
+
+	params := make([]float64, len(defaults))
+	copy(params, defaults)
+	for i, p := range parts[1:] {
+		v, err := strconv.ParseFloat(p, 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			return Spec{}, fmt.Errorf("%w: %s parameter %q is not a number", ErrInvalidParameter, kind, p)
+		}
+		params[i] = v
+	}
+
+	spec := Spec{Kind: kind, Params: params}
+	if err := spec.validate(); err != nil {
+		return Spec{}, err
+	}
+	return spec, nil
+}
+
+// MaxPeriod is the largest accepted period parameter. It bounds the warm-up history
+// loaded in front of a request.
+const MaxPeriod = 1000
+
+// MaxBollingerWidth is the largest accepted Bollinger width, in standard deviations.
+// Wider bands carry no information and, for huge widths, overflow to infinity.
+const MaxBollingerWidth = 10
+
+// validate checks that period parameters are integers in [1, MaxPeriod] and that the
+// Bollinger width is in (0, MaxBollingerWidth].
+func (s Spec) validate() error {
+	periods := s.Params
+	if s.Kind == KindBollinger {
+		periods = s.Params[:1]
+		if k := s.Params[1]; k <= 0 || k > MaxBollingerWidth {
+			return fmt.Errorf("%w: %s width must be greater than 0 and at most %d, got %v", ErrInvalidParameter, s.Kind, MaxBollingerWidth, k)
+		}
+	}
+	for _, p := range periods {
+		if p < 1 || p > MaxPeriod || p != math.Trunc(p) {
+			return fmt.Errorf("%w: %s period must be an integer from 1 to %d, got %v", ErrInvalidParameter, s.Kind, MaxPeriod, p)
+		}
+	}
+	return nil
+}
+
+// String returns the canonical "kind:param..." form.
+func (s Spec) String() string {
+	parts := []string{string(s.Kind)}
+	for _, p := range s.Params {
+		parts = append(parts, strconv.FormatFloat(p, 'f', -1, 64))
+	}
+	return strings.Join(parts, ":")
+}
+
+// WarmUp returns how many candles of a contiguous series precede the first point at
+// which every output line is valid.
+func (s Spec) WarmUp() int {
+	p := int(s.Params[0])
+	switch s.Kind {
+	case KindRSI:
+		return p
+	case KindMACD:
+		return int(s.Params[1]) + int(s.Params[2]) - 2
+	case KindADX:
+		return 2*p - 1
+	case KindVolCC:
+		return p
+	default:
+		return p - 1
+	}
+}
+
+// Compute evaluates the indicator over cs and returns its output lines.
+func (s Spec) Compute(cs domain.CandleSeries, gaps GapMode) ([]Series, error) {
+	p := int(s.Params[0])
+	switch s.Kind {
+	case KindSMA:
+		line, err := SMA(cs, p, gaps)
+		return []Series{line}, err
+	case KindEMA:
+		line, err := EMA(cs, p, gaps)
+		return []Series{line}, err
+	case KindRSI:
+		line, err := RSI(cs, p, gaps)
+		return []Series{line}, err
+	case KindATR:
+		line, err := ATR(cs, p, gaps)
+		return []Series{line}, err
+	case KindADX:
+		line, err := ADX(cs, p, gaps)
+		return []Series{line}, err
+	case KindVolCC, KindVolParkinson, KindVolGK, KindVolRS:
+		line, err := Volatility(cs, volatilityKinds[s.Kind], p, gaps)
+		return []Series{line}, err
+	case KindMACD:
+		r, err := MACD(cs, p, int(s.Params[1]), int(s.Params[2]), gaps)
+		return []Series{r.MACD, r.Signal, r.Histogram}, err
+	case KindBollinger:
+		r, err := Bollinger(cs, p, s.Params[1], gaps)
+		return []Series{r.Middle, r.Upper, r.Lower}, err
+	default:
+		return nil, fmt.Errorf("%w: unknown indicator %q", ErrInvalidParameter, s.Kind)
+	}
+}
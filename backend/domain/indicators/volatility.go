@@ -0,0 +1,138 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// Estimator names a historical volatility estimator.
+type Estimator string
+
+// Supported volatility estimators.
+const (
+	// CloseToClose is the sample standard deviation of log close-to-close returns.
+	CloseToClose Estimator = "cc"
+	// Parkinson uses the high-low range of each candle.
+	Parkinson Estimator = "parkinson"
+	// GarmanKlass uses open, high, low and close, assuming no drift and no opening jumps.
+	GarmanKlass Estimator = "gk"
+	// RogersSatchell uses open, high, low and close and is unbiased under drift.
+	RogersSatchell Estimator = "rs"
+)
+
+// Estimators lists every supported estimator in canonical order.
+var Estimators = []Estimator{CloseToClose, Parkinson, GarmanKlass, RogersSatchell}
+
+// ParseEstimator parses an estimator name case-insensitively.
+func ParseEstimator(s string) (Estimator, error) {
+	e := Estimator(strings.ToLower(strings.TrimSpace(s)))
+	for _, known := range Estimators {
+		if e == known {
+			return e, nil
+		}
+	}
+	return "", fmt.Errorf("%w: unknown volatility estimator %q", ErrInvalidParameter, s)
+}
+
+// tradingYear is the period over which volatility is annualized.
+// Crypto markets trade around the clock, so a year is 365 full days.
+const tradingYear = 365 * 24 * time.Hour
+
+// AnnualizationFactor returns sqrt(periods per year) for tf, the factor that converts a
+// per-candle standard deviation into an annualized one. It is 0 for an unknown timeframe.
+func AnnualizationFactor(tf domain.Timeframe) float64 {
+	d := tf.Duration()
+	if d <= 0 {
+		return 0
+	}
+	return math.Sqrt(float64(tradingYear) / float64(d))
+}
+
+// Volatility computes a rolling annualized volatility over window candles using est.
+// Close-to-close uses window returns and so needs window+1 candles: its first window
+// points of each segment are invalid. The range-based estimators use window candles and
+// their first window-1 points are invalid. Windows containing a non-positive price are
+// invalid, since log prices are undefined there.
+func Volatility(cs domain.CandleSeries, est Estimator, window int, gaps GapMode) (Series, error) {
+	if _, err := ParseEstimator(string(est)); err != nil {
+		return Series{}, err
+	}
+	if window < 2 {
+		return Series{}, fmt.Errorf("%w: volatility window must be >= 2, got %d", ErrInvalidParameter, window)
+	}
+	annualize := AnnualizationFactor(cs.Timeframe())
+	if annualize == 0 {
+		return Series{}, fmt.Errorf("%w: cannot annualize timeframe %q", domain.ErrInvalidTimeframe, cs.Timeframe())
+	}
+	lines := apply(cs, gaps, []string{"vol_" + string(est)}, func(seg []domain.Candle, out [][]Point) {
+		terms := volatilityTerms(seg, est)
+		first := window - 1
+		if est == CloseToClose {
+			first = window
+		}
+		for i := first; i < len(seg); i++ {
+			v := windowVariance(terms[i-window+1:i+1], est)
+			if math.IsNaN(v) {
+				continue
+			}
+			out[0][i] = Point{Value: math.Sqrt(math.Max(v, 0)) * annualize, Valid: true}
+		}
+	})
+	return lines[0], nil
+}
+
+// volatilityTerms returns the per-candle quantity each estimator averages. For
+// close-to-close it is the log return into the candle (NaN for the first candle);
+// for the range-based estimators it is the candle's variance contribution.
+// Terms involving a non-positive price are NaN.
+func volatilityTerms(seg []domain.Candle, est Estimator) []float64 {
+	terms := make([]float64, len(seg))
+	for i, c := range seg {
+		o, h, l, cl := c.Open(), c.High(), c.Low(), c.Close()
+		if o <= 0 || h <= 0 || l <= 0 || cl <= 0 {
+			terms[i] = math.NaN()
+			continue
+		}
+		switch est {
+		case CloseToClose:
+			prev := math.NaN()
+			if i > 0 && seg[i-1].Close() > 0 {
+				prev = seg[i-1].Close()
+			}
+			terms[i] = math.Log(cl / prev)
+		case Parkinson:
+			hl := math.Log(h / l)
+			terms[i] = hl * hl / (4 * math.Ln2)
+		case GarmanKlass:
+			hl, co := math.Log(h/l), math.Log(cl/o)
+			terms[i] = 0.5*hl*hl - (2*math.Ln2-1)*co*co
+		case RogersSatchell:
+			terms[i] = math.Log(h/cl)*math.Log(h/o) + math.Log(l/cl)*math.Log(l/o)
+		}
+	}
+	return terms
+}
+
+// windowVariance combines the terms of one window into a per-candle variance: the
+// sample variance of returns for close-to-close, the mean term otherwise.
+// It is NaN if any term is NaN.
+func windowVariance(terms []float64, est Estimator) float64 {
+	sum := 0.0
+	for _, t := range terms {
+		sum += t
+	}
+	mean := sum / float64(len(terms))
+	if est != CloseToClose {
+		return mean
+	}
+	ss := 0.0
+	for _, t := range terms {
+		d := t - mean
+		ss += d * d
+	}
+	return ss / float64(len(terms)-1)
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// BucketPolicy decides whether an incomplete aggregation bucket is emitted by Resample.
+type BucketPolicy int
+
+const (
+	// DropIncomplete discards buckets that are missing any constituent candle.
+	DropIncomplete BucketPolicy = iota
+	// KeepPartial emits buckets folded from whichever constituent candles are present.
+	KeepPartial
+)
+
+// ResamplePolicy configures how Resample treats buckets that are not fully covered.
+// The zero value is strict: every incomplete bucket is dropped.
+type ResamplePolicy struct {
+	// Edges applies to a bucket whose missing candles all lie before the first or after
+	// the last source candle, i.e. the series starts or ends inside the bucket
+	// (this includes the still-forming current bucket).
+	Edges BucketPolicy
+	// Gaps applies to a bucket missing candles within the span covered by the source series.
+	Gaps BucketPolicy
+}
+
+// Resample aggregates the series into the coarser target timeframe.
+//
+// Buckets start at timestamps satisfying the target's temporal alignment (the same rule
+// NewCandle enforces) and are folded as: open of the first candle, close of the last,
+// maximum high, minimum low and summed volume. Incomplete buckets are handled according
+// to policy. The target duration must be a whole multiple of the series timeframe;
+// otherwise an error wrapping ErrInvalidTimeframe is returned. Resampling to the same
+// timeframe returns an equivalent series.
+func (cs CandleSeries) Resample(target Timeframe, policy ResamplePolicy) (CandleSeries, error) {
+	src := cs.tf.Duration()
+	dst := target.Duration()
+	if src == 0 || dst == 0 || dst < src || dst%src != 0 {
+		return CandleSeries{}, fmt.Errorf("%w: cannot resample %v into %v", ErrInvalidTimeframe, cs.tf, target)
+	}
+	if len(cs.candles) == 0 {
+		return NewCandleSeries(cs.symbol, target, nil)
+	}
+
+	perBucket := int(dst / src)
+	spanStart := cs.candles[0].Timestamp()
+	spanEnd := cs.candles[len(cs.candles)-1].Timestamp().Add(src)
+
+	out := make([]Candle, 0, len(cs.candles)/perBucket+2)
+	for i := 0; i < len(cs.candles); {
+		start := cs.candles[i].Timestamp().Truncate(dst)
+		end := start.Add(dst)
+		j := i
+		for j < len(cs.candles) && cs.candles[j].Timestamp().Before(end) {
+			j++
+		}
+		bucket := cs.candles[i:j]
+		i = j
+
+		if len(bucket) < perBucket && !policy.keeps(bucket, start, end, spanStart, spanEnd, src) {
+			continue
+		}
+		c, err := foldBucket(cs.symbol, target, start, bucket)
+		if err != nil {
+			return CandleSeries{}, err
+		}
+		out = append(out, c)
+	}
+
+	return NewCandleSeries(cs.symbol, target, out)
+}
+
+// keeps reports whether an incomplete bucket [start, end) should be emitted.
+// Missing slots inside [spanStart, spanEnd) are gaps; all others are edge effects.
+func (p ResamplePolicy) keeps(bucket []Candle, start, end, spanStart, spanEnd time.Time, src time.Duration) bool {
+	lo, hi := start, end
+	if spanStart.After(lo) {
+		lo = spanStart
+	}
+	if spanEnd.Before(hi) {
+		hi = spanEnd
+	}
+	if len(bucket) < int(hi.Sub(lo)/src) {
+		return p.Gaps == KeepPartial
+	}
+	return p.Edges == KeepPartial
+}
+
+// foldBucket combines consecutive candles into a single candle at ts.
+func foldBucket(symbol Symbol, tf Timeframe, ts time.Time, bucket []Candle) (Candle, error) {
+	first := bucket[0]
+	high, low, volume := first.High(), first.Low(), 0.0
+	for _, c := range bucket {
+		if c.High() > high {
+			high = c.High()
+		}
+		if c.Low() < low {
+			low = c.Low()
+		}
+		volume += c.Volume()
+	}
+	return NewCandle(symbol, tf, ts, first.Open(), high, low, bucket[len(bucket)-1].Close(), volume)
+}
@@ -11,22 +11,22 @@ type Symbol string
 
 // NewSymbol creates a new Symbol from a string.
 // The symbol is normalized to uppercase.
-// Returns an error if the symbol is invalid.
+// Returns an error wrapping ErrInvalidSymbol if the symbol is invalid.
 func NewSymbol(s string) (Symbol, error) {
 	// Reject empty string
 	if s == "" {
-		return "", fmt.Errorf("symbol cannot be empty")
+		return "", fmt.Errorf("%w: cannot be empty", ErrInvalidSymbol)
 	}
 
 	// Reject whitespace-only string
 	if strings.TrimSpace(s) == "" {
-		return "", fmt.Errorf("symbol cannot be whitespace-only")
+		return "", fmt.Errorf("%w: cannot be whitespace-only", ErrInvalidSymbol)
 	}
 
 	// Validate characters: A-Z, 0-9, -, _
 	for _, ch := range s {
 		if !isValidSymbolChar(ch) {
-			return "", fmt.Errorf("symbol contains invalid character: %q", ch)
+			return "", fmt.Errorf("%w: contains invalid character %q", ErrInvalidSymbol, ch)
 		}
 	}
 
@@ -32,11 +32,11 @@ var validTimeframes = map[string]Timeframe{
 
 // NewTimeframe creates a new Timeframe from a string.
 // The timeframe is normalized to lowercase and validated against supported values.
-// Returns an error if the timeframe is invalid or unsupported.
+// Returns an error wrapping ErrInvalidTimeframe if the timeframe is invalid or unsupported.
 func NewTimeframe(s string) (Timeframe, error) {
 	// Reject empty string
 	if s == "" {
-		return "", fmt.Errorf("timeframe cannot be empty")
+		return "", fmt.Errorf("%w: cannot be empty", ErrInvalidTimeframe)
 	}
 
 	// Normalize to lowercase
@@ -47,7 +47,7 @@ func NewTimeframe(s string) (Timeframe, error) {
 		return tf, nil
 	}
 
-	return "", fmt.Errorf("unsupported timeframe: %q", s)
+	return "", fmt.Errorf("%w: unsupported value %q", ErrInvalidTimeframe, s)
 }
 
 // String returns the canonical string representation of the Timeframe.
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS candle_coverage;
+DROP TABLE IF EXISTS candles;
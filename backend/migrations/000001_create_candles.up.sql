@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS candles (
+    symbol    TEXT             NOT NULL,
+    timeframe TEXT             NOT NULL,
+    ts        TIMESTAMPTZ      NOT NULL,
+    open      DOUBLE PRECISION NOT NULL,
+    high      DOUBLE PRECISION NOT NULL,
+    low       DOUBLE PRECISION NOT NULL,
+    close     DOUBLE PRECISION NOT NULL,
+    volume    DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (symbol, timeframe, ts)
+);
+
+-- Ranges [from_ts, to_ts) known to be complete; candles absent from them do not exist upstream.
+CREATE TABLE IF NOT EXISTS candle_coverage (
+    symbol    TEXT        NOT NULL,
+    timeframe TEXT        NOT NULL,
+    from_ts   TIMESTAMPTZ NOT NULL,
+    to_ts     TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (symbol, timeframe, from_ts, to_ts),
+    CHECK (from_ts < to_ts)
+);
+
+-- On TimescaleDB, partition candles by time.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'timescaledb') THEN
+        PERFORM create_hypertable('candles', 'ts', if_not_exists => TRUE, migrate_data => TRUE);
+    END IF;
+END
+$$;
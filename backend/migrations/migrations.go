@@ -0,0 +1,13 @@
+// Package migrations ships the backend's SQL schema migrations.
+//
+// Files follow the golang-migrate naming scheme, <version>_<name>.up.sql and
+// <version>_<name>.down.sql, so they can be applied with that tool or, embedded, by
+// infra.PostgresCandleStore.Migrate.
+package migrations
+
+import "embed"
+
+// FS holds the migration files.
+//
+//go:embed *.sql
+var FS embed.FS
@@ -1,14 +1,18 @@
 package http_test
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
-	"encoding/json"
 
 	adhttp "github.com/akarso/pano_chart/backend/adapters/http"
+	"github.com/akarso/pano_chart/backend/application/ports"
 	"github.com/akarso/pano_chart/backend/domain"
 )
 
@@ -23,7 +27,7 @@ type fakeUseCase struct {
 	err      error
 }
 
-func (f *fakeUseCase) Execute(sym domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+func (f *fakeUseCase) Execute(_ context.Context, sym domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
 	f.called = true
 	f.lastSym = sym
 	f.lastTf = tf
@@ -62,7 +66,7 @@ func TestGetCandleSeriesHandler_Returns200OnSuccess(t *testing.T) {
 		Symbol    string `json:"symbol"`
 		Timeframe string `json:"timeframe"`
 		Candles   []struct {
-			Timestamp string  `json:"timestamp"`
+			Timestamp int64   `json:"timestamp"`
 			Open      float64 `json:"open"`
 			High      float64 `json:"high"`
 			Low       float64 `json:"low"`
@@ -84,6 +88,9 @@ func TestGetCandleSeriesHandler_Returns200OnSuccess(t *testing.T) {
 	if len(body.Candles) != 1 {
 		t.Fatalf("expected 1 candle, got %d", len(body.Candles))
 	}
+	if body.Candles[0].Timestamp != from.UnixMilli() {
+		t.Fatalf("expected epoch-ms timestamp %d, got %d", from.UnixMilli(), body.Candles[0].Timestamp)
+	}
 }
 
 func TestGetCandleSeriesHandler_Returns400OnInvalidParams(t *testing.T) {
@@ -150,3 +157,279 @@ func TestGetCandleSeriesHandler_Returns500OnUseCaseError(t *testing.T) {
 		t.Fatalf("expected 500, got %d", res.StatusCode)
 	}
 }
+
+// errorBody mirrors the COMMON.md error envelope.
+type errorBody struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func decodeErrorBody(t *testing.T, w *httptest.ResponseRecorder) errorBody {
+	t.Helper()
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("expected JSON error body, got content type %q", ct)
+	}
+	var body errorBody
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode error body: %v", err)
+	}
+	return body
+}
+
+func TestGetCandleSeriesHandler_ReturnsErrorEnvelopeForInvalidInput(t *testing.T) {
+	cases := []struct {
+		target string
+		code   string
+	}{
+		{"/api/v1/candles?symbol=BTC", "MISSING_PARAMETER"},
+		{"/api/v1/candles?symbol=BT$C&timeframe=1m&from=2026-01-01T12:00:00Z&to=2026-01-01T12:01:00Z", "INVALID_SYMBOL"},
+		{"/api/v1/candles?symbol=BTC&timeframe=7m&from=2026-01-01T12:00:00Z&to=2026-01-01T12:01:00Z", "INVALID_TIMEFRAME"},
+		{"/api/v1/candles?symbol=BTC&timeframe=1m&from=yesterday&to=2026-01-01T12:01:00Z", "INVALID_TIMESTAMP"},
+	}
+	for _, c := range cases {
+		h := adhttp.NewGetCandleSeriesHandler(&fakeUseCase{})
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", c.target, nil))
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", c.target, w.Code)
+		}
+		if body := decodeErrorBody(t, w); body.Error.Code != c.code || body.Error.Message == "" {
+			t.Fatalf("%s: expected code %s with message, got %+v", c.target, c.code, body.Error)
+		}
+	}
+}
+
+func TestGetCandleSeriesHandler_MapsUseCaseErrorsToCodes(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{fmt.Errorf("%w: from must be before to", domain.ErrInvalidRange), http.StatusBadRequest, "INVALID_RANGE"},
+		{&ports.UpstreamStatusError{StatusCode: 429}, http.StatusTooManyRequests, "RATE_LIMITED"},
+		{&ports.UpstreamStatusError{StatusCode: 503}, http.StatusBadGateway, "UPSTREAM_UNAVAILABLE"},
+		{errors.New("boom"), http.StatusInternalServerError, "INTERNAL_ERROR"},
+	}
+	for _, c := range cases {
+		h := adhttp.NewGetCandleSeriesHandler(&fakeUseCase{err: c.err})
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/candles?symbol=BTC&timeframe=1m&from=2026-01-01T12:00:00Z&to=2026-01-01T12:01:00Z", nil))
+
+		if w.Code != c.status {
+			t.Fatalf("%v: expected status %d, got %d", c.err, c.status, w.Code)
+		}
+		if body := decodeErrorBody(t, w); body.Error.Code != c.code {
+			t.Fatalf("%v: expected code %s, got %s", c.err, c.code, body.Error.Code)
+		}
+	}
+}
+
+func TestGetCandleSeriesHandler_SetsRetryAfterWhenRateLimited(t *testing.T) {
+	h := adhttp.NewGetCandleSeriesHandler(&fakeUseCase{err: &ports.RateLimitError{RetryAfter: 1500 * time.Millisecond}})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/candles?symbol=BTC&timeframe=1m&from=2026-01-01T12:00:00Z&to=2026-01-01T12:01:00Z", nil))
+
+	if w.Code != http.StatusTooManyRequests || decodeErrorBody(t, w).Error.Code != "RATE_LIMITED" {
+		t.Fatalf("expected 429 RATE_LIMITED, got %d", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Fatalf("expected Retry-After rounded up to 2 seconds, got %q", got)
+	}
+}
+
+func TestGetCandleSeriesHandler_DoesNotLeakInternalErrorMessages(t *testing.T) {
+	h := adhttp.NewGetCandleSeriesHandler(&fakeUseCase{err: errors.New("dial tcp 10.0.0.1: secret")})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/candles?symbol=BTC&timeframe=1m&from=2026-01-01T12:00:00Z&to=2026-01-01T12:01:00Z", nil))
+
+	if body := decodeErrorBody(t, w); strings.Contains(body.Error.Message, "secret") {
+		t.Fatalf("expected internal error message to be generic, got %q", body.Error.Message)
+	}
+}
+
+func TestGetCandleSeriesHandler_AcceptsEpochMillisecondRange(t *testing.T) {
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	to := from.Add(5 * time.Minute)
+
+	uc := &fakeUseCase{}
+	h := adhttp.NewGetCandleSeriesHandler(uc)
+
+	target := fmt.Sprintf("/api/v1/candles?symbol=BTC&timeframe=1m&from=%d&to=%s", from.UnixMilli(), to.Format(time.RFC3339))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+
+	if !uc.called {
+		t.Fatalf("expected use case to be called, got status %d", w.Code)
+	}
+	if !uc.lastFrom.Equal(from) || !uc.lastTo.Equal(to) {
+		t.Fatalf("expected mixed epoch-ms/RFC3339 range to be parsed, got %v..%v", uc.lastFrom, uc.lastTo)
+	}
+	if uc.lastFrom.Location() != time.UTC {
+		t.Fatalf("expected parsed time in UTC")
+	}
+}
+
+func TestGetCandleSeriesHandler_EmitsRFC3339WhenRequested(t *testing.T) {
+	sym := domain.NewSymbolUnsafe("BTC")
+	tf := domain.NewTimeframeUnsafe("1m")
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := domain.NewCandleUnsafe(sym, tf, from, 100, 110, 90, 105, 1000)
+	series, _ := domain.NewCandleSeries(sym, tf, []domain.Candle{c})
+
+	h := adhttp.NewGetCandleSeriesHandler(&fakeUseCase{series: series})
+
+	target := fmt.Sprintf("/api/v1/candles?symbol=BTC&timeframe=1m&from=%d&to=%d&format=rfc3339", from.UnixMilli(), from.Add(time.Minute).UnixMilli())
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+
+	var body struct {
+		Candles []struct {
+			Timestamp string `json:"timestamp"`
+		} `json:"candles"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(body.Candles) != 1 || body.Candles[0].Timestamp != "2026-01-01T12:00:00Z" {
+		t.Fatalf("expected RFC3339 timestamp, got %+v", body.Candles)
+	}
+}
+
+func TestGetCandleSeriesHandler_RejectsUnknownFormat(t *testing.T) {
+	uc := &fakeUseCase{}
+	h := adhttp.NewGetCandleSeriesHandler(uc)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/candles?symbol=BTC&timeframe=1m&from=2026-01-01T12:00:00Z&to=2026-01-01T12:01:00Z&format=unix", nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+	if body := decodeErrorBody(t, w); body.Error.Code != "INVALID_PARAMETER" {
+		t.Fatalf("expected INVALID_PARAMETER, got %s", body.Error.Code)
+	}
+	if uc.called {
+		t.Fatal("did not expect use case to be called")
+	}
+}
+
+func TestGetCandleSeriesHandler_EncodesEmptySeriesAsEmptyArray(t *testing.T) {
+	series, _ := domain.NewCandleSeries(domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), nil)
+	h := adhttp.NewGetCandleSeriesHandler(&fakeUseCase{series: series})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/candles?symbol=BTC&timeframe=1m&from=2026-01-01T12:00:00Z&to=2026-01-01T12:01:00Z", nil))
+
+	if !strings.Contains(w.Body.String(), `"candles":[]`) {
+		t.Fatalf("expected empty candle array, got %s", w.Body.String())
+	}
+}
+
+func TestGetCandleSeriesHandler_MapsContextErrors(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{context.DeadlineExceeded, http.StatusGatewayTimeout, "TIMEOUT"},
+		{fmt.Errorf("aborted: %w", context.Canceled), 499, "CANCELED"},
+	}
+	for _, c := range cases {
+		h := adhttp.NewGetCandleSeriesHandler(&fakeUseCase{err: c.err})
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/candles?symbol=BTC&timeframe=1m&from=2026-01-01T12:00:00Z&to=2026-01-01T12:01:00Z", nil))
+
+		if w.Code != c.status {
+			t.Fatalf("%v: expected status %d, got %d", c.err, c.status, w.Code)
+		}
+		if got := decodeErrorBody(t, w).Error.Code; got != c.code {
+			t.Fatalf("%v: expected code %s, got %s", c.err, c.code, got)
+		}
+	}
+}
+
+func TestWithRequestTimeout_SetsDeadlineOnRequestContext(t *testing.T) {
+	var deadline time.Time
+	var ok bool
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline, ok = r.Context().Deadline()
+	})
+
+	adhttp.WithRequestTimeout(next, time.Second).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	if !ok || time.Until(deadline) > time.Second {
+		t.Fatalf("expected a deadline within 1s, got %v (set=%v)", deadline, ok)
+	}
+}
+
+func TestWithStaleWarning_AddsWarningWhenDataIsStale(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ports.MarkStale(r.Context())
+		_, _ = w.Write([]byte("{}"))
+	})
+	w := httptest.NewRecorder()
+	adhttp.WithStaleWarning(next).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if got := w.Header().Get("Warning"); !strings.HasPrefix(got, "110") {
+		t.Fatalf("expected a 110 stale warning, got %q", got)
+	}
+}
+
+func TestWithStaleWarning_OmitsWarningForFreshData(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	w := httptest.NewRecorder()
+	adhttp.WithStaleWarning(next).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if got := w.Header().Get("Warning"); got != "" {
+		t.Fatalf("expected no warning, got %q", got)
+	}
+}
+
+func TestWithQualityHeader_SummarizesCandleIssues(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ports.RecordCandleIssues(r.Context(), ports.CandleIssue{Reason: "high below low"}, ports.CandleIssue{Reason: "negative volume", Repaired: true}, ports.CandleIssue{Reason: "zero timestamp"})
+		_, _ = w.Write([]byte("{}"))
+	})
+	w := httptest.NewRecorder()
+	adhttp.WithQualityHeader(next).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if got := w.Header().Get("X-Data-Quality"); got != "rejected=2, repaired=1" {
+		t.Fatalf("expected 2 rejected and 1 repaired candles, got %q", got)
+	}
+}
+
+func TestWithQualityHeader_OmitsHeaderForCleanData(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	w := httptest.NewRecorder()
+	adhttp.WithQualityHeader(next).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if got := w.Header().Get("X-Data-Quality"); got != "" {
+		t.Fatalf("expected no quality header, got %q", got)
+	}
+}
+
+func TestWithSourcesHeader_ListsProvidersInOrder(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ports.RecordProvenance(r.Context(),
+			ports.SourceRange{Source: "backup", From: t0.Add(time.Minute), To: t0.Add(2 * time.Minute)},
+			ports.SourceRange{Source: "primary", From: t0, To: t0.Add(time.Minute)},
+			ports.SourceRange{Source: "primary", From: t0.Add(2 * time.Minute), To: t0.Add(3 * time.Minute)},
+		)
+		_, _ = w.Write([]byte("{}"))
+	})
+	w := httptest.NewRecorder()
+	adhttp.WithSourcesHeader(next).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if got := w.Header().Get("X-Data-Sources"); got != "primary, backup" {
+		t.Fatalf("expected the providers in order of their first candle, got %q", got)
+	}
+}
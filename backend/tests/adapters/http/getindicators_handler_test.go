@@ -0,0 +1,135 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	adhttp "github.com/akarso/pano_chart/backend/adapters/http"
+	"github.com/akarso/pano_chart/backend/application/usecases"
+	"github.com/akarso/pano_chart/backend/domain"
+	"github.com/akarso/pano_chart/backend/domain/indicators"
+)
+
+// fakeIndicatorsUseCase implements usecases.GetIndicators for testing.
+type fakeIndicatorsUseCase struct {
+	called    bool
+	lastSpecs []indicators.Spec
+	lastGaps  indicators.GapMode
+	result    usecases.IndicatorSeries
+}
+
+func (f *fakeIndicatorsUseCase) Execute(_ context.Context, sym domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time, specs []indicators.Spec, gaps indicators.GapMode) (usecases.IndicatorSeries, error) {
+	f.called = true
+	f.lastSpecs = specs
+	f.lastGaps = gaps
+	return f.result, nil
+}
+
+const indicatorsQuery = "/api/v1/indicators?symbol=BTC&timeframe=1m&from=2026-01-01T12:00:00Z&to=2026-01-01T12:02:00Z"
+
+func TestGetIndicatorsHandler_ReturnsCandlesAndIndicatorLines(t *testing.T) {
+	sym := domain.NewSymbolUnsafe("BTC")
+	tf := domain.Timeframe1m
+	ts := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	c1 := domain.NewCandleUnsafe(sym, tf, ts, 1, 1, 1, 1, 1)
+	c2 := domain.NewCandleUnsafe(sym, tf, ts.Add(time.Minute), 3, 3, 3, 3, 1)
+	series, _ := domain.NewCandleSeries(sym, tf, []domain.Candle{c1, c2})
+	spec, _ := indicators.ParseSpec("sma:2")
+	line, _ := indicators.SMA(series, 2, indicators.GapReset)
+
+	uc := &fakeIndicatorsUseCase{result: usecases.IndicatorSeries{
+		Series:     series,
+		Indicators: []usecases.IndicatorOutput{{Spec: spec, Lines: []indicators.Series{line}}},
+	}}
+	h := adhttp.NewGetIndicatorsHandler(uc)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", indicatorsQuery+"&indicators=sma:2", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Candles    []json.RawMessage `json:"candles"`
+		Indicators []struct {
+			Name  string `json:"name"`
+			Lines []struct {
+				Name   string `json:"name"`
+				Points []struct {
+					Timestamp int64    `json:"timestamp"`
+					Value     *float64 `json:"value"`
+				} `json:"points"`
+			} `json:"lines"`
+		} `json:"indicators"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(body.Candles) != 2 || len(body.Indicators) != 1 {
+		t.Fatalf("expected 2 candles and 1 indicator, got %d/%d", len(body.Candles), len(body.Indicators))
+	}
+	ind := body.Indicators[0]
+	if ind.Name != "sma:2" || len(ind.Lines) != 1 || ind.Lines[0].Name != "sma" {
+		t.Fatalf("unexpected indicator shape: %+v", ind)
+	}
+	pts := ind.Lines[0].Points
+	if len(pts) != 2 || pts[0].Value != nil || pts[1].Value == nil || *pts[1].Value != 2 {
+		t.Fatalf("expected [null, 2], got %+v", pts)
+	}
+	if pts[1].Timestamp != ts.Add(time.Minute).UnixMilli() {
+		t.Fatalf("expected point timestamp aligned to candle, got %d", pts[1].Timestamp)
+	}
+}
+
+func TestGetIndicatorsHandler_ParsesSpecsAndGapMode(t *testing.T) {
+	uc := &fakeIndicatorsUseCase{}
+	h := adhttp.NewGetIndicatorsHandler(uc)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", indicatorsQuery+"&indicators=rsi,macd:5:10:3&gaps=ignore", nil))
+
+	if !uc.called {
+		t.Fatalf("expected use case to be called, got %d", w.Code)
+	}
+	if len(uc.lastSpecs) != 2 || uc.lastSpecs[0].String() != "rsi:14" || uc.lastSpecs[1].String() != "macd:5:10:3" {
+		t.Fatalf("unexpected specs forwarded: %v", uc.lastSpecs)
+	}
+	if uc.lastGaps != indicators.GapIgnore {
+		t.Fatalf("expected GapIgnore, got %v", uc.lastGaps)
+	}
+}
+
+func TestGetIndicatorsHandler_Returns400OnInvalidParams(t *testing.T) {
+	cases := []struct {
+		query string
+		code  string
+	}{
+		{indicatorsQuery, "MISSING_PARAMETER"},
+		{indicatorsQuery + "&indicators=vwap", "INVALID_INDICATOR"},
+		{indicatorsQuery + "&indicators=sma:0", "INVALID_INDICATOR"},
+		{indicatorsQuery + "&indicators=ema:1e300", "INVALID_INDICATOR"},
+		{indicatorsQuery + "&indicators=sma&gaps=fill", "INVALID_PARAMETER"},
+		{indicatorsQuery + "&indicators=sma,sma,sma,sma,sma,sma,sma,sma,sma,sma,sma", "INVALID_PARAMETER"},
+	}
+	for _, c := range cases {
+		uc := &fakeIndicatorsUseCase{}
+		h := adhttp.NewGetIndicatorsHandler(uc)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", c.query, nil))
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", c.query, w.Code)
+		}
+		if body := decodeErrorBody(t, w); body.Error.Code != c.code {
+			t.Fatalf("%s: expected %s, got %s", c.query, c.code, body.Error.Code)
+		}
+		if uc.called {
+			t.Fatalf("%s: did not expect use case to be called", c.query)
+		}
+	}
+}
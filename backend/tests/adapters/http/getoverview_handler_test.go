@@ -0,0 +1,267 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	adhttp "github.com/akarso/pano_chart/backend/adapters/http"
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/application/usecases"
+	"github.com/akarso/pano_chart/backend/domain"
+	"github.com/akarso/pano_chart/backend/domain/analysis"
+)
+
+// fakeOverviewUseCase implements usecases.GetOverview for testing.
+type fakeOverviewUseCase struct {
+	called   bool
+	lastSyms []domain.Symbol
+	lastTf   domain.Timeframe
+	lastFrom time.Time
+	lastTo   time.Time
+	entries  []usecases.OverviewEntry
+	err      error
+}
+
+func (f *fakeOverviewUseCase) Execute(_ context.Context, syms []domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (usecases.Overview, error) {
+	f.called = true
+	f.lastSyms = syms
+	f.lastTf = tf
+	f.lastFrom = from
+	f.lastTo = to
+	if f.err != nil {
+		return usecases.Overview{}, f.err
+	}
+	return usecases.Overview{Timeframe: tf, Entries: f.entries}, nil
+}
+
+type overviewBody struct {
+	Timeframe string `json:"timeframe"`
+	Symbols   []struct {
+		Symbol  string `json:"symbol"`
+		Candles []struct {
+			Timestamp int64   `json:"timestamp"`
+			Open      float64 `json:"open"`
+			Close     float64 `json:"close"`
+		} `json:"candles"`
+		Range *struct {
+			Score    float64 `json:"score"`
+			WidthATR float64 `json:"width_atr"`
+			ADX      float64 `json:"adx"`
+		} `json:"range"`
+		Error *struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	} `json:"symbols"`
+}
+
+func TestGetOverviewHandler_Returns200WithPerSymbolEntries(t *testing.T) {
+	tf := domain.NewTimeframeUnsafe("15m")
+	ts := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	btc := domain.NewSymbolUnsafe("BTCUSDT")
+	eth := domain.NewSymbolUnsafe("ETHUSDT")
+	c := domain.NewCandleUnsafe(btc, tf, ts, 100, 110, 90, 105, 1000)
+	series, _ := domain.NewCandleSeries(btc, tf, []domain.Candle{c})
+
+	uc := &fakeOverviewUseCase{entries: []usecases.OverviewEntry{
+		{Symbol: btc, Series: series},
+		{Symbol: eth, Err: errors.New("upstream down")},
+	}}
+	h := adhttp.NewGetOverviewHandler(uc)
+
+	req := httptest.NewRequest("GET", "/api/v1/overview?symbols=btcusdt,ETHUSDT&timeframe=15m", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	res := w.Result()
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", res.StatusCode)
+	}
+
+	var body overviewBody
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if body.Timeframe != "15m" {
+		t.Fatalf("expected timeframe 15m, got %s", body.Timeframe)
+	}
+	if len(body.Symbols) != 2 {
+		t.Fatalf("expected 2 symbols, got %d", len(body.Symbols))
+	}
+	if body.Symbols[0].Symbol != "BTCUSDT" || len(body.Symbols[0].Candles) != 1 || body.Symbols[0].Error != nil {
+		t.Fatalf("unexpected BTC entry: %+v", body.Symbols[0])
+	}
+	if body.Symbols[0].Candles[0].Timestamp != ts.UnixMilli() {
+		t.Fatalf("expected epoch-ms timestamp %d, got %d", ts.UnixMilli(), body.Symbols[0].Candles[0].Timestamp)
+	}
+	if body.Symbols[1].Error == nil || body.Symbols[1].Error.Code != "INTERNAL_ERROR" {
+		t.Fatalf("expected ETH entry to carry an error, got %+v", body.Symbols[1])
+	}
+	if body.Symbols[1].Candles == nil || len(body.Symbols[1].Candles) != 0 {
+		t.Fatalf("expected failed entry to carry an empty candle list")
+	}
+}
+
+func TestGetOverviewHandler_ParsesAndDeduplicatesSymbols(t *testing.T) {
+	uc := &fakeOverviewUseCase{}
+	h := adhttp.NewGetOverviewHandler(uc)
+
+	req := httptest.NewRequest("GET", "/api/v1/overview?symbols=btc,%20eth,BTC&timeframe=1h", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if !uc.called {
+		t.Fatal("expected use case to be called")
+	}
+	if len(uc.lastSyms) != 2 || uc.lastSyms[0] != "BTC" || uc.lastSyms[1] != "ETH" {
+		t.Fatalf("unexpected symbols forwarded: %v", uc.lastSyms)
+	}
+	if uc.lastTf != domain.Timeframe1h {
+		t.Fatalf("expected 1h timeframe, got %v", uc.lastTf)
+	}
+}
+
+func TestGetOverviewHandler_DefaultsRangeWhenOmitted(t *testing.T) {
+	uc := &fakeOverviewUseCase{}
+	h := adhttp.NewGetOverviewHandler(uc)
+
+	req := httptest.NewRequest("GET", "/api/v1/overview?symbols=BTC&timeframe=5m", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if !uc.called {
+		t.Fatal("expected use case to be called")
+	}
+	if uc.lastTo.Sub(uc.lastFrom) != 100*5*time.Minute {
+		t.Fatalf("expected default window of 100 candles, got %v", uc.lastTo.Sub(uc.lastFrom))
+	}
+	if !uc.lastTo.Equal(uc.lastTo.Truncate(5 * time.Minute)) {
+		t.Fatalf("expected range end aligned to the timeframe, got %v", uc.lastTo)
+	}
+}
+
+func TestGetOverviewHandler_ForwardsExplicitRange(t *testing.T) {
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	uc := &fakeOverviewUseCase{}
+	h := adhttp.NewGetOverviewHandler(uc)
+
+	req := httptest.NewRequest("GET", "/api/v1/overview?symbols=BTC&timeframe=1m&from="+from.Format(time.RFC3339)+"&to="+to.Format(time.RFC3339), nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if !uc.lastFrom.Equal(from) || !uc.lastTo.Equal(to) {
+		t.Fatalf("expected time range forwarded unchanged")
+	}
+}
+
+func TestGetOverviewHandler_Returns400OnInvalidParams(t *testing.T) {
+	cases := []string{
+		"/api/v1/overview?timeframe=1m",
+		"/api/v1/overview?symbols=BTC",
+		"/api/v1/overview?symbols=BTC,bad$sym&timeframe=1m",
+		"/api/v1/overview?symbols=BTC&timeframe=2m",
+		"/api/v1/overview?symbols=BTC&timeframe=1m&from=2026-01-01T12:00:00Z",
+		"/api/v1/overview?symbols=BTC&timeframe=1m&sort=volume",
+	}
+	for _, target := range cases {
+		uc := &fakeOverviewUseCase{}
+		h := adhttp.NewGetOverviewHandler(uc)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", target, w.Code)
+		}
+		if uc.called {
+			t.Fatalf("%s: did not expect use case to be called", target)
+		}
+	}
+}
+
+func TestGetOverviewHandler_Returns500OnUseCaseError(t *testing.T) {
+	uc := &fakeOverviewUseCase{err: errors.New("boom")}
+	h := adhttp.NewGetOverviewHandler(uc)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/overview?symbols=BTC&timeframe=1m", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+}
+
+func TestGetOverviewHandler_MapsPerSymbolErrorCodes(t *testing.T) {
+	uc := &fakeOverviewUseCase{entries: []usecases.OverviewEntry{
+		{Symbol: domain.NewSymbolUnsafe("BTC"), Err: &ports.UpstreamStatusError{StatusCode: 429}},
+	}}
+	h := adhttp.NewGetOverviewHandler(uc)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/overview?symbols=BTC&timeframe=1m", nil))
+
+	var body overviewBody
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if body.Symbols[0].Error == nil || body.Symbols[0].Error.Code != "RATE_LIMITED" {
+		t.Fatalf("expected RATE_LIMITED entry error, got %+v", body.Symbols[0].Error)
+	}
+}
+
+func TestGetOverviewHandler_ReturnsErrorEnvelopeForInvalidSymbol(t *testing.T) {
+	h := adhttp.NewGetOverviewHandler(&fakeOverviewUseCase{})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/overview?symbols=BTC,bad$&timeframe=1m", nil))
+
+	if body := decodeErrorBody(t, w); body.Error.Code != "INVALID_SYMBOL" {
+		t.Fatalf("expected INVALID_SYMBOL, got %s", body.Error.Code)
+	}
+}
+
+func TestGetOverviewHandler_SortsByRangeScore(t *testing.T) {
+	a, b, c := domain.NewSymbolUnsafe("A"), domain.NewSymbolUnsafe("B"), domain.NewSymbolUnsafe("C")
+	uc := &fakeOverviewUseCase{entries: []usecases.OverviewEntry{
+		{Symbol: a, Range: &analysis.RangeAnalysis{Score: 0.2, WidthATR: 9, ADX: 35}},
+		{Symbol: b},
+		{Symbol: c, Range: &analysis.RangeAnalysis{Score: 0.9, WidthATR: 3, ADX: 12}},
+	}}
+	h := adhttp.NewGetOverviewHandler(uc)
+
+	for target, want := range map[string][]string{
+		"/api/v1/overview?symbols=A,B,C&timeframe=1h":            {"A", "B", "C"},
+		"/api/v1/overview?symbols=A,B,C&timeframe=1h&sort=range": {"C", "A", "B"},
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", target, w.Code)
+		}
+		var body overviewBody
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		for i, sym := range want {
+			if body.Symbols[i].Symbol != sym {
+				t.Fatalf("%s: position %d: expected %s, got %s", target, i, sym, body.Symbols[i].Symbol)
+			}
+		}
+		for _, e := range body.Symbols {
+			if (e.Symbol == "B") != (e.Range == nil) {
+				t.Fatalf("%s: unexpected range presence for %s: %+v", target, e.Symbol, e.Range)
+			}
+			if e.Symbol == "C" && (e.Range.Score != 0.9 || e.Range.WidthATR != 3 || e.Range.ADX != 12) {
+				t.Fatalf("%s: unexpected range for C: %+v", target, e.Range)
+			}
+		}
+	}
+}
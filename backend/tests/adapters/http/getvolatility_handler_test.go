@@ -0,0 +1,131 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	adhttp "github.com/akarso/pano_chart/backend/adapters/http"
+	"github.com/akarso/pano_chart/backend/application/usecases"
+	"github.com/akarso/pano_chart/backend/domain"
+	"github.com/akarso/pano_chart/backend/domain/indicators"
+)
+
+// fakeVolatilityUseCase implements usecases.GetVolatility for testing.
+type fakeVolatilityUseCase struct {
+	called   bool
+	lastSyms []domain.Symbol
+	lastEsts []indicators.Estimator
+	entries  []usecases.VolatilityEntry
+}
+
+func (f *fakeVolatilityUseCase) Execute(_ context.Context, syms []domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time, ests []indicators.Estimator) (usecases.VolatilityReport, error) {
+	f.called = true
+	f.lastSyms = syms
+	f.lastEsts = ests
+	return usecases.VolatilityReport{Timeframe: tf, Entries: f.entries}, nil
+}
+
+type volatilityBody struct {
+	Timeframe string `json:"timeframe"`
+	Symbols   []struct {
+		Symbol     string              `json:"symbol"`
+		Candles    int                 `json:"candles"`
+		Volatility map[string]*float64 `json:"volatility"`
+		Error      *struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	} `json:"symbols"`
+}
+
+func volValues(cc, park float64, valid bool) []usecases.VolatilityValue {
+	return []usecases.VolatilityValue{
+		{Estimator: indicators.CloseToClose, Value: cc, Valid: valid},
+		{Estimator: indicators.Parkinson, Value: park, Valid: valid},
+	}
+}
+
+func TestGetVolatilityHandler_ReturnsPerSymbolValuesSorted(t *testing.T) {
+	uc := &fakeVolatilityUseCase{entries: []usecases.VolatilityEntry{
+		{Symbol: domain.NewSymbolUnsafe("A"), Candles: 30, Values: volValues(0.4, 0.9, true)},
+		{Symbol: domain.NewSymbolUnsafe("B"), Candles: 1, Values: volValues(0, 0, false)},
+		{Symbol: domain.NewSymbolUnsafe("C"), Candles: 30, Values: volValues(0.8, 0.5, true)},
+		{Symbol: domain.NewSymbolUnsafe("D"), Err: errors.New("boom")},
+	}}
+	h := adhttp.NewGetVolatilityHandler(uc)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/volatility?symbols=A,B,C,D&timeframe=1d&estimators=cc,parkinson,cc&sort=cc", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(uc.lastEsts) != 2 || uc.lastEsts[0] != indicators.CloseToClose || uc.lastEsts[1] != indicators.Parkinson {
+		t.Fatalf("expected de-duplicated estimators [cc parkinson], got %v", uc.lastEsts)
+	}
+
+	var body volatilityBody
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	order := []string{"C", "A", "B", "D"}
+	for i, sym := range order {
+		if body.Symbols[i].Symbol != sym {
+			t.Fatalf("position %d: expected %s, got %s", i, sym, body.Symbols[i].Symbol)
+		}
+	}
+	if v := body.Symbols[0].Volatility["cc"]; v == nil || *v != 0.8 {
+		t.Fatalf("expected C cc volatility 0.8, got %v", v)
+	}
+	if v, ok := body.Symbols[2].Volatility["parkinson"]; !ok || v != nil {
+		t.Fatalf("expected B parkinson volatility to be null, got %v (present=%v)", v, ok)
+	}
+	if body.Symbols[3].Error == nil || body.Symbols[3].Error.Code != "INTERNAL_ERROR" {
+		t.Fatalf("expected D to carry an error, got %+v", body.Symbols[3])
+	}
+}
+
+func TestGetVolatilityHandler_DefaultsToAllEstimators(t *testing.T) {
+	uc := &fakeVolatilityUseCase{}
+	h := adhttp.NewGetVolatilityHandler(uc)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/volatility?symbols=BTC&timeframe=1d", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if len(uc.lastEsts) != len(indicators.Estimators) {
+		t.Fatalf("expected all estimators, got %v", uc.lastEsts)
+	}
+}
+
+func TestGetVolatilityHandler_Returns400OnInvalidParams(t *testing.T) {
+	cases := map[string]string{
+		"/api/v1/volatility?timeframe=1d":                                       "MISSING_PARAMETER",
+		"/api/v1/volatility?symbols=BTC&timeframe=1d&estimators=yz":             "INVALID_INDICATOR",
+		"/api/v1/volatility?symbols=BTC&timeframe=1d&sort=gk&estimators=cc":     "INVALID_PARAMETER",
+		"/api/v1/volatility?symbols=BTC&timeframe=1d&from=2026-01-01T00:00:00Z": "INVALID_RANGE",
+	}
+	for target, code := range cases {
+		uc := &fakeVolatilityUseCase{}
+		h := adhttp.NewGetVolatilityHandler(uc)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", target, w.Code)
+		}
+		if got := decodeErrorBody(t, w).Error.Code; got != code {
+			t.Errorf("%s: expected code %s, got %s", target, code, got)
+		}
+		if uc.called {
+			t.Fatalf("%s: did not expect use case to be called", target)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	adhttp "github.com/akarso/pano_chart/backend/adapters/http"
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/application/usecases"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// fakeScreenerUseCase implements usecases.Screener for testing.
+type fakeScreenerUseCase struct {
+	called bool
+	last   usecases.ScreenerQuery
+	res    usecases.ScreenerResult
+	err    error
+}
+
+func (f *fakeScreenerUseCase) Execute(_ context.Context, q usecases.ScreenerQuery) (usecases.ScreenerResult, error) {
+	f.called = true
+	f.last = q
+	if f.err != nil {
+		return usecases.ScreenerResult{}, f.err
+	}
+	return f.res, nil
+}
+
+type screenerBody struct {
+	Timeframe string `json:"timeframe"`
+	From      int64  `json:"from"`
+	To        int64  `json:"to"`
+	Rows      []struct {
+		Symbol  string             `json:"symbol"`
+		Metrics map[string]float64 `json:"metrics"`
+	} `json:"rows"`
+	Errors []struct {
+		Symbol string `json:"symbol"`
+		Error  struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	} `json:"errors"`
+}
+
+func TestScreenerHandler_BuildsQueryAndRendersRows(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(100 * time.Hour)
+	uc := &fakeScreenerUseCase{res: usecases.ScreenerResult{
+		Timeframe: domain.Timeframe1h,
+		From:      from,
+		To:        to,
+		Rows: []usecases.ScreenerRow{
+			{Symbol: domain.NewSymbolUnsafe("BTC"), Metrics: map[usecases.Metric]float64{usecases.MetricRangeScore: 0.8}},
+		},
+		Failures: []usecases.ScreenerFailure{{Symbol: domain.NewSymbolUnsafe("ETH"), Err: ports.ErrRateLimited}},
+	}}
+	h := adhttp.NewScreenerHandler(uc, nil)
+
+	target := "/api/v1/screener?timeframe=1h&symbols=btc,eth&lookback=50&filter=volatility%20%3E%200.5%20AND%20change_24h%20%3C%202&sort=range_score&order=asc&limit=20"
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	q := uc.last
+	if len(q.Symbols) != 2 || q.Timeframe != domain.Timeframe1h || q.Lookback != 50 || q.Limit != 20 {
+		t.Fatalf("unexpected query: %+v", q)
+	}
+	if q.SortBy != usecases.MetricRangeScore || !q.Ascending || len(q.Filter) != 2 {
+		t.Fatalf("unexpected sort or filter: %+v", q)
+	}
+
+	var body screenerBody
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if body.From != from.UnixMilli() || body.To != to.UnixMilli() {
+		t.Fatalf("unexpected window %d..%d", body.From, body.To)
+	}
+	if len(body.Rows) != 1 || body.Rows[0].Symbol != "BTC" || body.Rows[0].Metrics["range_score"] != 0.8 {
+		t.Fatalf("unexpected rows: %+v", body.Rows)
+	}
+	if len(body.Errors) != 1 || body.Errors[0].Symbol != "ETH" || body.Errors[0].Error.Code != "RATE_LIMITED" {
+		t.Fatalf("unexpected errors: %+v", body.Errors)
+	}
+}
+
+func TestScreenerHandler_UsesDefaultUniverse(t *testing.T) {
+	universe := []domain.Symbol{domain.NewSymbolUnsafe("BTC"), domain.NewSymbolUnsafe("ETH")}
+	uc := &fakeScreenerUseCase{}
+	h := adhttp.NewScreenerHandler(uc, universe)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/screener?timeframe=1d", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if len(uc.last.Symbols) != 2 || uc.last.Lookback != 100 || uc.last.Limit != 0 || uc.last.SortBy != "" {
+		t.Fatalf("unexpected defaults: %+v", uc.last)
+	}
+}
+
+func TestScreenerHandler_Returns400OnInvalidParams(t *testing.T) {
+	cases := map[string]string{
+		"/api/v1/screener?symbols=BTC":                         "MISSING_PARAMETER",
+		"/api/v1/screener?timeframe=1h":                        "MISSING_PARAMETER",
+		"/api/v1/screener?timeframe=1h&symbols=BTC&lookback=1": "INVALID_PARAMETER",
+		"/api/v1/screener?timeframe=1h&symbols=BTC&limit=x":    "INVALID_PARAMETER",
+		"/api/v1/screener?timeframe=1h&symbols=BTC&sort=rsi":   "INVALID_PARAMETER",
+		"/api/v1/screener?timeframe=1h&symbols=BTC&order=up":   "INVALID_PARAMETER",
+		"/api/v1/screener?timeframe=1h&symbols=BTC&filter=rsi": "INVALID_PARAMETER",
+		"/api/v1/screener?timeframe=2h&symbols=BTC":            "INVALID_TIMEFRAME",
+	}
+	for target, code := range cases {
+		uc := &fakeScreenerUseCase{}
+		h := adhttp.NewScreenerHandler(uc, nil)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", target, w.Code)
+		}
+		if got := decodeErrorBody(t, w).Error.Code; got != code {
+			t.Errorf("%s: expected code %s, got %s", target, code, got)
+		}
+		if uc.called {
+			t.Fatalf("%s: did not expect use case to be called", target)
+		}
+	}
+}
+
+func TestScreenerHandler_Returns500OnUseCaseError(t *testing.T) {
+	uc := &fakeScreenerUseCase{err: errors.New("boom")}
+	h := adhttp.NewScreenerHandler(uc, nil)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/screener?timeframe=1h&symbols=BTC", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+}
+
+func TestScreenerHandler_Returns500WhenTheResponseCannotBeEncoded(t *testing.T) {
+	uc := &fakeScreenerUseCase{res: usecases.ScreenerResult{
+		Timeframe: domain.Timeframe1h,
+		Rows: []usecases.ScreenerRow{
+			{Symbol: domain.NewSymbolUnsafe("BTC"), Metrics: map[usecases.Metric]float64{usecases.MetricRangeScore: math.NaN()}},
+		},
+	}}
+	h := adhttp.NewScreenerHandler(uc, nil)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/screener?timeframe=1h&symbols=BTC", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d: %s", w.Code, w.Body.String())
+	}
+	if body := decodeErrorBody(t, w); body.Error.Code != "INTERNAL_ERROR" {
+		t.Fatalf("expected INTERNAL_ERROR, got %s", body.Error.Code)
+	}
+}
@@ -0,0 +1,147 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	adhttp "github.com/akarso/pano_chart/backend/adapters/http"
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// fakeStream implements ports.CandleStreamPort by replaying updates and then ending the
+// subscription as if the subscriber had fallen behind.
+type fakeStream struct {
+	updates []ports.CandleUpdate
+	keys    []ports.SeriesKey
+	err     error
+}
+
+func (f *fakeStream) Publish(ports.CandleUpdate) {}
+
+func (f *fakeStream) Watched() []ports.SeriesKey { return f.keys }
+
+func (f *fakeStream) Subscribe(_ context.Context, keys []ports.SeriesKey) (<-chan ports.CandleUpdate, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.keys = keys
+	ch := make(chan ports.CandleUpdate, len(f.updates))
+	for _, u := range f.updates {
+		ch <- u
+	}
+	close(ch)
+	return ch, nil
+}
+
+// sseEvent is one parsed Server-Sent Event.
+type sseEvent struct {
+	name string
+	data string
+}
+
+func parseEvents(body string) []sseEvent {
+	var events []sseEvent
+	for _, block := range strings.Split(body, "\n\n") {
+		var e sseEvent
+		for _, line := range strings.Split(block, "\n") {
+			if v, ok := strings.CutPrefix(line, "event: "); ok {
+				e.name = v
+			} else if v, ok := strings.CutPrefix(line, "data: "); ok {
+				e.data = v
+			}
+		}
+		if e.name != "" {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+func TestStreamCandlesHandler_StreamsUpdatesAsEvents(t *testing.T) {
+	sym, tf := domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m")
+	ts := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	stream := &fakeStream{updates: []ports.CandleUpdate{{Candle: domain.NewCandleUnsafe(sym, tf, ts, 100, 110, 90, 105, 1000)}}}
+	h := adhttp.NewStreamCandlesHandler(stream)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/stream?pairs=btc:1m,eth:1h,BTC:1m&format=rfc3339", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected an event stream, got %q", ct)
+	}
+	if len(stream.keys) != 2 {
+		t.Fatalf("expected 2 distinct subscriptions, got %v", stream.keys)
+	}
+
+	events := parseEvents(w.Body.String())
+	if len(events) != 2 || events[0].name != "candle" || events[1].name != "error" {
+		t.Fatalf("expected a candle event then an error event, got %+v", events)
+	}
+	var candle struct {
+		Symbol    string `json:"symbol"`
+		Timeframe string `json:"timeframe"`
+		Closed    bool   `json:"closed"`
+		Candle    struct {
+			Timestamp string  `json:"timestamp"`
+			Close     float64 `json:"close"`
+		} `json:"candle"`
+	}
+	if err := json.Unmarshal([]byte(events[0].data), &candle); err != nil {
+		t.Fatalf("invalid candle event: %v", err)
+	}
+	if candle.Symbol != "BTC" || candle.Timeframe != "1m" || candle.Closed || candle.Candle.Timestamp != "2026-01-01T12:00:00Z" || candle.Candle.Close != 105 {
+		t.Fatalf("unexpected candle event: %+v", candle)
+	}
+	if !strings.Contains(events[1].data, adhttp.CodeSlowConsumer) {
+		t.Fatalf("expected a SLOW_CONSUMER error, got %s", events[1].data)
+	}
+}
+
+func TestStreamCandlesHandler_RejectsInvalidSubscriptions(t *testing.T) {
+	many := make([]string, 51)
+	for i := range many {
+		many[i] = fmt.Sprintf("S%d:1m", i)
+	}
+	cases := []struct {
+		query string
+		code  string
+	}{
+		{"", adhttp.CodeMissingParameter},
+		{"pairs=BTC", adhttp.CodeInvalidTimeframe},
+		{"pairs=BTC:7m", adhttp.CodeInvalidTimeframe},
+		{"pairs=:1m", adhttp.CodeInvalidSymbol},
+		{"pairs=" + strings.Join(many, ","), adhttp.CodeInvalidParameter},
+	}
+	for _, tc := range cases {
+		stream := &fakeStream{}
+		w := httptest.NewRecorder()
+		adhttp.NewStreamCandlesHandler(stream).ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/stream?"+tc.query, nil))
+
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), tc.code) {
+			t.Fatalf("%q: expected 400 %s, got %d: %s", tc.query, tc.code, w.Code, w.Body.String())
+		}
+		if stream.keys != nil {
+			t.Fatalf("%q: expected no subscription", tc.query)
+		}
+	}
+}
+
+func TestStreamCandlesHandler_RejectsSubscriptionsPastTheStreamLimit(t *testing.T) {
+	stream := &fakeStream{err: fmt.Errorf("%w: 2 watched series", ports.ErrStreamFull)}
+	w := httptest.NewRecorder()
+	adhttp.NewStreamCandlesHandler(stream).ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/stream?pairs=BTC:1m", nil))
+
+	if w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), adhttp.CodeStreamLimit) {
+		t.Fatalf("expected 503 %s, got %d: %s", adhttp.CodeStreamLimit, w.Code, w.Body.String())
+	}
+}
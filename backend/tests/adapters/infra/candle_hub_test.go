@@ -0,0 +1,122 @@
+package infra_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/adapters/infra"
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+func hubUpdate(symbol string, minute int) ports.CandleUpdate {
+	sym, tf := domain.NewSymbolUnsafe(symbol), domain.NewTimeframeUnsafe("1m")
+	ts := time.Date(2026, 1, 1, 12, minute, 0, 0, time.UTC)
+	return ports.CandleUpdate{Candle: domain.NewCandleUnsafe(sym, tf, ts, 100, 110, 90, 105, 1000)}
+}
+
+func hubKey(symbol string) ports.SeriesKey {
+	return ports.SeriesKey{Symbol: domain.NewSymbolUnsafe(symbol), Timeframe: domain.NewTimeframeUnsafe("1m")}
+}
+
+func subscribe(t *testing.T, hub *infra.CandleHub, ctx context.Context, symbols ...string) <-chan ports.CandleUpdate {
+	t.Helper()
+	keys := make([]ports.SeriesKey, len(symbols))
+	for i, s := range symbols {
+		keys[i] = hubKey(s)
+	}
+	updates, err := hub.Subscribe(ctx, keys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return updates
+}
+
+func TestCandleHub_DeliversToSubscribersOfTheSeries(t *testing.T) {
+	var _ ports.CandleStreamPort = infra.NewCandleHub(0)
+	hub := infra.NewCandleHub(4)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	btc := subscribe(t, hub, ctx, "BTC")
+	both := subscribe(t, hub, ctx, "BTC", "ETH")
+
+	hub.Publish(hubUpdate("ETH", 0))
+	hub.Publish(hubUpdate("BTC", 1))
+
+	if u := <-btc; u.Candle.Symbol().String() != "BTC" {
+		t.Fatalf("expected only BTC updates, got %v", u.Candle.Symbol())
+	}
+	if len(btc) != 0 || len(both) != 2 {
+		t.Fatalf("expected 0 and 2 pending updates, got %d and %d", len(btc), len(both))
+	}
+	if got := len(hub.Watched()); got != 2 {
+		t.Fatalf("expected 2 watched series, got %d", got)
+	}
+}
+
+func TestCandleHub_DropsSubscribersThatFallBehind(t *testing.T) {
+	hub := infra.NewCandleHub(2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	slow := subscribe(t, hub, ctx, "BTC")
+
+	// Publishing never blocks, even with nobody reading.
+	for i := 0; i < 5; i++ {
+		hub.Publish(hubUpdate("BTC", i))
+	}
+
+	var got int
+	for range slow {
+		got++
+	}
+	if got != 2 {
+		t.Fatalf("expected the buffered updates before the channel closed, got %d", got)
+	}
+	if len(hub.Watched()) != 0 {
+		t.Fatalf("expected the dropped subscriber to be unsubscribed, got %v", hub.Watched())
+	}
+}
+
+func TestCandleHub_UnsubscribesWhenContextEnds(t *testing.T) {
+	hub := infra.NewCandleHub(2)
+	ctx, cancel := context.WithCancel(context.Background())
+	updates := subscribe(t, hub, ctx, "BTC")
+
+	cancel()
+	select {
+	case _, ok := <-updates:
+		if ok {
+			t.Fatal("expected no updates")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the channel to close once the context ended")
+	}
+	if len(hub.Watched()) != 0 {
+		t.Fatalf("expected no watched series, got %v", hub.Watched())
+	}
+	hub.Publish(hubUpdate("BTC", 0))
+}
+
+func TestCandleHub_LimitsSubscribersAndWatchedSeries(t *testing.T) {
+	hub := infra.NewCandleHub(2).WithLimits(2, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	subscribe(t, hub, ctx, "BTC", "ETH")
+
+	if _, err := hub.Subscribe(ctx, []ports.SeriesKey{hubKey("SOL")}); !errors.Is(err, ports.ErrStreamFull) {
+		t.Fatalf("expected a third series to be refused, got %v", err)
+	}
+	// Series already watched cost nothing more.
+	subCtx, unsubscribe := context.WithCancel(ctx)
+	updates := subscribe(t, hub, subCtx, "ETH")
+	if _, err := hub.Subscribe(ctx, []ports.SeriesKey{hubKey("BTC")}); !errors.Is(err, ports.ErrStreamFull) {
+		t.Fatalf("expected a third subscriber to be refused, got %v", err)
+	}
+
+	unsubscribe()
+	for range updates {
+	}
+	subscribe(t, hub, ctx, "BTC")
+}
@@ -0,0 +1,228 @@
+package infra_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/adapters/infra"
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// gatedRepo blocks every call until release is closed (or the call's context ends)
+// and counts the calls that started.
+type gatedRepo struct {
+	release chan struct{}
+	started chan context.Context
+	calls   int32
+	series  domain.CandleSeries
+	err     error
+}
+
+func newGatedRepo() *gatedRepo {
+	return &gatedRepo{release: make(chan struct{}), started: make(chan context.Context, 16), series: buildSampleSeries()}
+}
+
+func (g *gatedRepo) GetSeries(ctx context.Context, sym domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+	atomic.AddInt32(&g.calls, 1)
+	g.started <- ctx
+	select {
+	case <-g.release:
+		return g.series, g.err
+	case <-ctx.Done():
+		return domain.CandleSeries{}, ctx.Err()
+	}
+}
+
+var coalesceFrom = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func coalescedGet(ctx context.Context, repo ports.CandleRepositoryPort, sym string) (domain.CandleSeries, error) {
+	return repo.GetSeries(ctx, domain.NewSymbolUnsafe(sym), domain.Timeframe1m, coalesceFrom, coalesceFrom.Add(time.Minute))
+}
+
+// arrivals counts callers that have joined an in-flight call.
+type arrivals chan struct{}
+
+func newArrivals(n int) arrivals { return make(arrivals, n) }
+
+// ctx returns a context that reports on a when the decorator waits on it, which it does
+// only once the caller is registered as a waiter.
+func (a arrivals) ctx(parent context.Context) context.Context {
+	return arrivingCtx{Context: parent, arrived: a}
+}
+
+// waitForWaiters blocks until n callers have joined.
+func (a arrivals) waitForWaiters(n int) {
+	for i := 0; i < n; i++ {
+		<-a
+	}
+}
+
+type arrivingCtx struct {
+	context.Context
+	arrived chan<- struct{}
+}
+
+func (c arrivingCtx) Done() <-chan struct{} {
+	c.arrived <- struct{}{}
+	return c.Context.Done()
+}
+
+func TestCoalescingCandleRepository_CollapsesConcurrentIdenticalCalls(t *testing.T) {
+	wrapped := newGatedRepo()
+	repo := infra.NewCoalescingCandleRepository(wrapped)
+
+	const n = 10
+	joined := newArrivals(n)
+	var wg sync.WaitGroup
+	results := make([]domain.CandleSeries, n)
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = coalescedGet(joined.ctx(context.Background()), repo, "BTC")
+		}(i)
+	}
+	<-wrapped.started
+	joined.waitForWaiters(n)
+	close(wrapped.release)
+	wg.Wait()
+
+	if wrapped.calls != 1 {
+		t.Fatalf("expected 1 upstream call, got %d", wrapped.calls)
+	}
+	for i := 0; i < n; i++ {
+		if errs[i] != nil || results[i].Len() != 1 {
+			t.Fatalf("waiter %d: expected the shared series, got %v (%d candles)", i, errs[i], results[i].Len())
+		}
+	}
+}
+
+func TestCoalescingCandleRepository_KeepsDistinctRequestsApart(t *testing.T) {
+	wrapped := newGatedRepo()
+	close(wrapped.release)
+	repo := infra.NewCoalescingCandleRepository(wrapped)
+
+	var wg sync.WaitGroup
+	for _, sym := range []string{"BTC", "ETH"} {
+		wg.Add(1)
+		go func(sym string) {
+			defer wg.Done()
+			_, _ = coalescedGet(context.Background(), repo, sym)
+		}(sym)
+	}
+	wg.Wait()
+
+	if wrapped.calls != 2 {
+		t.Fatalf("expected 2 upstream calls, got %d", wrapped.calls)
+	}
+}
+
+func TestCoalescingCandleRepository_DoesNotRetainResults(t *testing.T) {
+	wrapped := newGatedRepo()
+	close(wrapped.release)
+	repo := infra.NewCoalescingCandleRepository(wrapped)
+
+	_, _ = coalescedGet(context.Background(), repo, "BTC")
+	_, _ = coalescedGet(context.Background(), repo, "BTC")
+
+	if wrapped.calls != 2 {
+		t.Fatalf("expected sequential calls to reach the wrapped repository, got %d", wrapped.calls)
+	}
+}
+
+func TestCoalescingCandleRepository_SharesErrors(t *testing.T) {
+	wrapped := newGatedRepo()
+	wrapped.err = ports.ErrRateLimited
+	repo := infra.NewCoalescingCandleRepository(wrapped)
+
+	joined := newArrivals(2)
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := coalescedGet(joined.ctx(context.Background()), repo, "BTC")
+			errs <- err
+		}()
+	}
+	<-wrapped.started
+	joined.waitForWaiters(2)
+	close(wrapped.release)
+
+	for i := 0; i < 2; i++ {
+		if err := <-errs; !errors.Is(err, ports.ErrRateLimited) {
+			t.Fatalf("expected ErrRateLimited, got %v", err)
+		}
+	}
+	if wrapped.calls != 1 {
+		t.Fatalf("expected 1 upstream call, got %d", wrapped.calls)
+	}
+}
+
+func TestCoalescingCandleRepository_CanceledWaiterDoesNotCancelOthers(t *testing.T) {
+	wrapped := newGatedRepo()
+	repo := infra.NewCoalescingCandleRepository(wrapped)
+
+	joined := newArrivals(2)
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := coalescedGet(joined.ctx(ctx), repo, "BTC")
+		first <- err
+	}()
+	upstreamCtx := <-wrapped.started
+
+	second := make(chan error, 1)
+	go func() {
+		_, err := coalescedGet(joined.ctx(context.Background()), repo, "BTC")
+		second <- err
+	}()
+	joined.waitForWaiters(2)
+
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the canceled waiter to get context.Canceled, got %v", err)
+	}
+	if upstreamCtx.Err() != nil {
+		t.Fatal("expected the shared call to keep running for the remaining waiter")
+	}
+
+	close(wrapped.release)
+	if err := <-second; err != nil {
+		t.Fatalf("expected the remaining waiter to get the result, got %v", err)
+	}
+}
+
+func TestCoalescingCandleRepository_CancelsSharedCallWhenAllWaitersLeave(t *testing.T) {
+	wrapped := newGatedRepo()
+	repo := infra.NewCoalescingCandleRepository(wrapped)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := coalescedGet(ctx, repo, "BTC")
+		done <- err
+	}()
+	upstreamCtx := <-wrapped.started
+	cancel()
+	<-done
+
+	select {
+	case <-upstreamCtx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected the shared call to be canceled")
+	}
+
+	// A later identical request starts a fresh upstream call.
+	close(wrapped.release)
+	if _, err := coalescedGet(context.Background(), repo, "BTC"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if wrapped.calls != 2 {
+		t.Fatalf("expected a fresh upstream call, got %d calls", wrapped.calls)
+	}
+}
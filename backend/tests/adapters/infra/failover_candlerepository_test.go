@@ -0,0 +1,184 @@
+package infra_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/adapters/infra"
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+var failoverFrom = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// minuteSeries builds a 1m BTC series with a candle at each given minute offset.
+func minuteSeries(offsets ...int) domain.CandleSeries {
+	sym, tf := domain.NewSymbolUnsafe("BTC"), domain.Timeframe1m
+	candles := make([]domain.Candle, 0, len(offsets))
+	for _, m := range offsets {
+		candles = append(candles, domain.NewCandleUnsafe(sym, tf, failoverFrom.Add(time.Duration(m)*time.Minute), 100, 110, 90, 105, 1000))
+	}
+	series, _ := domain.NewCandleSeries(sym, tf, candles)
+	return series
+}
+
+func failoverGet(ctx context.Context, repo ports.CandleRepositoryPort) (domain.CandleSeries, error) {
+	return repo.GetSeries(ctx, domain.NewSymbolUnsafe("BTC"), domain.Timeframe1m, failoverFrom, failoverFrom.Add(5*time.Minute))
+}
+
+func TestFailoverCandleRepository_ImplementsPort(t *testing.T) {
+	// compile-time check
+	var _ ports.CandleRepositoryPort = infra.NewFailoverCandleRepository(infra.NamedRepository{Name: "a", Repo: &fakeRepo{}})
+	_ = t
+}
+
+func TestFailoverCandleRepository_UsesPrimaryWhenHealthy(t *testing.T) {
+	primary := &fakeRepo{series: minuteSeries(0, 1, 2)}
+	secondary := &fakeRepo{series: minuteSeries(0)}
+	repo := infra.NewFailoverCandleRepository(infra.NamedRepository{Name: "primary", Repo: primary}, infra.NamedRepository{Name: "secondary", Repo: secondary})
+
+	res, err := failoverGet(context.Background(), repo)
+	if err != nil || res.Len() != 3 {
+		t.Fatalf("expected the primary series, got %v (%d candles)", err, res.Len())
+	}
+	if secondary.called {
+		t.Fatal("did not expect the secondary to be consulted")
+	}
+}
+
+func TestFailoverCandleRepository_FailsOverOnErrorOrEmpty(t *testing.T) {
+	failing := &fakeRepo{err: ports.ErrUpstreamUnavailable}
+	empty := &fakeRepo{series: minuteSeries()}
+	healthy := &fakeRepo{series: minuteSeries(0, 1)}
+	repo := infra.NewFailoverCandleRepository(
+		infra.NamedRepository{Name: "failing", Repo: failing},
+		infra.NamedRepository{Name: "empty", Repo: empty},
+		infra.NamedRepository{Name: "healthy", Repo: healthy},
+	)
+
+	ctx, provenance := ports.WithProvenance(context.Background())
+	res, err := failoverGet(ctx, repo)
+	if err != nil || res.Len() != 2 {
+		t.Fatalf("expected the healthy series, got %v (%d candles)", err, res.Len())
+	}
+	ranges := provenance()
+	if len(ranges) != 1 || ranges[0].Source != "healthy" || !ranges[0].To.Equal(failoverFrom.Add(2*time.Minute)) {
+		t.Fatalf("unexpected provenance: %+v", ranges)
+	}
+}
+
+func TestFailoverCandleRepository_JoinsErrorsWhenAllFail(t *testing.T) {
+	repo := infra.NewFailoverCandleRepository(
+		infra.NamedRepository{Name: "a", Repo: &fakeRepo{err: ports.ErrUpstreamUnavailable}},
+		infra.NamedRepository{Name: "b", Repo: &fakeRepo{err: ports.ErrRateLimited}},
+	)
+
+	_, err := failoverGet(context.Background(), repo)
+	if !errors.Is(err, ports.ErrUpstreamUnavailable) || !errors.Is(err, ports.ErrRateLimited) {
+		t.Fatalf("expected both provider errors, got %v", err)
+	}
+}
+
+func TestFailoverCandleRepository_ReturnsEmptyWhenNoProviderHasData(t *testing.T) {
+	repo := infra.NewFailoverCandleRepository(
+		infra.NamedRepository{Name: "a", Repo: &fakeRepo{series: minuteSeries()}},
+		infra.NamedRepository{Name: "b", Repo: &fakeRepo{series: minuteSeries()}},
+	)
+
+	res, err := failoverGet(context.Background(), repo)
+	if err != nil || res.Len() != 0 {
+		t.Fatalf("expected an empty series, got %v (%d candles)", err, res.Len())
+	}
+}
+
+func TestFailoverCandleRepository_ReturnsErrorsWhenPrimaryFailsAndBackupIsEmpty(t *testing.T) {
+	repo := infra.NewFailoverCandleRepository(
+		infra.NamedRepository{Name: "primary", Repo: &fakeRepo{err: ports.ErrUpstreamUnavailable}},
+		infra.NamedRepository{Name: "backup", Repo: &fakeRepo{series: minuteSeries()}},
+	)
+
+	_, err := failoverGet(context.Background(), repo)
+	if !errors.Is(err, ports.ErrUpstreamUnavailable) {
+		t.Fatalf("expected the primary's error, got %v", err)
+	}
+}
+
+func TestFailoverCandleRepository_MergesGapsFromLaterProviders(t *testing.T) {
+	primary := &fakeRepo{series: minuteSeries(0, 1, 4)}
+	secondary := &fakeRepo{series: minuteSeries(1, 2, 3)}
+	repo := infra.NewFailoverCandleRepository(
+		infra.NamedRepository{Name: "primary", Repo: primary},
+		infra.NamedRepository{Name: "secondary", Repo: secondary},
+	).WithMerge(true)
+
+	ctx, provenance := ports.WithProvenance(context.Background())
+	res, err := failoverGet(ctx, repo)
+	if err != nil || res.Len() != 5 {
+		t.Fatalf("expected the gap to be filled, got %v (%d candles)", err, res.Len())
+	}
+	if !secondary.lastFrom.Equal(failoverFrom.Add(2*time.Minute)) || !secondary.lastTo.Equal(failoverFrom.Add(4*time.Minute)) {
+		t.Fatalf("expected the secondary to be asked for the gap only, got [%v, %v)", secondary.lastFrom, secondary.lastTo)
+	}
+	want := []string{"primary", "secondary", "primary"}
+	ranges := provenance()
+	if len(ranges) != len(want) {
+		t.Fatalf("unexpected provenance: %+v", ranges)
+	}
+	for i, r := range ranges {
+		if r.Source != want[i] {
+			t.Fatalf("range %d: expected %s, got %+v", i, want[i], r)
+		}
+	}
+}
+
+func TestFailoverCandleRepository_MergesMissingLeadingCandles(t *testing.T) {
+	primary := &fakeRepo{series: minuteSeries(2, 3, 4)}
+	secondary := &fakeRepo{series: minuteSeries(0, 1)}
+	repo := infra.NewFailoverCandleRepository(
+		infra.NamedRepository{Name: "primary", Repo: primary},
+		infra.NamedRepository{Name: "secondary", Repo: secondary},
+	).WithMerge(true)
+
+	res, err := failoverGet(context.Background(), repo)
+	if err != nil || res.Len() != 5 {
+		t.Fatalf("expected the leading edge to be filled, got %v (%d candles)", err, res.Len())
+	}
+	if !secondary.lastFrom.Equal(failoverFrom) || !secondary.lastTo.Equal(failoverFrom.Add(2*time.Minute)) {
+		t.Fatalf("expected the secondary to be asked for [12:00, 12:02), got [%v, %v)", secondary.lastFrom, secondary.lastTo)
+	}
+}
+
+func TestFailoverCandleRepository_MergesMissingTrailingCandles(t *testing.T) {
+	primary := &fakeRepo{series: minuteSeries(0, 1, 2)}
+	secondary := &fakeRepo{series: minuteSeries(3, 4)}
+	repo := infra.NewFailoverCandleRepository(
+		infra.NamedRepository{Name: "primary", Repo: primary},
+		infra.NamedRepository{Name: "secondary", Repo: secondary},
+	).WithMerge(true)
+
+	res, err := failoverGet(context.Background(), repo)
+	if err != nil || res.Len() != 5 {
+		t.Fatalf("expected the truncated tail to be filled, got %v (%d candles)", err, res.Len())
+	}
+	if !secondary.lastFrom.Equal(failoverFrom.Add(3*time.Minute)) || !secondary.lastTo.Equal(failoverFrom.Add(5*time.Minute)) {
+		t.Fatalf("expected the secondary to be asked for [12:03, 12:05), got [%v, %v)", secondary.lastFrom, secondary.lastTo)
+	}
+}
+
+func TestFailoverCandleRepository_DoesNotMergeCandlesThatHaveNotOpened(t *testing.T) {
+	primary := &fakeRepo{series: minuteSeries(0, 1, 2, 3)}
+	secondary := &fakeRepo{series: minuteSeries(4)}
+	repo := infra.NewFailoverCandleRepository(
+		infra.NamedRepository{Name: "primary", Repo: primary},
+		infra.NamedRepository{Name: "secondary", Repo: secondary},
+	).WithMerge(true).WithClock(func() time.Time { return failoverFrom.Add(3*time.Minute + 30*time.Second) })
+
+	if _, err := failoverGet(context.Background(), repo); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if secondary.called {
+		t.Fatal("did not expect the secondary to be asked for the future")
+	}
+}
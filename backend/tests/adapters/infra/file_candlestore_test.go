@@ -0,0 +1,210 @@
+package infra_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/adapters/infra"
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+func newTestStore(t *testing.T, dir string) *infra.FileCandleStore {
+	t.Helper()
+	store, err := infra.NewFileCandleStore(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return store
+}
+
+func storeGet(t *testing.T, store ports.CandleStorePort, from, to int) domain.CandleSeries {
+	t.Helper()
+	series, err := store.GetSeries(context.Background(), domain.NewSymbolUnsafe("BTC"), domain.Timeframe1m, failoverFrom.Add(time.Duration(from)*time.Minute), failoverFrom.Add(time.Duration(to)*time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return series
+}
+
+func minuteRange(from, to int) ports.TimeRange {
+	return ports.TimeRange{From: failoverFrom.Add(time.Duration(from) * time.Minute), To: failoverFrom.Add(time.Duration(to) * time.Minute)}
+}
+
+func TestFileCandleStore_ImplementsPort(t *testing.T) {
+	// compile-time check
+	var _ ports.CandleStorePort = newTestStore(t, t.TempDir())
+}
+
+func TestFileCandleStore_UpsertReplacesCandles(t *testing.T) {
+	store := newTestStore(t, t.TempDir())
+	ctx := context.Background()
+
+	if err := store.Upsert(ctx, minuteSeries(0, 1, 2), minuteRange(0, 3)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sym, tf := domain.NewSymbolUnsafe("BTC"), domain.Timeframe1m
+	revised, _ := domain.NewCandleSeries(sym, tf, []domain.Candle{domain.NewCandleUnsafe(sym, tf, failoverFrom.Add(time.Minute), 100, 120, 90, 115, 2000)})
+	if err := store.Upsert(ctx, revised, ports.TimeRange{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	series := storeGet(t, store, 0, 3)
+	if series.Len() != 3 {
+		t.Fatalf("expected 3 candles, got %d", series.Len())
+	}
+	if c, _ := series.At(1); c.Close() != 115 {
+		t.Fatalf("expected the later write to win, got close %v", c.Close())
+	}
+	if got := storeGet(t, store, 1, 2); got.Len() != 1 {
+		t.Fatalf("expected the range to be honored, got %d candles", got.Len())
+	}
+}
+
+func TestFileCandleStore_MergesAndClipsCoveredRanges(t *testing.T) {
+	store := newTestStore(t, t.TempDir())
+	ctx := context.Background()
+	sym, tf := domain.NewSymbolUnsafe("BTC"), domain.Timeframe1m
+
+	for _, r := range []ports.TimeRange{minuteRange(0, 2), minuteRange(5, 7), minuteRange(2, 3)} {
+		if err := store.Upsert(ctx, minuteSeries(), r); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	covered, err := store.Covered(ctx, sym, tf, failoverFrom.Add(time.Minute), failoverFrom.Add(6*time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []ports.TimeRange{minuteRange(1, 3), minuteRange(5, 6)}
+	if len(covered) != len(want) {
+		t.Fatalf("expected %v, got %v", want, covered)
+	}
+	for i := range want {
+		if !covered[i].From.Equal(want[i].From) || !covered[i].To.Equal(want[i].To) {
+			t.Fatalf("expected %v, got %v", want, covered)
+		}
+	}
+}
+
+func TestFileCandleStore_SurvivesReopenAcrossSegments(t *testing.T) {
+	dir := t.TempDir()
+	store := newTestStore(t, dir).WithSegmentRecords(2)
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		if err := store.Upsert(ctx, minuteSeries(i), minuteRange(i, i+1)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	reopened := newTestStore(t, dir)
+	if got := storeGet(t, reopened, 0, 4); got.Len() != 4 {
+		t.Fatalf("expected 4 candles after reopening, got %d", got.Len())
+	}
+	segments, _ := filepath.Glob(filepath.Join(dir, "BTC", "1m", "seg-*.log"))
+	if len(segments) < 2 {
+		t.Fatalf("expected several segments, got %v", segments)
+	}
+}
+
+func TestFileCandleStore_SplitsLargeWritesAcrossSegments(t *testing.T) {
+	dir := t.TempDir()
+	store := newTestStore(t, dir).WithSegmentRecords(4)
+	if err := store.Upsert(context.Background(), minuteSeries(0, 1, 2, 3, 4, 5, 6, 7, 8, 9), minuteRange(0, 10)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	segments, _ := filepath.Glob(filepath.Join(dir, "BTC", "1m", "seg-*.log"))
+	if len(segments) != 3 {
+		t.Fatalf("expected 11 records in 3 segments, got %v", segments)
+	}
+	for _, name := range segments {
+		b, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n := strings.Count(string(b), "\n"); n > 4 {
+			t.Fatalf("expected at most 4 records in %s, got %d", filepath.Base(name), n)
+		}
+	}
+	if got := storeGet(t, newTestStore(t, dir), 0, 10); got.Len() != 10 {
+		t.Fatalf("expected 10 candles after reopening, got %d", got.Len())
+	}
+}
+
+func TestFileCandleStore_RebuildsIndexAndDropsTornRecord(t *testing.T) {
+	dir := t.TempDir()
+	store := newTestStore(t, dir)
+	ctx := context.Background()
+	if err := store.Upsert(ctx, minuteSeries(0, 1), minuteRange(0, 2)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Simulate a crash during the next write: a partial record and no index update.
+	seriesDir := filepath.Join(dir, "BTC", "1m")
+	f, err := os.OpenFile(filepath.Join(seriesDir, "seg-000001.log"), os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, _ = f.WriteString(`{"k":"c","t":`)
+	_ = f.Close()
+	_ = os.Remove(filepath.Join(seriesDir, "index.json"))
+
+	reopened := newTestStore(t, dir)
+	if got := storeGet(t, reopened, 0, 5); got.Len() != 2 {
+		t.Fatalf("expected 2 candles after recovery, got %d", got.Len())
+	}
+	covered, _ := reopened.Covered(ctx, domain.NewSymbolUnsafe("BTC"), domain.Timeframe1m, failoverFrom, failoverFrom.Add(5*time.Minute))
+	if len(covered) != 1 || !covered[0].To.Equal(failoverFrom.Add(2*time.Minute)) {
+		t.Fatalf("expected coverage to be rebuilt, got %v", covered)
+	}
+	if err := reopened.Upsert(ctx, minuteSeries(2), minuteRange(2, 3)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := storeGet(t, newTestStore(t, dir), 0, 5); got.Len() != 3 {
+		t.Fatalf("expected writes after recovery to be readable, got %d candles", got.Len())
+	}
+}
+
+func TestFileCandleStore_ServesConcurrentWritesAndReads(t *testing.T) {
+	store := newTestStore(t, t.TempDir()).WithSegmentRecords(7)
+	ctx := context.Background()
+	sym, tf := domain.NewSymbolUnsafe("BTC"), domain.Timeframe1m
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			if err := store.Upsert(ctx, minuteSeries(i), minuteRange(i, i+1)); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			if _, err := store.GetSeries(ctx, sym, tf, failoverFrom, failoverFrom.Add(time.Hour)); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if _, err := store.Covered(ctx, sym, tf, failoverFrom, failoverFrom.Add(time.Hour)); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := storeGet(t, store, 0, 60); got.Len() != 50 {
+		t.Fatalf("expected every written candle, got %d", got.Len())
+	}
+	covered, err := store.Covered(ctx, sym, tf, failoverFrom, failoverFrom.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(covered) != 1 || !covered[0].To.Equal(failoverFrom.Add(50*time.Minute)) {
+		t.Fatalf("expected one merged range of 50 minutes, got %v", covered)
+	}
+}
@@ -0,0 +1,41 @@
+package infra_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/adapters/infra"
+	"github.com/akarso/pano_chart/backend/application/ports"
+)
+
+func TestFileCheckpointStore_ImplementsPort(t *testing.T) {
+	// compile-time check
+	var _ ports.CheckpointPort = &infra.FileCheckpointStore{}
+	_ = t
+}
+
+func TestFileCheckpointStore_PersistsAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoints.json")
+	ctx := context.Background()
+	store, err := infra.NewFileCheckpointStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok, _ := store.Load(ctx, "job"); ok {
+		t.Fatal("expected no checkpoint in a new store")
+	}
+	progress := time.Date(2025, 1, 1, 4, 0, 0, 0, time.UTC)
+	if err := store.Save(ctx, "job", progress); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reopened, err := infra.NewFileCheckpointStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, ok, err := reopened.Load(ctx, "job"); err != nil || !ok || !got.Equal(progress) {
+		t.Fatalf("expected %v after reopening, got %v, %v, %v", progress, got, ok, err)
+	}
+}
@@ -1,7 +1,9 @@
 package infra_test
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -50,7 +52,7 @@ func TestFreeTierCandleRepository_MapsValidResponseToCandleSeries(t *testing.T)
 	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
 	to := from.Add(1 * time.Minute)
 
-	series, err := repo.GetSeries(sym, tf, from, to)
+	series, err := repo.GetSeries(context.Background(), sym, tf, from, to)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -80,7 +82,7 @@ func TestFreeTierCandleRepository_ReturnsErrorOnHTTPFailure(t *testing.T) {
 	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
 	to := from.Add(1 * time.Minute)
 
-	_, err := repo.GetSeries(sym, tf, from, to)
+	_, err := repo.GetSeries(context.Background(), sym, tf, from, to)
 	if err == nil {
 		t.Fatal("expected error for HTTP failure")
 	}
@@ -100,8 +102,167 @@ func TestFreeTierCandleRepository_ReturnsErrorOnInvalidPayload(t *testing.T) {
 	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
 	to := from.Add(1 * time.Minute)
 
-	_, err := repo.GetSeries(sym, tf, from, to)
+	_, err := repo.GetSeries(context.Background(), sym, tf, from, to)
 	if err == nil {
 		t.Fatal("expected error for invalid payload")
 	}
 }
+
+func TestFreeTierCandleRepository_MapsRateLimitStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	repo := infra.NewFreeTierCandleRepository(server.URL, server.Client())
+
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	_, err := repo.GetSeries(context.Background(), domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), from, from.Add(time.Minute))
+	if !errors.Is(err, ports.ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+	var se *ports.UpstreamStatusError
+	if !errors.As(err, &se) || se.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected UpstreamStatusError with status 429, got %v", err)
+	}
+}
+
+func TestFreeTierCandleRepository_ReadsRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	repo := infra.NewFreeTierCandleRepository(server.URL, server.Client())
+
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	_, err := repo.GetSeries(context.Background(), domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), from, from.Add(time.Minute))
+	if got := ports.RetryAfter(err); got != 30*time.Second {
+		t.Fatalf("expected a 30s retry delay, got %v (%v)", got, err)
+	}
+}
+
+func TestFreeTierCandleRepository_WrapsTransportErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	repo := infra.NewFreeTierCandleRepository(url, http.DefaultClient)
+
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	_, err := repo.GetSeries(context.Background(), domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), from, from.Add(time.Minute))
+	if !errors.Is(err, ports.ErrUpstreamUnavailable) {
+		t.Fatalf("expected ErrUpstreamUnavailable, got %v", err)
+	}
+}
+
+func TestFreeTierCandleRepository_HonorsContextDeadline(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	repo := infra.NewFreeTierCandleRepository(server.URL, server.Client())
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	start := time.Now()
+	_, err := repo.GetSeries(ctx, domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), from, from.Add(time.Minute))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if errors.Is(err, ports.ErrUpstreamUnavailable) {
+		t.Fatalf("did not expect a deadline to be reported as an upstream failure: %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatal("expected the request to be aborted at the deadline")
+	}
+}
+
+// newDirtyServer serves one valid candle, one with high below close, one with a
+// misaligned timestamp and one with an unreadable timestamp.
+func newDirtyServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		items := []sampleResponseItem{
+			{Timestamp: "2026-01-01T12:00:00Z", Open: 100, High: 110, Low: 90, Close: 105, Volume: 1000},
+			{Timestamp: "2026-01-01T12:01:00Z", Open: 100, High: 104, Low: 90, Close: 105, Volume: 1000},
+			{Timestamp: "2026-01-01T12:02:30Z", Open: 100, High: 110, Low: 90, Close: 105, Volume: 1000},
+			{Timestamp: "yesterday", Open: 100, High: 110, Low: 90, Close: 105, Volume: 1000},
+		}
+		_ = json.NewEncoder(w).Encode(items)
+	}))
+}
+
+func TestFreeTierCandleRepository_StrictPolicyFailsOnInvalidCandle(t *testing.T) {
+	server := newDirtyServer()
+	defer server.Close()
+
+	repo := infra.NewFreeTierCandleRepository(server.URL, server.Client())
+
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	_, err := repo.GetSeries(context.Background(), domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), from, from.Add(5*time.Minute))
+	if !errors.Is(err, domain.ErrInvalidCandle) {
+		t.Fatalf("expected ErrInvalidCandle, got %v", err)
+	}
+}
+
+func TestFreeTierCandleRepository_SkipPolicyReportsDroppedCandles(t *testing.T) {
+	server := newDirtyServer()
+	defer server.Close()
+
+	repo := infra.NewFreeTierCandleRepository(server.URL, server.Client()).WithIngestPolicy(infra.IngestSkipInvalid)
+
+	ctx, issues := ports.WithQualityReport(context.Background())
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	series, err := repo.GetSeries(ctx, domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), from, from.Add(5*time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if series.Len() != 1 {
+		t.Fatalf("expected only the valid candle, got %d", series.Len())
+	}
+	got := issues()
+	if len(got) != 3 {
+		t.Fatalf("expected 3 issues, got %+v", got)
+	}
+	for _, issue := range got {
+		if issue.Repaired || issue.Reason == "" {
+			t.Fatalf("expected a dropped candle with a reason, got %+v", issue)
+		}
+	}
+}
+
+func TestFreeTierCandleRepository_RepairPolicyFixesCandles(t *testing.T) {
+	server := newDirtyServer()
+	defer server.Close()
+
+	repo := infra.NewFreeTierCandleRepository(server.URL, server.Client()).WithIngestPolicy(infra.IngestRepair)
+
+	ctx, issues := ports.WithQualityReport(context.Background())
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	series, err := repo.GetSeries(ctx, domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), from, from.Add(5*time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if series.Len() != 3 {
+		t.Fatalf("expected 3 candles, got %d", series.Len())
+	}
+	if c, _ := series.At(1); c.High() != 105 {
+		t.Fatalf("expected high to be clamped to close, got %v", c.High())
+	}
+	if c, _ := series.At(2); !c.Timestamp().Equal(from.Add(2 * time.Minute)) {
+		t.Fatalf("expected the timestamp to be snapped to 12:02, got %v", c.Timestamp())
+	}
+
+	got := issues()
+	if len(got) != 3 || !got[0].Repaired || !got[1].Repaired || got[2].Repaired {
+		t.Fatalf("expected two repaired and one dropped candle, got %+v", got)
+	}
+}
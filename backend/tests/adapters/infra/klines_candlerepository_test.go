@@ -0,0 +1,122 @@
+package infra_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/adapters/infra"
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// klinesServer serves one 1m kline per minute in the requested window, honoring limit,
+// and records the query of every request.
+type klinesServer struct {
+	mu      sync.Mutex
+	queries []map[string]string
+}
+
+func (k *klinesServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	k.mu.Lock()
+	k.queries = append(k.queries, map[string]string{"symbol": q.Get("symbol"), "interval": q.Get("interval"), "startTime": q.Get("startTime")})
+	k.mu.Unlock()
+
+	start, _ := strconv.ParseInt(q.Get("startTime"), 10, 64)
+	end, _ := strconv.ParseInt(q.Get("endTime"), 10, 64)
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	rows := [][]interface{}{}
+	for t := start; t <= end && len(rows) < limit; t += time.Minute.Milliseconds() {
+		rows = append(rows, []interface{}{t, "100.5", "110.0", "90.25", "105.0", "1000.123", t + time.Minute.Milliseconds() - 1, "0", 10, "0", "0", "0"})
+	}
+	_ = json.NewEncoder(w).Encode(rows)
+}
+
+var klinesFrom = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func TestKlinesCandleRepository_ImplementsPort(t *testing.T) {
+	// compile-time check
+	var _ ports.CandleRepositoryPort = infra.NewKlinesCandleRepository("", http.DefaultClient)
+	_ = t
+}
+
+func TestKlinesCandleRepository_MapsRowsToCandles(t *testing.T) {
+	stub := &klinesServer{}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	repo := infra.NewKlinesCandleRepository(server.URL, server.Client())
+	res, err := repo.GetSeries(context.Background(), domain.NewSymbolUnsafe("BTC-USDT"), domain.Timeframe1m, klinesFrom, klinesFrom.Add(3*time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Len() != 3 {
+		t.Fatalf("expected 3 candles, got %d", res.Len())
+	}
+	c, _ := res.First()
+	if !c.Timestamp().Equal(klinesFrom) || c.Open() != 100.5 || c.Low() != 90.25 || c.Volume() != 1000.123 {
+		t.Fatalf("unexpected candle: %v %v %v %v", c.Timestamp(), c.Open(), c.Low(), c.Volume())
+	}
+	if q := stub.queries[0]; q["symbol"] != "BTCUSDT" || q["interval"] != "1m" {
+		t.Fatalf("unexpected query: %v", q)
+	}
+}
+
+func TestKlinesCandleRepository_PaginatesByPageLimit(t *testing.T) {
+	stub := &klinesServer{}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	repo := infra.NewKlinesCandleRepository(server.URL, server.Client()).WithPageLimit(4)
+	res, err := repo.GetSeries(context.Background(), domain.NewSymbolUnsafe("BTCUSDT"), domain.Timeframe1m, klinesFrom, klinesFrom.Add(10*time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Len() != 10 {
+		t.Fatalf("expected 10 candles, got %d", res.Len())
+	}
+	if len(stub.queries) != 3 {
+		t.Fatalf("expected 3 pages, got %d", len(stub.queries))
+	}
+	if want := strconv.FormatInt(klinesFrom.Add(4*time.Minute).UnixMilli(), 10); stub.queries[1]["startTime"] != want {
+		t.Fatalf("expected the second page to start after the first, got %s", stub.queries[1]["startTime"])
+	}
+	for i := 0; i < res.Len()-1; i++ {
+		if res.HasGapAfter(i) {
+			t.Fatalf("unexpected gap after candle %d", i)
+		}
+	}
+}
+
+func TestKlinesCandleRepository_MapsRateLimitStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "5")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	repo := infra.NewKlinesCandleRepository(server.URL, server.Client())
+	_, err := repo.GetSeries(context.Background(), domain.NewSymbolUnsafe("BTCUSDT"), domain.Timeframe1m, klinesFrom, klinesFrom.Add(time.Minute))
+	if !errors.Is(err, ports.ErrRateLimited) || ports.RetryAfter(err) != 5*time.Second {
+		t.Fatalf("expected ErrRateLimited with a 5s delay, got %v", err)
+	}
+}
+
+func TestKlinesCandleRepository_RejectsMalformedRows(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[[1767268800000, "abc", "1", "1", "1", "1"]]`))
+	}))
+	defer server.Close()
+
+	repo := infra.NewKlinesCandleRepository(server.URL, server.Client())
+	if _, err := repo.GetSeries(context.Background(), domain.NewSymbolUnsafe("BTCUSDT"), domain.Timeframe1m, klinesFrom, klinesFrom.Add(time.Minute)); err == nil {
+		t.Fatal("expected an error for a malformed decimal")
+	}
+}
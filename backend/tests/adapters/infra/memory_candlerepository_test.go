@@ -0,0 +1,163 @@
+package infra_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/adapters/infra"
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+var memoryFrom = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func memoryGet(repo ports.CandleRepositoryPort, sym string) (domain.CandleSeries, error) {
+	return repo.GetSeries(context.Background(), domain.NewSymbolUnsafe(sym), domain.NewTimeframeUnsafe("1m"), memoryFrom, memoryFrom.Add(time.Minute))
+}
+
+// perSymbolRepo returns a one-candle series for whichever symbol is requested.
+type perSymbolRepo struct {
+	calls int
+}
+
+func (p *perSymbolRepo) GetSeries(_ context.Context, sym domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+	p.calls++
+	c := domain.NewCandleUnsafe(sym, tf, from, 100, 110, 90, 105, 1000)
+	return domain.NewCandleSeries(sym, tf, []domain.Candle{c})
+}
+
+func TestMemoryCandleRepository_ImplementsPort(t *testing.T) {
+	// compile-time check
+	var _ ports.CandleRepositoryPort = infra.NewMemoryCandleRepository(&fakeRepo{}, 1, time.Minute)
+	_ = t
+}
+
+func TestMemoryCandleRepository_ServesRepeatedRequestsFromMemory(t *testing.T) {
+	wrapped := &fakeRepo{series: buildSampleSeries()}
+	repo := infra.NewMemoryCandleRepository(wrapped, 100, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		res, err := memoryGet(repo, "BTC")
+		if err != nil || res.Len() != 1 {
+			t.Fatalf("expected the cached series, got %v (%d candles)", err, res.Len())
+		}
+	}
+	if wrapped.calls != 1 {
+		t.Fatalf("expected 1 upstream call, got %d", wrapped.calls)
+	}
+	stats := repo.Stats()
+	if stats.Hits != 2 || stats.Misses != 1 || stats.Entries != 1 || stats.Candles != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestMemoryCandleRepository_ExpiresEntriesAfterTTL(t *testing.T) {
+	wrapped := &fakeRepo{series: buildSampleSeries()}
+	now := memoryFrom
+	repo := infra.NewMemoryCandleRepository(wrapped, 100, time.Minute).WithClock(func() time.Time { return now })
+
+	_, _ = memoryGet(repo, "BTC")
+	now = now.Add(time.Minute)
+	_, _ = memoryGet(repo, "BTC")
+
+	if wrapped.calls != 2 {
+		t.Fatalf("expected the expired entry to be refetched, got %d calls", wrapped.calls)
+	}
+}
+
+func TestMemoryCandleRepository_EvictsLeastRecentlyUsed(t *testing.T) {
+	wrapped := &perSymbolRepo{}
+	repo := infra.NewMemoryCandleRepository(wrapped, 2, time.Minute)
+
+	_, _ = memoryGet(repo, "BTC")
+	_, _ = memoryGet(repo, "ETH")
+	_, _ = memoryGet(repo, "BTC") // BTC is now the most recently used
+	_, _ = memoryGet(repo, "SOL") // evicts ETH
+	_, _ = memoryGet(repo, "BTC")
+
+	if wrapped.calls != 3 {
+		t.Fatalf("expected BTC to survive eviction, got %d upstream calls", wrapped.calls)
+	}
+	_, _ = memoryGet(repo, "ETH")
+	if wrapped.calls != 4 {
+		t.Fatalf("expected ETH to have been evicted, got %d upstream calls", wrapped.calls)
+	}
+	if stats := repo.Stats(); stats.Evictions != 2 || stats.Candles != 2 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestMemoryCandleRepository_DoesNotCacheErrors(t *testing.T) {
+	wrapped := &fakeRepo{err: ports.ErrUpstreamUnavailable}
+	repo := infra.NewMemoryCandleRepository(wrapped, 100, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		if _, err := memoryGet(repo, "BTC"); !errors.Is(err, ports.ErrUpstreamUnavailable) {
+			t.Fatalf("expected the upstream error, got %v", err)
+		}
+	}
+	if wrapped.calls != 2 || repo.Stats().Entries != 0 {
+		t.Fatalf("expected errors to pass through uncached, got %d calls", wrapped.calls)
+	}
+}
+
+// staleRepo serves a fixed series and marks it stale.
+type staleRepo struct {
+	fakeRepo
+}
+
+func (s *staleRepo) GetSeries(ctx context.Context, sym domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+	ports.MarkStale(ctx)
+	return s.fakeRepo.GetSeries(ctx, sym, tf, from, to)
+}
+
+func TestMemoryCandleRepository_DoesNotCacheStaleSeries(t *testing.T) {
+	wrapped := &staleRepo{fakeRepo{series: buildSampleSeries()}}
+	repo := infra.NewMemoryCandleRepository(wrapped, 100, time.Minute)
+
+	ctx, stale := ports.WithStaleTracking(context.Background())
+	if _, err := repo.GetSeries(ctx, domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), memoryFrom, memoryFrom.Add(time.Minute)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !stale() {
+		t.Fatal("expected the staleness to reach the caller")
+	}
+	if repo.Stats().Entries != 0 {
+		t.Fatal("expected the stale series not to be cached")
+	}
+}
+
+// reportingRepo serves a fixed series and reports its provenance and a repaired candle.
+type reportingRepo struct {
+	fakeRepo
+}
+
+func (r *reportingRepo) GetSeries(ctx context.Context, sym domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+	ports.RecordProvenance(ctx, ports.SourceRange{Source: "backup", From: from, To: to})
+	ports.RecordCandleIssues(ctx, ports.CandleIssue{Symbol: sym, Timeframe: tf, Timestamp: from, Reason: "high below close", Repaired: true})
+	return r.fakeRepo.GetSeries(ctx, sym, tf, from, to)
+}
+
+func TestMemoryCandleRepository_ReplaysReportsOnHits(t *testing.T) {
+	wrapped := &reportingRepo{fakeRepo{series: buildSampleSeries()}}
+	repo := infra.NewMemoryCandleRepository(wrapped, 100, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		ctx, provenance := ports.WithProvenance(context.Background())
+		ctx, quality := ports.WithQualityReport(ctx)
+		if _, err := repo.GetSeries(ctx, domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), memoryFrom, memoryFrom.Add(time.Minute)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ranges := provenance(); len(ranges) != 1 || ranges[0].Source != "backup" {
+			t.Fatalf("request %d: expected the backup's provenance, got %+v", i, ranges)
+		}
+		if issues := quality(); len(issues) != 1 || !issues[0].Repaired {
+			t.Fatalf("request %d: expected the repaired candle to be reported, got %+v", i, issues)
+		}
+	}
+	if wrapped.calls != 1 {
+		t.Fatalf("expected the second request to be a hit, got %d upstream calls", wrapped.calls)
+	}
+}
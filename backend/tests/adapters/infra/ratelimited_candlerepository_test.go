@@ -0,0 +1,142 @@
+package infra_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/adapters/infra"
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+var rateLimitFrom = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func rateLimitedGet(ctx context.Context, repo ports.CandleRepositoryPort) error {
+	_, err := repo.GetSeries(ctx, domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), rateLimitFrom, rateLimitFrom.Add(time.Minute))
+	return err
+}
+
+func TestRateLimitedCandleRepository_ImplementsPort(t *testing.T) {
+	// compile-time check
+	var _ ports.CandleRepositoryPort = infra.NewRateLimitedCandleRepository(&fakeRepo{}, infra.RateLimit{})
+	_ = t
+}
+
+func TestRateLimitedCandleRepository_RejectsWhenBudgetIsExhausted(t *testing.T) {
+	wrapped := &fakeRepo{series: buildSampleSeries()}
+	now := rateLimitFrom
+	repo := infra.NewRateLimitedCandleRepository(wrapped, infra.RateLimit{PerMinute: 2}).WithClock(func() time.Time { return now })
+
+	for i := 0; i < 2; i++ {
+		if err := rateLimitedGet(context.Background(), repo); err != nil {
+			t.Fatalf("call %d: unexpected error: %v", i, err)
+		}
+	}
+	err := rateLimitedGet(context.Background(), repo)
+	var rle *ports.RateLimitError
+	if !errors.As(err, &rle) || !errors.Is(err, ports.ErrRateLimited) {
+		t.Fatalf("expected a RateLimitError, got %v", err)
+	}
+	if rle.RetryAfter != time.Minute {
+		t.Fatalf("expected to retry once the first call leaves the window, got %v", rle.RetryAfter)
+	}
+	if wrapped.calls != 2 {
+		t.Fatalf("expected the rejected call not to reach the provider, got %d calls", wrapped.calls)
+	}
+
+	now = now.Add(time.Minute)
+	if err := rateLimitedGet(context.Background(), repo); err != nil {
+		t.Fatalf("expected the window to allow a call again, got %v", err)
+	}
+}
+
+func TestRateLimitedCandleRepository_EnforcesDailyQuota(t *testing.T) {
+	wrapped := &fakeRepo{series: buildSampleSeries()}
+	now := rateLimitFrom
+	repo := infra.NewRateLimitedCandleRepository(wrapped, infra.RateLimit{PerMinute: 10, PerDay: 3}).WithClock(func() time.Time { return now })
+
+	for i := 0; i < 3; i++ {
+		_ = rateLimitedGet(context.Background(), repo)
+		now = now.Add(time.Minute)
+	}
+	if err := rateLimitedGet(context.Background(), repo); !errors.Is(err, ports.ErrRateLimited) {
+		t.Fatalf("expected the daily quota to be exhausted, got %v", err)
+	}
+}
+
+func TestRateLimitedCandleRepository_HonorsProviderRetryAfter(t *testing.T) {
+	wrapped := &fakeRepo{err: &ports.UpstreamStatusError{StatusCode: 429, RetryAfter: time.Minute}}
+	now := rateLimitFrom
+	repo := infra.NewRateLimitedCandleRepository(wrapped, infra.RateLimit{PerMinute: 100}).WithClock(func() time.Time { return now })
+
+	_ = rateLimitedGet(context.Background(), repo)
+	wrapped.err = nil
+	if err := rateLimitedGet(context.Background(), repo); ports.RetryAfter(err) != time.Minute {
+		t.Fatalf("expected calls to be held back for the provider's delay, got %v", err)
+	}
+	now = now.Add(time.Minute)
+	if err := rateLimitedGet(context.Background(), repo); err != nil {
+		t.Fatalf("expected calls to resume after the delay, got %v", err)
+	}
+}
+
+func TestRateLimitedCandleRepository_QueuesWithinMaxWait(t *testing.T) {
+	wrapped := &fakeRepo{err: &ports.UpstreamStatusError{StatusCode: 429, RetryAfter: 20 * time.Millisecond}}
+	repo := infra.NewRateLimitedCandleRepository(wrapped, infra.RateLimit{PerMinute: 100, MaxWait: time.Second})
+
+	_ = rateLimitedGet(context.Background(), repo)
+	wrapped.err = nil
+	start := time.Now()
+	if err := rateLimitedGet(context.Background(), repo); err != nil {
+		t.Fatalf("expected the call to be queued, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Fatalf("expected the call to wait for the provider's delay, took %v", elapsed)
+	}
+}
+
+func TestRateLimitedCandleRepository_StopsWaitingOnCancel(t *testing.T) {
+	wrapped := &fakeRepo{err: &ports.UpstreamStatusError{StatusCode: 429, RetryAfter: time.Minute}}
+	repo := infra.NewRateLimitedCandleRepository(wrapped, infra.RateLimit{PerMinute: 100, MaxWait: time.Hour})
+
+	_ = rateLimitedGet(context.Background(), repo)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := rateLimitedGet(ctx, repo); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the wait to end with the context, got %v", err)
+	}
+	if wrapped.calls != 1 {
+		t.Fatalf("expected the abandoned call not to reach the provider, got %d calls", wrapped.calls)
+	}
+}
+
+func TestRateLimitedCandleRepository_NeverExceedsTheQuotaInARollingWindow(t *testing.T) {
+	wrapped := &fakeRepo{series: buildSampleSeries()}
+	now := rateLimitFrom
+	repo := infra.NewRateLimitedCandleRepository(wrapped, infra.RateLimit{PerMinute: 5}).WithClock(func() time.Time { return now })
+
+	// Try a call every 5 seconds for 5 minutes.
+	var accepted []time.Time
+	for i := 0; i < 60; i++ {
+		if err := rateLimitedGet(context.Background(), repo); err == nil {
+			accepted = append(accepted, now)
+		}
+		now = now.Add(5 * time.Second)
+	}
+	for i, start := range accepted {
+		n := 0
+		for _, at := range accepted[i:] {
+			if at.Before(start.Add(time.Minute)) {
+				n++
+			}
+		}
+		if n > 5 {
+			t.Fatalf("expected at most 5 calls in the minute from %v, got %d", start, n)
+		}
+	}
+	if len(accepted) != 25 {
+		t.Fatalf("expected the full quota of 5 calls per minute to be used, got %d", len(accepted))
+	}
+}
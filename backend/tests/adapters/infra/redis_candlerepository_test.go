@@ -1,20 +1,24 @@
 package infra_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/akarso/pano_chart/backend/adapters/infra"
+	"github.com/akarso/pano_chart/backend/application/ports"
 	"github.com/akarso/pano_chart/backend/domain"
 )
 
 // fakeRedisClient is a test double for Redis operations.
 type fakeRedisClient struct {
-	store    map[string][]byte
-	getErr   error
-	setErr   error
+	mu         sync.Mutex
+	store      map[string][]byte
+	getErr     error
+	setErr     error
 	lastSetKey string
 	lastSetTTL time.Duration
 }
@@ -23,7 +27,9 @@ func newFakeRedis() *fakeRedisClient {
 	return &fakeRedisClient{store: make(map[string][]byte)}
 }
 
-func (f *fakeRedisClient) Get(key string) ([]byte, error) {
+func (f *fakeRedisClient) Get(_ context.Context, key string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	if f.getErr != nil {
 		return nil, f.getErr
 	}
@@ -34,7 +40,9 @@ func (f *fakeRedisClient) Get(key string) ([]byte, error) {
 	return b, nil
 }
 
-func (f *fakeRedisClient) Set(key string, value []byte, ttl time.Duration) error {
+func (f *fakeRedisClient) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	if f.setErr != nil {
 		return f.setErr
 	}
@@ -44,15 +52,51 @@ func (f *fakeRedisClient) Set(key string, value []byte, ttl time.Duration) error
 	return nil
 }
 
+// fakeBatchRedisClient is a fakeRedisClient that also implements infra.BatchRedisClient.
+type fakeBatchRedisClient struct {
+	*fakeRedisClient
+	gets, sets int
+}
+
+func (f *fakeBatchRedisClient) GetMany(ctx context.Context, keys []string) ([][]byte, error) {
+	f.gets++
+	values := make([][]byte, len(keys))
+	for i, k := range keys {
+		values[i], _ = f.fakeRedisClient.Get(ctx, k)
+	}
+	return values, nil
+}
+
+func (f *fakeBatchRedisClient) SetMany(ctx context.Context, items []infra.RedisSetItem) error {
+	f.sets++
+	for _, it := range items {
+		_ = f.fakeRedisClient.Set(ctx, it.Key, it.Value, it.TTL)
+	}
+	return nil
+}
+
+func (f *fakeBatchRedisClient) Get(context.Context, string) ([]byte, error) {
+	return nil, errors.New("unexpected single Get")
+}
+
+func (f *fakeBatchRedisClient) Set(context.Context, string, []byte, time.Duration) error {
+	return errors.New("unexpected single Set")
+}
+
 // fakeRepo is a stubbed wrapped repository implementation.
 type fakeRepo struct {
-	called bool
-	series domain.CandleSeries
-	err    error
+	called   bool
+	calls    int
+	lastFrom time.Time
+	lastTo   time.Time
+	series   domain.CandleSeries
+	err      error
 }
 
-func (f *fakeRepo) GetSeries(sym domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+func (f *fakeRepo) GetSeries(_ context.Context, sym domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
 	f.called = true
+	f.calls++
+	f.lastFrom, f.lastTo = from, to
 	if f.err != nil {
 		return domain.CandleSeries{}, f.err
 	}
@@ -68,11 +112,8 @@ func buildSampleSeries() domain.CandleSeries {
 	return series
 }
 
-func TestRedisCandleRepository_ReturnsCachedResultOnHit(t *testing.T) {
-	fake := newFakeRedis()
-	series := buildSampleSeries()
-
-	// serialize series into cache format
+// encodeSegment serializes series into the cache's segment format.
+func encodeSegment(series domain.CandleSeries, freshUntil time.Time) []byte {
 	all := series.All()
 	items := make([]map[string]interface{}, 0, len(all))
 	for _, c := range all {
@@ -85,8 +126,16 @@ func TestRedisCandleRepository_ReturnsCachedResultOnHit(t *testing.T) {
 			"volume":    c.Volume(),
 		})
 	}
-	b, _ := json.Marshal(items)
-	key := "BTC|1m|2026-01-01T12:00:00Z|2026-01-01T12:01:00Z"
+	b, _ := json.Marshal(map[string]interface{}{"fresh_until": freshUntil.UnixMilli(), "candles": items})
+	return b
+}
+
+func TestRedisCandleRepository_ReturnsCachedResultOnHit(t *testing.T) {
+	fake := newFakeRedis()
+	series := buildSampleSeries()
+
+	key := "BTC|1m|2026-01-01T12:00:00Z"
+	b := encodeSegment(series, time.Now().Add(time.Hour))
 	fake.store[key] = b
 
 	wrapped := &fakeRepo{}
@@ -95,7 +144,7 @@ func TestRedisCandleRepository_ReturnsCachedResultOnHit(t *testing.T) {
 	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
 	to := from.Add(1 * time.Minute)
 
-	res, err := repo.GetSeries(domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), from, to)
+	res, err := repo.GetSeries(context.Background(), domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), from, to)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -116,7 +165,7 @@ func TestRedisCandleRepository_DelegatesOnCacheMiss(t *testing.T) {
 	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
 	to := from.Add(1 * time.Minute)
 
-	res, err := repo.GetSeries(domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), from, to)
+	res, err := repo.GetSeries(context.Background(), domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), from, to)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -133,18 +182,19 @@ func TestRedisCandleRepository_CachesResultAfterMiss(t *testing.T) {
 	series := buildSampleSeries()
 	wrapped := &fakeRepo{series: series}
 	ttl := 2 * time.Minute
-	repo := infra.NewRedisCandleRepository(fake, wrapped, ttl)
-
 	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	// The segment is still forming, so it gets the short TTL.
+	repo := infra.NewRedisCandleRepository(fake, wrapped, ttl).WithClock(func() time.Time { return from.Add(time.Hour) })
+
 	to := from.Add(1 * time.Minute)
 
-	_, err := repo.GetSeries(domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), from, to)
+	_, err := repo.GetSeries(context.Background(), domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), from, to)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	// ensure set was called
-	expectedKey := "BTC|1m|2026-01-01T12:00:00Z|2026-01-01T12:01:00Z"
+	// ensure set was called for the segment holding the candle
+	expectedKey := "BTC|1m|2026-01-01T12:00:00Z"
 	if fake.lastSetKey != expectedKey {
 		t.Fatalf("unexpected cache key: %s", fake.lastSetKey)
 	}
@@ -164,7 +214,7 @@ func TestRedisCandleRepository_IgnoresRedisErrors(t *testing.T) {
 	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
 	to := from.Add(1 * time.Minute)
 
-	res, err := repo.GetSeries(domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), from, to)
+	res, err := repo.GetSeries(context.Background(), domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), from, to)
 	if err != nil {
 		t.Fatalf("expected redis errors to be ignored, got: %v", err)
 	}
@@ -181,8 +231,321 @@ func TestRedisCandleRepository_PropagatesRepositoryError(t *testing.T) {
 	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
 	to := from.Add(1 * time.Minute)
 
-	_, err := repo.GetSeries(domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), from, to)
+	_, err := repo.GetSeries(context.Background(), domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), from, to)
 	if err == nil {
 		t.Fatal("expected repository error to propagate")
 	}
 }
+
+// slowRedisClient blocks every operation until its context is done.
+type slowRedisClient struct{}
+
+func (slowRedisClient) Get(ctx context.Context, key string) ([]byte, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (slowRedisClient) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestRedisCandleRepository_BoundsCacheLatency(t *testing.T) {
+	wrapped := &fakeRepo{series: buildSampleSeries()}
+	repo := infra.NewRedisCandleRepository(slowRedisClient{}, wrapped, time.Minute).WithOperationTimeout(10 * time.Millisecond)
+
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	start := time.Now()
+	res, err := repo.GetSeries(context.Background(), domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), from, from.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("expected a slow cache to degrade to a miss, got: %v", err)
+	}
+	if !wrapped.called || res.Len() != 1 {
+		t.Fatal("expected the wrapped repository to serve the request")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected cache operations to time out quickly, took %v", elapsed)
+	}
+}
+
+func TestRedisCandleRepository_CachesClosedSegmentsWithClosedTTL(t *testing.T) {
+	fake := newFakeRedis()
+	wrapped := &fakeRepo{series: buildSampleSeries()}
+	repo := infra.NewRedisCandleRepository(fake, wrapped, time.Minute).WithClosedTTL(48 * time.Hour)
+
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := repo.GetSeries(context.Background(), domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), from, from.Add(time.Minute)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fake.lastSetTTL != 48*time.Hour {
+		t.Fatalf("expected closed segment TTL, got %v", fake.lastSetTTL)
+	}
+}
+
+func TestRedisCandleRepository_ServesShiftedRangeFromCache(t *testing.T) {
+	fake := newFakeRedis()
+	wrapped := &fakeRepo{series: buildSampleSeries()}
+	repo := infra.NewRedisCandleRepository(fake, wrapped, time.Minute)
+	sym, tf := domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m")
+
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := repo.GetSeries(context.Background(), sym, tf, from, from.Add(30*time.Minute)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res, err := repo.GetSeries(context.Background(), sym, tf, from.Add(time.Minute), from.Add(31*time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if wrapped.calls != 1 {
+		t.Fatalf("expected the shifted range to be served from cache, got %d upstream calls", wrapped.calls)
+	}
+	if res.Len() != 0 {
+		t.Fatalf("expected the candle before the range to be trimmed, got %d candles", res.Len())
+	}
+}
+
+func TestRedisCandleRepository_FetchesOnlyMissingSegments(t *testing.T) {
+	fake := newFakeRedis()
+	wrapped := &fakeRepo{series: buildSampleSeries()}
+	repo := infra.NewRedisCandleRepository(fake, wrapped, time.Minute)
+	sym, tf := domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m")
+
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := repo.GetSeries(context.Background(), sym, tf, from, from.Add(time.Minute)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res, err := repo.GetSeries(context.Background(), sym, tf, from, from.Add(12*time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Len() != 1 {
+		t.Fatalf("expected 1 candle, got %d", res.Len())
+	}
+	if wrapped.calls != 2 || !wrapped.lastFrom.Equal(from.Add(6*time.Hour)) || !wrapped.lastTo.Equal(from.Add(12*time.Hour)) {
+		t.Fatalf("expected only the missing segment to be fetched, got [%v, %v) after %d calls", wrapped.lastFrom, wrapped.lastTo, wrapped.calls)
+	}
+}
+
+func TestRedisCandleRepository_DoesNotCacheEmptyFormingSegment(t *testing.T) {
+	fake := newFakeRedis()
+	empty, _ := domain.NewCandleSeries(domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), nil)
+	wrapped := &fakeRepo{series: empty}
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := infra.NewRedisCandleRepository(fake, wrapped, time.Minute).WithClock(func() time.Time { return from })
+
+	if _, err := repo.GetSeries(context.Background(), domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), from, from.Add(time.Minute)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fake.store) != 0 {
+		t.Fatalf("expected nothing cached, got %d entries", len(fake.store))
+	}
+}
+
+func TestRedisCandleRepository_TreatsSegmentsAsFormingUntilPublished(t *testing.T) {
+	fake := newFakeRedis()
+	empty, _ := domain.NewCandleSeries(domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), nil)
+	wrapped := &fakeRepo{series: empty}
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := from.Add(6*time.Hour + 2*time.Second)
+	repo := infra.NewRedisCandleRepository(fake, wrapped, time.Minute).
+		WithPublicationLag(5 * time.Second).
+		WithClock(func() time.Time { return now })
+	sym, tf := domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m")
+
+	// The segment has ended, but its last candle may not be published yet.
+	if _, err := repo.GetSeries(context.Background(), sym, tf, from, from.Add(6*time.Hour)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fake.store) != 0 {
+		t.Fatalf("expected an empty segment within the lag not to be cached, got %d entries", len(fake.store))
+	}
+
+	wrapped.series = buildSampleSeries()
+	if _, err := repo.GetSeries(context.Background(), sym, tf, from, from.Add(6*time.Hour)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fake.lastSetTTL >= 24*time.Hour {
+		t.Fatalf("expected the forming TTL within the lag, got %v", fake.lastSetTTL)
+	}
+
+	now = now.Add(5 * time.Second)
+	if err := repo.Refresh(context.Background(), sym, tf, from, from.Add(6*time.Hour)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fake.lastSetTTL != 24*time.Hour {
+		t.Fatalf("expected the closed TTL past the lag, got %v", fake.lastSetTTL)
+	}
+}
+
+func TestRedisCandleRepository_ServesStaleWhileRevalidating(t *testing.T) {
+	fake := newFakeRedis()
+	now := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	fake.store["BTC|1m|2026-01-01T12:00:00Z"] = encodeSegment(buildSampleSeries(), now.Add(-time.Minute))
+	wrapped := newGatedRepo()
+	close(wrapped.release)
+	repo := infra.NewRedisCandleRepository(fake, wrapped, time.Minute).
+		WithStaleWhileRevalidate(10 * time.Minute).
+		WithClock(func() time.Time { return now })
+
+	ctx, stale := ports.WithStaleTracking(context.Background())
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	res, err := repo.GetSeries(ctx, domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), from, from.Add(time.Minute))
+	if err != nil || res.Len() != 1 {
+		t.Fatalf("expected the stale series, got %v (%d candles)", err, res.Len())
+	}
+	if !stale() {
+		t.Fatal("expected the response to be marked stale")
+	}
+	select {
+	case <-wrapped.started:
+	case <-time.After(time.Second):
+		t.Fatal("expected a background refresh")
+	}
+}
+
+func TestRedisCandleRepository_ServesStaleOnUpstreamError(t *testing.T) {
+	fake := newFakeRedis()
+	now := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	fake.store["BTC|1m|2026-01-01T12:00:00Z"] = encodeSegment(buildSampleSeries(), now.Add(-time.Hour))
+	wrapped := &fakeRepo{err: &ports.UpstreamStatusError{StatusCode: 429}}
+	repo := infra.NewRedisCandleRepository(fake, wrapped, time.Minute).
+		WithStaleWhileRevalidate(10 * time.Minute).
+		WithStaleIfError(24 * time.Hour).
+		WithClock(func() time.Time { return now })
+
+	ctx, stale := ports.WithStaleTracking(context.Background())
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	res, err := repo.GetSeries(ctx, domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), from, from.Add(time.Minute))
+	if err != nil || res.Len() != 1 {
+		t.Fatalf("expected the stale series, got %v (%d candles)", err, res.Len())
+	}
+	if !wrapped.called || !stale() {
+		t.Fatal("expected a refetch attempt and a stale response")
+	}
+}
+
+func TestRedisCandleRepository_ReturnsErrorPastStaleWindows(t *testing.T) {
+	fake := newFakeRedis()
+	now := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	fake.store["BTC|1m|2026-01-01T12:00:00Z"] = encodeSegment(buildSampleSeries(), now.Add(-2*time.Hour))
+	wrapped := &fakeRepo{err: ports.ErrUpstreamUnavailable}
+	repo := infra.NewRedisCandleRepository(fake, wrapped, time.Minute).
+		WithStaleIfError(time.Hour).
+		WithClock(func() time.Time { return now })
+
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	_, err := repo.GetSeries(context.Background(), domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), from, from.Add(time.Minute))
+	if !errors.Is(err, ports.ErrUpstreamUnavailable) {
+		t.Fatalf("expected the upstream error, got %v", err)
+	}
+}
+
+func TestRedisCandleRepository_DoesNotMaskNonUpstreamErrors(t *testing.T) {
+	fake := newFakeRedis()
+	now := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	fake.store["BTC|1m|2026-01-01T12:00:00Z"] = encodeSegment(buildSampleSeries(), now.Add(-time.Hour))
+	wrapped := &fakeRepo{err: errors.New("malformed payload")}
+	repo := infra.NewRedisCandleRepository(fake, wrapped, time.Minute).
+		WithStaleIfError(24 * time.Hour).
+		WithClock(func() time.Time { return now })
+
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := repo.GetSeries(context.Background(), domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), from, from.Add(time.Minute)); err == nil {
+		t.Fatal("expected the repository error to propagate")
+	}
+}
+
+func TestRedisCandleRepository_RefreshReplacesFreshSegments(t *testing.T) {
+	fake := newFakeRedis()
+	empty, _ := domain.NewCandleSeries(domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), nil)
+	wrapped := &fakeRepo{series: empty}
+	now := time.Date(2026, 1, 1, 12, 30, 0, 0, time.UTC)
+	repo := infra.NewRedisCandleRepository(fake, wrapped, time.Hour).WithClock(func() time.Time { return now })
+	sym, tf := domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m")
+
+	// A closed, empty segment is cached before the candle is published.
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := repo.GetSeries(context.Background(), sym, tf, from.Add(-6*time.Hour), from); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wrapped.series = buildSampleSeries()
+	if err := repo.Refresh(context.Background(), sym, tf, from, from.Add(time.Minute)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !wrapped.lastFrom.Equal(from) || !wrapped.lastTo.Equal(from.Add(6*time.Hour)) {
+		t.Fatalf("expected the uncached segment to be refetched whole, got [%v, %v)", wrapped.lastFrom, wrapped.lastTo)
+	}
+
+	calls := wrapped.calls
+	res, err := repo.GetSeries(context.Background(), sym, tf, from, from.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if wrapped.calls != calls || res.Len() != 1 {
+		t.Fatalf("expected the refreshed candle from cache, got %d candles after %d upstream calls", res.Len(), wrapped.calls-calls)
+	}
+}
+
+func TestRedisCandleRepository_RefreshMergesIntoCachedSegments(t *testing.T) {
+	fake := newFakeRedis()
+	sym, tf := domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m")
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	candle := func(minute int, close float64) domain.Candle {
+		return domain.NewCandleUnsafe(sym, tf, from.Add(time.Duration(minute)*time.Minute), 100, 110, 90, close, 1000)
+	}
+	cached, _ := domain.NewCandleSeries(sym, tf, []domain.Candle{candle(0, 101), candle(1, 102), candle(2, 103)})
+	wrapped := &fakeRepo{series: cached}
+	now := from.Add(3 * time.Minute)
+	repo := infra.NewRedisCandleRepository(fake, wrapped, time.Hour).WithClock(func() time.Time { return now })
+	if _, err := repo.GetSeries(context.Background(), sym, tf, from, from.Add(3*time.Minute)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wrapped.series, _ = domain.NewCandleSeries(sym, tf, []domain.Candle{candle(1, 107)})
+	if err := repo.Refresh(context.Background(), sym, tf, from.Add(time.Minute), from.Add(2*time.Minute)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !wrapped.lastFrom.Equal(from.Add(time.Minute)) || !wrapped.lastTo.Equal(from.Add(2*time.Minute)) {
+		t.Fatalf("expected only the refreshed range to be fetched, got [%v, %v)", wrapped.lastFrom, wrapped.lastTo)
+	}
+
+	calls := wrapped.calls
+	res, err := repo.GetSeries(context.Background(), sym, tf, from, from.Add(3*time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if wrapped.calls != calls {
+		t.Fatalf("expected the merged segment to be served from cache, got %d upstream calls", wrapped.calls-calls)
+	}
+	var closes []float64
+	for _, c := range res.All() {
+		closes = append(closes, c.Close())
+	}
+	if len(closes) != 3 || closes[0] != 101 || closes[1] != 107 || closes[2] != 103 {
+		t.Fatalf("expected the refreshed candle merged between the cached ones, got %v", closes)
+	}
+}
+
+func TestRedisCandleRepository_BatchesSegmentsWhenSupported(t *testing.T) {
+	fake := &fakeBatchRedisClient{fakeRedisClient: newFakeRedis()}
+	wrapped := &fakeRepo{series: buildSampleSeries()}
+	repo := infra.NewRedisCandleRepository(fake, wrapped, time.Minute)
+	sym, tf := domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m")
+
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := repo.GetSeries(context.Background(), sym, tf, from, from.Add(24*time.Hour)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fake.gets != 1 || fake.sets != 1 || len(fake.store) != 4 {
+		t.Fatalf("expected 4 segments in one GetMany and one SetMany, got %d gets, %d sets, %d entries", fake.gets, fake.sets, len(fake.store))
+	}
+
+	res, err := repo.GetSeries(context.Background(), sym, tf, from, from.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if wrapped.calls != 1 || fake.gets != 2 || res.Len() != 1 {
+		t.Fatalf("expected the range from one batched read, got %d candles after %d upstream calls and %d gets", res.Len(), wrapped.calls, fake.gets)
+	}
+}
@@ -0,0 +1,389 @@
+package infra_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/adapters/infra"
+)
+
+// fakeRESPServer is an in-process Redis stand-in supporting HELLO, AUTH, SELECT, GET, SET
+// and PING over RESP2 or RESP3.
+type fakeRESPServer struct {
+	ln       net.Listener
+	password string
+	stall    time.Duration // delays every reply
+
+	mu       sync.Mutex
+	store    map[string]string
+	ttls     map[string]string
+	commands []string
+	dbs      []string
+	conns    int
+	open     int
+	maxOpen  int
+}
+
+func newFakeRESPServer(t *testing.T, password string, stall time.Duration) *fakeRESPServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &fakeRESPServer{ln: ln, password: password, stall: stall, store: make(map[string]string), ttls: make(map[string]string)}
+	t.Cleanup(func() { _ = ln.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *fakeRESPServer) addr() string { return s.ln.Addr().String() }
+
+// locked runs f while holding the server's lock, for reading its recorded state.
+func (s *fakeRESPServer) locked(f func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f()
+}
+
+func (s *fakeRESPServer) serve() {
+	for {
+		c, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns++
+		s.open++
+		if s.open > s.maxOpen {
+			s.maxOpen = s.open
+		}
+		s.mu.Unlock()
+		go s.handle(c)
+	}
+}
+
+func (s *fakeRESPServer) handle(c net.Conn) {
+	defer func() {
+		_ = c.Close()
+		s.mu.Lock()
+		s.open--
+		s.mu.Unlock()
+	}()
+	br := bufio.NewReader(c)
+	proto, authed := 2, s.password == ""
+	for {
+		args, err := readCommand(br)
+		if err != nil {
+			return
+		}
+		if s.stall > 0 {
+			time.Sleep(s.stall)
+		}
+		name := strings.ToUpper(args[0])
+		s.mu.Lock()
+		s.commands = append(s.commands, name)
+		s.mu.Unlock()
+
+		var reply string
+		switch {
+		case name == "HELLO":
+			if len(args) >= 5 && strings.ToUpper(args[2]) == "AUTH" {
+				authed = args[4] == s.password
+			}
+			if !authed {
+				reply = "-WRONGPASS invalid password\r\n"
+				break
+			}
+			proto, _ = strconv.Atoi(args[1])
+			reply = "%2\r\n+server\r\n+fake\r\n+proto\r\n:3\r\n"
+		case name == "AUTH":
+			authed = args[len(args)-1] == s.password
+			reply = "+OK\r\n"
+			if !authed {
+				reply = "-WRONGPASS invalid password\r\n"
+			}
+		case !authed:
+			reply = "-NOAUTH Authentication required.\r\n"
+		case name == "SELECT":
+			s.mu.Lock()
+			s.dbs = append(s.dbs, args[1])
+			s.mu.Unlock()
+			reply = "+OK\r\n"
+		case name == "PING":
+			reply = "+PONG\r\n"
+		case name == "SET":
+			s.mu.Lock()
+			s.store[args[1]] = args[2]
+			if len(args) == 5 {
+				s.ttls[args[1]] = args[3] + " " + args[4]
+			}
+			s.mu.Unlock()
+			reply = "+OK\r\n"
+		case name == "GET":
+			s.mu.Lock()
+			v, ok := s.store[args[1]]
+			s.mu.Unlock()
+			switch {
+			case ok:
+				reply = fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+			case proto == 3:
+				reply = "_\r\n"
+			default:
+				reply = "$-1\r\n"
+			}
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		if _, err := io.WriteString(c, reply); err != nil {
+			return
+		}
+	}
+}
+
+// readCommand parses one RESP array of bulk strings.
+func readCommand(br *bufio.Reader) ([]string, error) {
+	line, err := br.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil || line[0] != '*' {
+		return nil, errors.New("expected array")
+	}
+	args := make([]string, n)
+	for i := range args {
+		hdr, err := br.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, _ := strconv.Atoi(strings.TrimSpace(hdr[1:]))
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(br, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func TestRedisClient_ImplementsMinimalRedisClient(t *testing.T) {
+	// compile-time check
+	var _ infra.MinimalRedisClient = infra.NewRedisClient(infra.RedisClientOptions{})
+	var _ infra.BatchRedisClient = infra.NewRedisClient(infra.RedisClientOptions{})
+	_ = t
+}
+
+func TestRedisClient_SetThenGet(t *testing.T) {
+	srv := newFakeRESPServer(t, "", 0)
+	client := infra.NewRedisClient(infra.RedisClientOptions{Addr: srv.addr()})
+	defer func() { _ = client.Close() }()
+	ctx := context.Background()
+
+	if err := client.Set(ctx, "k", []byte("v\r\nwith crlf"), 90*time.Second); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	got, err := client.Get(ctx, "k")
+	if err != nil || string(got) != "v\r\nwith crlf" {
+		t.Fatalf("expected stored value, got %q (%v)", got, err)
+	}
+	srv.locked(func() {
+		if srv.ttls["k"] != "PX 90000" {
+			t.Fatalf("expected TTL in milliseconds, got %q", srv.ttls["k"])
+		}
+	})
+	if _, err := client.Get(ctx, "missing"); !errors.Is(err, infra.ErrRedisNil) {
+		t.Fatalf("expected ErrRedisNil, got %v", err)
+	}
+	srv.locked(func() {
+		if srv.conns != 1 {
+			t.Fatalf("expected the connection to be reused, got %d connections", srv.conns)
+		}
+	})
+}
+
+func TestRedisClient_AuthenticatesAndSelectsDB(t *testing.T) {
+	srv := newFakeRESPServer(t, "secret", 0)
+	client := infra.NewRedisClient(infra.RedisClientOptions{Addr: srv.addr(), Password: "secret", DB: 3})
+	defer func() { _ = client.Close() }()
+
+	if err := client.Set(context.Background(), "k", []byte("v"), 0); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	srv.locked(func() {
+		if len(srv.dbs) != 1 || srv.dbs[0] != "3" {
+			t.Fatalf("expected SELECT 3, got %v", srv.dbs)
+		}
+		if srv.ttls["k"] != "" {
+			t.Fatalf("expected no expiry, got %q", srv.ttls["k"])
+		}
+	})
+}
+
+func TestRedisClient_RejectsWrongPassword(t *testing.T) {
+	srv := newFakeRESPServer(t, "secret", 0)
+	client := infra.NewRedisClient(infra.RedisClientOptions{Addr: srv.addr(), Password: "wrong"})
+	defer func() { _ = client.Close() }()
+
+	_, err := client.Get(context.Background(), "k")
+	var rerr *infra.RedisError
+	if !errors.As(err, &rerr) || !strings.HasPrefix(rerr.Message, "WRONGPASS") {
+		t.Fatalf("expected WRONGPASS error, got %v", err)
+	}
+}
+
+func TestRedisClient_NegotiatesRESP3(t *testing.T) {
+	srv := newFakeRESPServer(t, "secret", 0)
+	client := infra.NewRedisClient(infra.RedisClientOptions{Addr: srv.addr(), Password: "secret", Protocol: 3})
+	defer func() { _ = client.Close() }()
+
+	if _, err := client.Get(context.Background(), "missing"); !errors.Is(err, infra.ErrRedisNil) {
+		t.Fatalf("expected ErrRedisNil for a RESP3 null, got %v", err)
+	}
+	srv.locked(func() {
+		if srv.commands[0] != "HELLO" {
+			t.Fatalf("expected HELLO handshake, got %v", srv.commands)
+		}
+	})
+}
+
+func TestRedisClient_PipelinesBatches(t *testing.T) {
+	srv := newFakeRESPServer(t, "", 0)
+	client := infra.NewRedisClient(infra.RedisClientOptions{Addr: srv.addr()})
+	defer func() { _ = client.Close() }()
+	ctx := context.Background()
+
+	err := client.SetMany(ctx, []infra.RedisSetItem{
+		{Key: "a", Value: []byte("1"), TTL: time.Minute},
+		{Key: "c", Value: []byte("3"), TTL: time.Hour},
+	})
+	if err != nil {
+		t.Fatalf("set many: %v", err)
+	}
+	values, err := client.GetMany(ctx, []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatalf("get many: %v", err)
+	}
+	if string(values[0]) != "1" || values[1] != nil || string(values[2]) != "3" {
+		t.Fatalf("expected aligned values, got %q", values)
+	}
+	srv.locked(func() {
+		if srv.conns != 1 || srv.ttls["c"] != "PX 3600000" {
+			t.Fatalf("expected both batches on one connection with per-item TTLs, got %d connections, ttl %q", srv.conns, srv.ttls["c"])
+		}
+	})
+}
+
+func TestRedisClient_BoundsPoolSize(t *testing.T) {
+	srv := newFakeRESPServer(t, "", 10*time.Millisecond)
+	client := infra.NewRedisClient(infra.RedisClientOptions{Addr: srv.addr(), PoolSize: 2})
+	defer func() { _ = client.Close() }()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = client.Set(context.Background(), "k", []byte("v"), 0)
+		}()
+	}
+	wg.Wait()
+
+	srv.locked(func() {
+		if srv.maxOpen > 2 {
+			t.Fatalf("expected at most 2 connections, got %d", srv.maxOpen)
+		}
+	})
+}
+
+func TestRedisClient_HonorsReadTimeoutAndContext(t *testing.T) {
+	srv := newFakeRESPServer(t, "", time.Second)
+	client := infra.NewRedisClient(infra.RedisClientOptions{Addr: srv.addr(), ReadTimeout: 20 * time.Millisecond})
+	defer func() { _ = client.Close() }()
+
+	start := time.Now()
+	if _, err := client.Get(context.Background(), "k"); err == nil {
+		t.Fatal("expected a read timeout")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	slow := infra.NewRedisClient(infra.RedisClientOptions{Addr: srv.addr()})
+	defer func() { _ = slow.Close() }()
+	if _, err := slow.Get(ctx, "k"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected both calls to abort quickly, took %v", elapsed)
+	}
+}
+
+func TestRedisClient_DiscardsConnectionsUsedPastCancellation(t *testing.T) {
+	srv := newFakeRESPServer(t, "", 0)
+	client := infra.NewRedisClient(infra.RedisClientOptions{Addr: srv.addr(), PoolSize: 1})
+	defer func() { _ = client.Close() }()
+	if err := client.Set(context.Background(), "k", []byte("v"), 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The pooled connection is taken even though ctx has ended; its deadline may then be
+	// expired at any point, so it must not return to the pool.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := client.Set(ctx, "k", []byte("v"), 0); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	if err := client.Set(context.Background(), "k", []byte("v"), 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	srv.locked(func() {
+		if srv.conns != 2 {
+			t.Fatalf("expected the cancelled connection to be replaced, got %d connections", srv.conns)
+		}
+	})
+}
+
+func TestRedisClient_FailsAfterClose(t *testing.T) {
+	srv := newFakeRESPServer(t, "", 0)
+	client := infra.NewRedisClient(infra.RedisClientOptions{Addr: srv.addr()})
+	_ = client.Close()
+
+	if _, err := client.Get(context.Background(), "k"); !errors.Is(err, infra.ErrRedisClosed) {
+		t.Fatalf("expected ErrRedisClosed, got %v", err)
+	}
+}
+
+func TestRedisClient_RejectsOversizedReplies(t *testing.T) {
+	for _, reply := range []string{"*4611686018427387904\r\n", "$4611686018427387904\r\n"} {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("listen: %v", err)
+		}
+		go func() {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer c.Close()
+			if _, err := readCommand(bufio.NewReader(c)); err == nil {
+				_, _ = io.WriteString(c, reply)
+			}
+		}()
+		client := infra.NewRedisClient(infra.RedisClientOptions{Addr: ln.Addr().String()})
+
+		if _, err := client.Get(context.Background(), "k"); err == nil || !strings.Contains(err.Error(), "protocol error") {
+			t.Fatalf("%q: expected a protocol error, got %v", reply, err)
+		}
+		_ = client.Close()
+		_ = ln.Close()
+	}
+}
@@ -0,0 +1,74 @@
+package infra_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/adapters/infra"
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// recordingRepo returns a generated 1m series covering the requested range.
+type recordingRepo struct {
+	lastTf   domain.Timeframe
+	lastFrom time.Time
+	lastTo   time.Time
+}
+
+func (f *recordingRepo) GetSeries(_ context.Context, sym domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+	f.lastTf, f.lastFrom, f.lastTo = tf, from, to
+	var candles []domain.Candle
+	for ts := from; ts.Before(to); ts = ts.Add(tf.Duration()) {
+		candles = append(candles, domain.NewCandleUnsafe(sym, tf, ts, 100, 110, 90, 105, 1))
+	}
+	return domain.NewCandleSeries(sym, tf, candles)
+}
+
+func TestResamplingCandleRepository_ImplementsPort(t *testing.T) {
+	var _ ports.CandleRepositoryPort = infra.NewResamplingCandleRepository(&recordingRepo{}, domain.Timeframe1m, domain.ResamplePolicy{})
+	_ = t
+}
+
+func TestResamplingCandleRepository_DerivesCoarserTimeframeFromBase(t *testing.T) {
+	wrapped := &recordingRepo{}
+	repo := infra.NewResamplingCandleRepository(wrapped, domain.Timeframe1m, domain.ResamplePolicy{})
+
+	from := time.Date(2026, 1, 1, 12, 10, 0, 0, time.UTC)
+	to := time.Date(2026, 1, 1, 13, 5, 0, 0, time.UTC)
+
+	res, err := repo.GetSeries(context.Background(), domain.NewSymbolUnsafe("BTC"), domain.Timeframe15m, from, to)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if wrapped.lastTf != domain.Timeframe1m {
+		t.Fatalf("expected base timeframe to be requested, got %v", wrapped.lastTf)
+	}
+	if !wrapped.lastFrom.Equal(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)) || !wrapped.lastTo.Equal(time.Date(2026, 1, 1, 13, 15, 0, 0, time.UTC)) {
+		t.Fatalf("expected range widened to whole buckets, got %v..%v", wrapped.lastFrom, wrapped.lastTo)
+	}
+	// Buckets 12:15, 12:30, 12:45, 13:00 fall within [from, to).
+	if res.Len() != 4 || res.Timeframe() != domain.Timeframe15m {
+		t.Fatalf("expected 4 15m candles, got %d at %v", res.Len(), res.Timeframe())
+	}
+	c, _ := res.First()
+	if c.Volume() != 15 {
+		t.Fatalf("expected folded volume 15, got %v", c.Volume())
+	}
+}
+
+func TestResamplingCandleRepository_PassesThroughBaseAndFinerTimeframes(t *testing.T) {
+	wrapped := &recordingRepo{}
+	repo := infra.NewResamplingCandleRepository(wrapped, domain.Timeframe1h, domain.ResamplePolicy{})
+
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for _, tf := range []domain.Timeframe{domain.Timeframe1h, domain.Timeframe5m} {
+		if _, err := repo.GetSeries(context.Background(), domain.NewSymbolUnsafe("BTC"), tf, from, from.Add(time.Hour)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if wrapped.lastTf != tf || !wrapped.lastFrom.Equal(from) {
+			t.Fatalf("expected %v request passed through unchanged", tf)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package infra_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/adapters/infra"
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// scriptedRepo fails with errs in order, then serves a sample series.
+type scriptedRepo struct {
+	errs  []error
+	calls int
+}
+
+func (s *scriptedRepo) GetSeries(_ context.Context, sym domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+	s.calls++
+	if s.calls <= len(s.errs) && s.errs[s.calls-1] != nil {
+		return domain.CandleSeries{}, s.errs[s.calls-1]
+	}
+	return buildSampleSeries(), nil
+}
+
+var resilientFrom = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func resilientGet(ctx context.Context, repo ports.CandleRepositoryPort) error {
+	_, err := repo.GetSeries(ctx, domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), resilientFrom, resilientFrom.Add(time.Minute))
+	return err
+}
+
+// fastPolicy keeps backoff delays negligible.
+func fastPolicy() infra.ResiliencePolicy {
+	return infra.ResiliencePolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, FailureThreshold: 2, OpenTimeout: time.Minute}
+}
+
+func TestResilientCandleRepository_ImplementsPort(t *testing.T) {
+	// compile-time check
+	var _ ports.CandleRepositoryPort = infra.NewResilientCandleRepository(&fakeRepo{}, infra.ResiliencePolicy{})
+	_ = t
+}
+
+func TestResilientCandleRepository_RetriesTransientFailures(t *testing.T) {
+	unavailable := &ports.UpstreamStatusError{StatusCode: 503}
+	wrapped := &scriptedRepo{errs: []error{unavailable, unavailable}}
+	repo := infra.NewResilientCandleRepository(wrapped, fastPolicy())
+
+	if err := resilientGet(context.Background(), repo); err != nil {
+		t.Fatalf("expected the third attempt to succeed, got %v", err)
+	}
+	if wrapped.calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", wrapped.calls)
+	}
+	if repo.State() != infra.CircuitClosed {
+		t.Fatalf("expected the circuit to stay closed, got %v", repo.State())
+	}
+}
+
+func TestResilientCandleRepository_DoesNotRetryPermanentFailures(t *testing.T) {
+	for _, err := range []error{ports.ErrRateLimited, errors.New("malformed payload")} {
+		wrapped := &scriptedRepo{errs: []error{err}}
+		repo := infra.NewResilientCandleRepository(wrapped, fastPolicy())
+
+		if got := resilientGet(context.Background(), repo); !errors.Is(got, err) {
+			t.Fatalf("expected %v to be returned, got %v", err, got)
+		}
+		if wrapped.calls != 1 {
+			t.Fatalf("%v: expected no retry, got %d attempts", err, wrapped.calls)
+		}
+	}
+}
+
+func TestResilientCandleRepository_OpensAfterConsecutiveFailures(t *testing.T) {
+	wrapped := &fakeRepo{err: ports.ErrUpstreamUnavailable}
+	var transitions []string
+	repo := infra.NewResilientCandleRepository(wrapped, fastPolicy()).
+		OnStateChange(func(from, to infra.CircuitState) { transitions = append(transitions, from.String()+">"+to.String()) })
+
+	_ = resilientGet(context.Background(), repo)
+	_ = resilientGet(context.Background(), repo)
+	if repo.State() != infra.CircuitOpen {
+		t.Fatalf("expected the circuit to open, got %v", repo.State())
+	}
+
+	calls := wrapped.calls
+	if err := resilientGet(context.Background(), repo); !errors.Is(err, infra.ErrCircuitOpen) || !errors.Is(err, ports.ErrUpstreamUnavailable) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if wrapped.calls != calls {
+		t.Fatal("expected an open circuit to fail fast")
+	}
+	if len(transitions) != 1 || transitions[0] != "closed>open" {
+		t.Fatalf("unexpected transitions: %v", transitions)
+	}
+}
+
+func TestResilientCandleRepository_HalfOpenProbeClosesOrReopens(t *testing.T) {
+	wrapped := &fakeRepo{err: ports.ErrUpstreamUnavailable}
+	now := resilientFrom
+	repo := infra.NewResilientCandleRepository(wrapped, fastPolicy()).WithClock(func() time.Time { return now })
+
+	_ = resilientGet(context.Background(), repo)
+	_ = resilientGet(context.Background(), repo)
+	now = now.Add(time.Minute)
+	if repo.State() != infra.CircuitHalfOpen {
+		t.Fatalf("expected the circuit to half-open, got %v", repo.State())
+	}
+
+	// A failed probe is not retried and reopens the circuit.
+	calls := wrapped.calls
+	_ = resilientGet(context.Background(), repo)
+	if wrapped.calls != calls+1 || repo.State() != infra.CircuitOpen {
+		t.Fatalf("expected a single probe to reopen the circuit, got %d attempts, %v", wrapped.calls-calls, repo.State())
+	}
+
+	now = now.Add(time.Minute)
+	wrapped.err = nil
+	if err := resilientGet(context.Background(), repo); err != nil {
+		t.Fatalf("expected the probe to succeed, got %v", err)
+	}
+	if repo.State() != infra.CircuitClosed {
+		t.Fatalf("expected a successful probe to close the circuit, got %v", repo.State())
+	}
+}
+
+func TestResilientCandleRepository_StopsRetryingOnCancel(t *testing.T) {
+	wrapped := &fakeRepo{err: ports.ErrUpstreamUnavailable}
+	policy := fastPolicy()
+	policy.BaseDelay, policy.MaxDelay = time.Hour, time.Hour
+	repo := infra.NewResilientCandleRepository(wrapped, policy)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := resilientGet(ctx, repo); err == nil {
+		t.Fatal("expected an error")
+	}
+	if wrapped.calls != 1 {
+		t.Fatalf("expected the backoff to end with the context, got %d attempts", wrapped.calls)
+	}
+}
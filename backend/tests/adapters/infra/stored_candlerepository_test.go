@@ -0,0 +1,191 @@
+package infra_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/adapters/infra"
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+func storedGet(repo ports.CandleRepositoryPort, from, to int) (domain.CandleSeries, error) {
+	return repo.GetSeries(context.Background(), domain.NewSymbolUnsafe("BTC"), domain.Timeframe1m, failoverFrom.Add(time.Duration(from)*time.Minute), failoverFrom.Add(time.Duration(to)*time.Minute))
+}
+
+func TestStoredCandleRepository_ImplementsPort(t *testing.T) {
+	// compile-time check
+	var _ ports.CandleRepositoryPort = infra.NewStoredCandleRepository(newTestStore(t, t.TempDir()), &fakeRepo{})
+}
+
+func TestStoredCandleRepository_ServesCoveredHistoryFromStore(t *testing.T) {
+	dir := t.TempDir()
+	store := newTestStore(t, dir)
+	wrapped := &fakeRepo{series: minuteSeries(0, 1, 2, 3, 4)}
+	repo := infra.NewStoredCandleRepository(store, wrapped).WithClock(func() time.Time { return failoverFrom.Add(time.Hour) })
+
+	if _, err := storedGet(repo, 0, 5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	series, err := storedGet(repo, 1, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if wrapped.calls != 1 {
+		t.Fatalf("expected covered history to be served from the store, got %d upstream calls", wrapped.calls)
+	}
+	if series.Len() != 3 {
+		t.Fatalf("expected 3 candles, got %d", series.Len())
+	}
+
+	// History survives a restart.
+	restarted := infra.NewStoredCandleRepository(newTestStore(t, dir), &fakeRepo{err: ports.ErrUpstreamUnavailable}).
+		WithClock(func() time.Time { return failoverFrom.Add(time.Hour) })
+	if series, err := storedGet(restarted, 0, 5); err != nil || series.Len() != 5 {
+		t.Fatalf("expected the stored history after a restart, got %d candles, %v", series.Len(), err)
+	}
+}
+
+func TestStoredCandleRepository_FetchesOnlyUncoveredSpan(t *testing.T) {
+	store := newTestStore(t, t.TempDir())
+	if err := store.Upsert(context.Background(), minuteSeries(0, 1), minuteRange(0, 2)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wrapped := &fakeRepo{series: minuteSeries(2, 3, 4)}
+	repo := infra.NewStoredCandleRepository(store, wrapped).WithClock(func() time.Time { return failoverFrom.Add(time.Hour) })
+
+	series, err := storedGet(repo, 0, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !wrapped.lastFrom.Equal(failoverFrom.Add(2*time.Minute)) || !wrapped.lastTo.Equal(failoverFrom.Add(5*time.Minute)) {
+		t.Fatalf("expected only [12:02, 12:05) to be fetched, got [%v, %v)", wrapped.lastFrom, wrapped.lastTo)
+	}
+	if series.Len() != 5 {
+		t.Fatalf("expected 5 candles, got %d", series.Len())
+	}
+}
+
+func TestStoredCandleRepository_DoesNotStoreFormingCandle(t *testing.T) {
+	store := newTestStore(t, t.TempDir())
+	wrapped := &fakeRepo{series: minuteSeries(0, 1, 2)}
+	// 12:02 is still forming.
+	repo := infra.NewStoredCandleRepository(store, wrapped).WithClock(func() time.Time { return failoverFrom.Add(150 * time.Second) })
+
+	series, err := storedGet(repo, 0, 3)
+	if err != nil || series.Len() != 3 {
+		t.Fatalf("expected 3 candles, got %d, %v", series.Len(), err)
+	}
+	if got := storeGet(t, store, 0, 3); got.Len() != 2 {
+		t.Fatalf("expected only closed candles to be stored, got %d", got.Len())
+	}
+
+	if _, err := storedGet(repo, 0, 3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !wrapped.lastFrom.Equal(failoverFrom.Add(2 * time.Minute)) {
+		t.Fatalf("expected only the forming candle to be refetched, got from %v", wrapped.lastFrom)
+	}
+}
+
+func TestStoredCandleRepository_PropagatesUpstreamErrors(t *testing.T) {
+	repo := infra.NewStoredCandleRepository(newTestStore(t, t.TempDir()), &fakeRepo{err: ports.ErrUpstreamUnavailable})
+	if _, err := storedGet(repo, 0, 5); !errors.Is(err, ports.ErrUpstreamUnavailable) {
+		t.Fatalf("expected ErrUpstreamUnavailable, got %v", err)
+	}
+}
+
+func TestStoredCandleRepository_CoversGapsTheProviderAnsweredFor(t *testing.T) {
+	store := newTestStore(t, t.TempDir())
+	// 12:02 does not exist upstream.
+	wrapped := &fakeRepo{series: minuteSeries(0, 1, 3, 4)}
+	repo := infra.NewStoredCandleRepository(store, wrapped).WithClock(func() time.Time { return failoverFrom.Add(time.Hour) })
+
+	for i := 0; i < 2; i++ {
+		series, err := storedGet(repo, 0, 5)
+		if err != nil || series.Len() != 4 {
+			t.Fatalf("expected 4 candles, got %d, %v", series.Len(), err)
+		}
+	}
+	if wrapped.calls != 1 {
+		t.Fatalf("expected the gapped range to be served from the store, got %d upstream calls", wrapped.calls)
+	}
+}
+
+func TestStoredCandleRepository_RefetchesTheTailAfterTheLastCandle(t *testing.T) {
+	store := newTestStore(t, t.TempDir())
+	// 12:02 onwards is missing upstream, or the response was truncated.
+	wrapped := &fakeRepo{series: minuteSeries(0, 1)}
+	repo := infra.NewStoredCandleRepository(store, wrapped).WithClock(func() time.Time { return failoverFrom.Add(time.Hour) })
+
+	if _, err := storedGet(repo, 0, 5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	covered, err := store.Covered(context.Background(), domain.NewSymbolUnsafe("BTC"), domain.Timeframe1m, failoverFrom, failoverFrom.Add(5*time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(covered) != 1 || !covered[0].To.Equal(failoverFrom.Add(2*time.Minute)) {
+		t.Fatalf("expected only [12:00, 12:02) to be covered, got %v", covered)
+	}
+
+	wrapped.series = minuteSeries(2, 3, 4)
+	if _, err := storedGet(repo, 0, 5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !wrapped.lastFrom.Equal(failoverFrom.Add(2 * time.Minute)) {
+		t.Fatalf("expected the tail to be fetched again, got from %v", wrapped.lastFrom)
+	}
+}
+
+func TestStoredCandleRepository_CoversEmptyGapsBeforeStoredHistory(t *testing.T) {
+	store := newTestStore(t, t.TempDir())
+	if err := store.Upsert(context.Background(), minuteSeries(3, 4), minuteRange(3, 5)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wrapped := &fakeRepo{series: minuteSeries()}
+	repo := infra.NewStoredCandleRepository(store, wrapped).WithClock(func() time.Time { return failoverFrom.Add(time.Hour) })
+
+	for i := 0; i < 2; i++ {
+		if _, err := storedGet(repo, 0, 5); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if wrapped.calls != 1 {
+		t.Fatalf("expected the empty gap to be covered, got %d upstream calls", wrapped.calls)
+	}
+}
+
+func TestStoredCandleRepository_DoesNotCoverEmptyResponses(t *testing.T) {
+	store := newTestStore(t, t.TempDir())
+	empty, _ := domain.NewCandleSeries(domain.NewSymbolUnsafe("BTC"), domain.Timeframe1m, nil)
+	wrapped := &fakeRepo{series: empty}
+	repo := infra.NewStoredCandleRepository(store, wrapped).WithClock(func() time.Time { return failoverFrom.Add(time.Hour) })
+
+	for i := 0; i < 2; i++ {
+		if _, err := storedGet(repo, 0, 5); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if wrapped.calls != 2 {
+		t.Fatalf("expected an empty response not to be cached as coverage, got %d upstream calls", wrapped.calls)
+	}
+}
+
+func TestStoredCandleRepository_WaitsForThePublicationLag(t *testing.T) {
+	store := newTestStore(t, t.TempDir())
+	wrapped := &fakeRepo{series: minuteSeries(0, 1, 2)}
+	// 12:02 ended 3s ago, within the publication lag.
+	repo := infra.NewStoredCandleRepository(store, wrapped).
+		WithPublicationLag(10 * time.Second).
+		WithClock(func() time.Time { return failoverFrom.Add(3*time.Minute + 3*time.Second) })
+
+	if _, err := storedGet(repo, 0, 3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := storeGet(t, store, 0, 3); got.Len() != 2 {
+		t.Fatalf("expected the candle within the lag not to be stored, got %d stored", got.Len())
+	}
+}
@@ -0,0 +1,142 @@
+package infra_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/adapters/infra"
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+func TestWindowedCandleRepository_ImplementsPort(t *testing.T) {
+	// compile-time check
+	var _ ports.CandleRepositoryPort = infra.NewWindowedCandleRepository(&fakeRepo{}, 0)
+}
+
+// windowServer answers every request with one candle per minute from `from` to `to`
+// inclusive, so adjacent windows overlap on their boundary candle. It records the windows
+// requested and the peak number of requests in flight.
+type windowServer struct {
+	mu       sync.Mutex
+	windows  []string
+	inFlight int
+	peak     int
+	delay    time.Duration
+	failFrom string
+}
+
+func (s *windowServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	s.mu.Lock()
+	s.windows = append(s.windows, q.Get("from"))
+	s.inFlight++
+	if s.inFlight > s.peak {
+		s.peak = s.inFlight
+	}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.inFlight--
+		s.mu.Unlock()
+	}()
+	time.Sleep(s.delay)
+
+	if q.Get("from") == s.failFrom {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	from, _ := time.Parse(time.RFC3339, q.Get("from"))
+	to, _ := time.Parse(time.RFC3339, q.Get("to"))
+	items := []sampleResponseItem{}
+	for ts := from; !ts.After(to); ts = ts.Add(time.Minute) {
+		items = append(items, sampleResponseItem{Timestamp: ts.Format(time.RFC3339), Open: 100, High: 110, Low: 90, Close: 105, Volume: 1000})
+	}
+	_ = json.NewEncoder(w).Encode(items)
+}
+
+func TestWindowedCandleRepository_ChunksLongRanges(t *testing.T) {
+	stub := &windowServer{}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	repo := infra.NewWindowedCandleRepository(infra.NewFreeTierCandleRepository(server.URL, server.Client()), 3)
+
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	series, err := repo.GetSeries(context.Background(), domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), from, from.Add(9*time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stub.windows) != 3 {
+		t.Fatalf("expected 3 windows, got %v", stub.windows)
+	}
+	if stub.windows[1] != "2026-01-01T12:03:00Z" {
+		t.Fatalf("expected the second window to start at 12:03, got %s", stub.windows[1])
+	}
+	// Boundary candles are returned by two windows but kept once.
+	if series.Len() != 10 {
+		t.Fatalf("expected 10 candles, got %d", series.Len())
+	}
+}
+
+func TestWindowedCandleRepository_BoundsWindowConcurrency(t *testing.T) {
+	stub := &windowServer{delay: 20 * time.Millisecond}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	repo := infra.NewWindowedCandleRepository(infra.NewFreeTierCandleRepository(server.URL, server.Client()), 2).WithConcurrency(2)
+
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	series, err := repo.GetSeries(context.Background(), domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), from, from.Add(10*time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stub.windows) != 5 || series.Len() != 11 {
+		t.Fatalf("expected 5 windows and 11 candles, got %d and %d", len(stub.windows), series.Len())
+	}
+	if stub.peak != 2 {
+		t.Fatalf("expected 2 windows in flight at most, got %d", stub.peak)
+	}
+}
+
+func TestWindowedCandleRepository_FailsWhenAWindowFails(t *testing.T) {
+	stub := &windowServer{failFrom: "2026-01-01T12:02:00Z"}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	repo := infra.NewWindowedCandleRepository(infra.NewFreeTierCandleRepository(server.URL, server.Client()), 2)
+
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	_, err := repo.GetSeries(context.Background(), domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), from, from.Add(6*time.Minute))
+	var se *ports.UpstreamStatusError
+	if !errors.As(err, &se) || se.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected the failing window's error, got %v", err)
+	}
+	if len(stub.windows) != 2 {
+		t.Fatalf("expected the remaining windows to be abandoned, got %v", stub.windows)
+	}
+}
+
+func TestWindowedCandleRepository_ChargesEveryWindowToTheRateLimit(t *testing.T) {
+	stub := &windowServer{}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	limited := infra.NewRateLimitedCandleRepository(infra.NewFreeTierCandleRepository(server.URL, server.Client()), infra.RateLimit{PerMinute: 2})
+	repo := infra.NewWindowedCandleRepository(limited, 3)
+
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	_, err := repo.GetSeries(context.Background(), domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), from, from.Add(9*time.Minute))
+	if !errors.Is(err, ports.ErrRateLimited) {
+		t.Fatalf("expected the third window to exceed the budget, got %v", err)
+	}
+	if len(stub.windows) != 2 {
+		t.Fatalf("expected only the budgeted windows to reach the provider, got %v", stub.windows)
+	}
+}
@@ -1,6 +1,7 @@
 package ports_test
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -17,6 +18,7 @@ type FakeCandleRepository struct {
 
 // GetSeries implements CandleRepositoryPort.
 func (f *FakeCandleRepository) GetSeries(
+	_ context.Context,
 	symbol domain.Symbol,
 	timeframe domain.Timeframe,
 	from time.Time,
@@ -50,7 +52,7 @@ func TestCandleRepositoryPort_GetSeriesReturnsCandleSeries(t *testing.T) {
 
 	repo := &FakeCandleRepository{series: series}
 
-	result, err := repo.GetSeries(sym, tf, ts, ts.Add(5*time.Minute))
+	result, err := repo.GetSeries(context.Background(), sym, tf, ts, ts.Add(5*time.Minute))
 
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
@@ -74,7 +76,7 @@ func TestCandleRepositoryPort_GetSeriesAllowsEmptyResult(t *testing.T) {
 
 	repo := &FakeCandleRepository{series: series}
 
-	result, err := repo.GetSeries(sym, tf, ts, ts.Add(5*time.Minute))
+	result, err := repo.GetSeries(context.Background(), sym, tf, ts, ts.Add(5*time.Minute))
 
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
@@ -92,7 +94,7 @@ func TestCandleRepositoryPort_ReturnsErrorOnFailure(t *testing.T) {
 
 	repo := &FakeCandleRepository{shouldErr: true}
 
-	_, err := repo.GetSeries(sym, tf, ts, ts.Add(5*time.Minute))
+	_, err := repo.GetSeries(context.Background(), sym, tf, ts, ts.Add(5*time.Minute))
 
 	if err == nil {
 		t.Error("expected error, got nil")
@@ -113,6 +115,7 @@ func TestCandleRepositoryPort_AcceptsTimeRange(t *testing.T) {
 
 	// Port should accept time range parameters
 	result, err := repo.GetSeries(
+		context.Background(),
 		sym,
 		tf,
 		ts,
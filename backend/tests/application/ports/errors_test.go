@@ -0,0 +1,63 @@
+package ports_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/ports"
+)
+
+func TestUpstreamStatusError_UnwrapsToSentinels(t *testing.T) {
+	cases := []struct {
+		status      int
+		rateLimited bool
+		unavailable bool
+	}{
+		{429, true, false},
+		{500, false, true},
+		{503, false, true},
+		{404, false, false},
+	}
+	for _, c := range cases {
+		err := error(&ports.UpstreamStatusError{StatusCode: c.status})
+		if got := errors.Is(err, ports.ErrRateLimited); got != c.rateLimited {
+			t.Errorf("status %d: errors.Is(ErrRateLimited) = %v, want %v", c.status, got, c.rateLimited)
+		}
+		if got := errors.Is(err, ports.ErrUpstreamUnavailable); got != c.unavailable {
+			t.Errorf("status %d: errors.Is(ErrUpstreamUnavailable) = %v, want %v", c.status, got, c.unavailable)
+		}
+	}
+}
+
+func TestUpstreamStatusError_ExposesStatusCode(t *testing.T) {
+	var err error = &ports.UpstreamStatusError{StatusCode: 418}
+	var se *ports.UpstreamStatusError
+	if !errors.As(err, &se) || se.StatusCode != 418 {
+		t.Fatalf("expected errors.As to recover status 418, got %v", err)
+	}
+}
+
+func TestRateLimitError_UnwrapsToErrRateLimited(t *testing.T) {
+	var err error = &ports.RateLimitError{RetryAfter: 2 * time.Second}
+	if !errors.Is(err, ports.ErrRateLimited) {
+		t.Fatalf("expected errors.Is(ErrRateLimited), got %v", err)
+	}
+}
+
+func TestRetryAfter_ReadsDelayFromErrorChain(t *testing.T) {
+	cases := []struct {
+		err  error
+		want time.Duration
+	}{
+		{fmt.Errorf("wrapped: %w", &ports.RateLimitError{RetryAfter: time.Second}), time.Second},
+		{&ports.UpstreamStatusError{StatusCode: 429, RetryAfter: time.Minute}, time.Minute},
+		{ports.ErrRateLimited, 0},
+	}
+	for _, c := range cases {
+		if got := ports.RetryAfter(c.err); got != c.want {
+			t.Errorf("%v: RetryAfter = %v, want %v", c.err, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package ports_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/ports"
+)
+
+func TestProvenance_CollectsRecordedRanges(t *testing.T) {
+	ctx, ranges := ports.WithProvenance(context.Background())
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	ports.RecordProvenance(ctx, ports.SourceRange{Source: "a", From: from, To: from.Add(time.Minute)})
+	ports.RecordProvenance(ctx, ports.SourceRange{Source: "b", From: from.Add(time.Minute), To: from.Add(2 * time.Minute)})
+
+	got := ranges()
+	if len(got) != 2 || got[0].Source != "a" || got[1].Source != "b" {
+		t.Fatalf("unexpected ranges: %+v", got)
+	}
+}
+
+func TestRecordProvenance_IsNoOpWithoutRecorder(t *testing.T) {
+	ports.RecordProvenance(context.Background(), ports.SourceRange{Source: "a"})
+}
@@ -0,0 +1,23 @@
+package ports_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/akarso/pano_chart/backend/application/ports"
+)
+
+func TestQualityReport_CollectsRecordedIssues(t *testing.T) {
+	ctx, issues := ports.WithQualityReport(context.Background())
+	ports.RecordCandleIssues(ctx, ports.CandleIssue{Reason: "bad timestamp"})
+	ports.RecordCandleIssues(ctx, ports.CandleIssue{Reason: "high below close", Repaired: true})
+
+	got := issues()
+	if len(got) != 2 || got[0].Repaired || !got[1].Repaired {
+		t.Fatalf("unexpected issues: %+v", got)
+	}
+}
+
+func TestRecordCandleIssues_IsNoOpWithoutReport(t *testing.T) {
+	ports.RecordCandleIssues(context.Background(), ports.CandleIssue{Reason: "bad timestamp"})
+}
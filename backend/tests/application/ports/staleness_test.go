@@ -0,0 +1,23 @@
+package ports_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/akarso/pano_chart/backend/application/ports"
+)
+
+func TestStaleTracking_RecordsMarkStale(t *testing.T) {
+	ctx, stale := ports.WithStaleTracking(context.Background())
+	if stale() {
+		t.Fatal("expected a new tracker to report fresh data")
+	}
+	ports.MarkStale(ctx)
+	if !stale() {
+		t.Fatal("expected MarkStale to be recorded")
+	}
+}
+
+func TestMarkStale_IsNoOpWithoutTracking(t *testing.T) {
+	ports.MarkStale(context.Background())
+}
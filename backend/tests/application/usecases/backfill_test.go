@@ -0,0 +1,228 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/application/usecases"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// hourlyRepo serves one candle per hour of the requested range, except for the hours in
+// skip, after failing with errs in order; it records the start of every call.
+type hourlyRepo struct {
+	errs  []error
+	skip  map[time.Time]bool
+	froms []time.Time
+}
+
+func (f *hourlyRepo) GetSeries(_ context.Context, sym domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+	f.froms = append(f.froms, from)
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		if err != nil {
+			return domain.CandleSeries{}, err
+		}
+	}
+	var candles []domain.Candle
+	for ts := from; ts.Before(to); ts = ts.Add(tf.Duration()) {
+		if !f.skip[ts] {
+			candles = append(candles, domain.NewCandleUnsafe(sym, tf, ts, 100, 110, 90, 105, 1000))
+		}
+	}
+	return domain.NewCandleSeries(sym, tf, candles)
+}
+
+// memoryStore is an in-memory ports.CandleStorePort for a single series.
+type memoryStore struct {
+	candles map[time.Time]domain.Candle
+	covered []ports.TimeRange
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{candles: make(map[time.Time]domain.Candle)}
+}
+
+func (m *memoryStore) GetSeries(_ context.Context, sym domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+	var candles []domain.Candle
+	for ts, c := range m.candles {
+		if !ts.Before(from) && ts.Before(to) {
+			candles = append(candles, c)
+		}
+	}
+	return domain.NewCandleSeries(sym, tf, candles)
+}
+
+func (m *memoryStore) Upsert(_ context.Context, series domain.CandleSeries, covered ports.TimeRange) error {
+	for _, c := range series.All() {
+		m.candles[c.Timestamp()] = c
+	}
+	m.covered = append(m.covered, covered)
+	return nil
+}
+
+func (m *memoryStore) Covered(context.Context, domain.Symbol, domain.Timeframe, time.Time, time.Time) ([]ports.TimeRange, error) {
+	return m.covered, nil
+}
+
+// memoryCheckpoints is an in-memory ports.CheckpointPort.
+type memoryCheckpoints map[string]time.Time
+
+func (m memoryCheckpoints) Load(_ context.Context, key string) (time.Time, bool, error) {
+	t, ok := m[key]
+	return t, ok, nil
+}
+
+func (m memoryCheckpoints) Save(_ context.Context, key string, progress time.Time) error {
+	m[key] = progress
+	return nil
+}
+
+var backfillFrom = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func backfillRequest(hours int) usecases.BackfillRequest {
+	return usecases.BackfillRequest{
+		Symbols:    []domain.Symbol{domain.NewSymbolUnsafe("BTC")},
+		Timeframes: []domain.Timeframe{domain.Timeframe1h},
+		From:       backfillFrom,
+		To:         backfillFrom.Add(time.Duration(hours) * time.Hour),
+	}
+}
+
+func TestBackfill_WalksHistoryInChunks(t *testing.T) {
+	repo := &hourlyRepo{}
+	store := newMemoryStore()
+	uc := usecases.NewBackfill(repo, store, memoryCheckpoints{}, 4)
+
+	results, err := uc.Execute(context.Background(), backfillRequest(10))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.froms) != 3 {
+		t.Fatalf("expected 3 chunks, got %d", len(repo.froms))
+	}
+	if len(store.candles) != 10 || len(store.covered) != 3 {
+		t.Fatalf("expected 10 stored candles in 3 covered ranges, got %d and %d", len(store.candles), len(store.covered))
+	}
+	res := results[0]
+	if res.Err != nil || res.Candles != 10 || !res.Through.Equal(backfillFrom.Add(10*time.Hour)) {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+}
+
+func TestBackfill_ResumesFromCheckpoint(t *testing.T) {
+	store := newMemoryStore()
+	checkpoints := memoryCheckpoints{}
+
+	failing := &hourlyRepo{errs: []error{nil, ports.ErrUpstreamUnavailable}}
+	results, err := usecases.NewBackfill(failing, store, checkpoints, 4).Execute(context.Background(), backfillRequest(10))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res := results[0]; !errors.Is(res.Err, ports.ErrUpstreamUnavailable) || !res.Through.Equal(backfillFrom.Add(4*time.Hour)) {
+		t.Fatalf("expected the series to stop after the first chunk, got %+v", res)
+	}
+
+	repo := &hourlyRepo{}
+	results, err = usecases.NewBackfill(repo, store, checkpoints, 4).Execute(context.Background(), backfillRequest(10))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !repo.froms[0].Equal(backfillFrom.Add(4 * time.Hour)) {
+		t.Fatalf("expected the run to resume at 04:00, got %v", repo.froms[0])
+	}
+	if res := results[0]; !res.Resumed || res.Err != nil || res.Candles != 6 || len(store.candles) != 10 {
+		t.Fatalf("unexpected result after resuming: %+v", res)
+	}
+}
+
+func TestBackfill_WaitsOutRateLimits(t *testing.T) {
+	repo := &hourlyRepo{errs: []error{&ports.RateLimitError{RetryAfter: time.Millisecond}}}
+	uc := usecases.NewBackfill(repo, newMemoryStore(), memoryCheckpoints{}, 4)
+
+	results, err := uc.Execute(context.Background(), backfillRequest(4))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results[0].Err != nil || len(repo.froms) != 2 {
+		t.Fatalf("expected the rate-limited chunk to be retried, got %+v after %d calls", results[0], len(repo.froms))
+	}
+}
+
+func TestBackfill_SkipsCandlesThatHaveNotClosed(t *testing.T) {
+	repo := &hourlyRepo{}
+	uc := usecases.NewBackfill(repo, newMemoryStore(), memoryCheckpoints{}, 4)
+
+	req := backfillRequest(1)
+	req.From, req.To = time.Now().Add(time.Hour), time.Now().Add(2*time.Hour)
+	results, err := uc.Execute(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.froms) != 0 || results[0].Candles != 0 {
+		t.Fatalf("expected future ranges to be skipped, got %d calls", len(repo.froms))
+	}
+}
+
+func TestBackfill_RejectsEmptyRequest(t *testing.T) {
+	uc := usecases.NewBackfill(&hourlyRepo{}, newMemoryStore(), memoryCheckpoints{}, 0)
+	req := backfillRequest(1)
+	req.Timeframes = nil
+	if _, err := uc.Execute(context.Background(), req); !errors.Is(err, domain.ErrInvalidTimeframe) {
+		t.Fatalf("expected ErrInvalidTimeframe, got %v", err)
+	}
+}
+
+func TestBackfill_ExtendingTheRangeResumes(t *testing.T) {
+	store := newMemoryStore()
+	checkpoints := memoryCheckpoints{}
+	if _, err := usecases.NewBackfill(&hourlyRepo{}, store, checkpoints, 4).Execute(context.Background(), backfillRequest(4)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	repo := &hourlyRepo{}
+	results, err := usecases.NewBackfill(repo, store, checkpoints, 4).Execute(context.Background(), backfillRequest(6))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.froms) != 1 || !repo.froms[0].Equal(backfillFrom.Add(4*time.Hour)) || results[0].Candles != 2 {
+		t.Fatalf("expected only the extension to be fetched, got %v and %+v", repo.froms, results[0])
+	}
+}
+
+func TestBackfill_CoversGapsUpToTheLastCandle(t *testing.T) {
+	hour := func(h int) time.Time { return backfillFrom.Add(time.Duration(h) * time.Hour) }
+	// The second chunk is empty and the third lacks 09:00 and ends early at 11:00.
+	repo := &hourlyRepo{skip: map[time.Time]bool{hour(4): true, hour(5): true, hour(6): true, hour(7): true, hour(9): true, hour(11): true}}
+	store := newMemoryStore()
+	checkpoints := memoryCheckpoints{}
+
+	results, err := usecases.NewBackfill(repo, store, checkpoints, 4).Execute(context.Background(), backfillRequest(12))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []ports.TimeRange{{From: hour(0), To: hour(4)}, {From: hour(4), To: hour(11)}}
+	if len(store.covered) != len(want) {
+		t.Fatalf("expected covered %v, got %v", want, store.covered)
+	}
+	for i := range want {
+		if !store.covered[i].From.Equal(want[i].From) || !store.covered[i].To.Equal(want[i].To) {
+			t.Fatalf("expected covered %v, got %v", want, store.covered)
+		}
+	}
+	if res := results[0]; res.Candles != 6 || res.Missing != 6 || !res.Through.Equal(hour(11)) {
+		t.Fatalf("expected 6 candles, 6 missing and history through 11:00, got %+v", res)
+	}
+
+	resumed := &hourlyRepo{}
+	if _, err := usecases.NewBackfill(resumed, store, checkpoints, 4).Execute(context.Background(), backfillRequest(12)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resumed.froms) != 1 || !resumed.froms[0].Equal(hour(11)) {
+		t.Fatalf("expected the uncovered tail to be fetched again, got %v", resumed.froms)
+	}
+}
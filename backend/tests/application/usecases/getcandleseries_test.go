@@ -1,6 +1,7 @@
 package usecases_test
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -12,6 +13,7 @@ import (
 // fakeRepo records calls and can be configured to return a series or an error.
 type fakeRepo struct {
 	called   bool
+	lastCtx  context.Context
 	lastSym  domain.Symbol
 	lastTf   domain.Timeframe
 	lastFrom time.Time
@@ -20,8 +22,9 @@ type fakeRepo struct {
 	err      error
 }
 
-func (f *fakeRepo) GetSeries(sym domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+func (f *fakeRepo) GetSeries(ctx context.Context, sym domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
 	f.called = true
+	f.lastCtx = ctx
 	f.lastSym = sym
 	f.lastTf = tf
 	f.lastFrom = from
@@ -41,7 +44,7 @@ func TestGetCandleSeries_DelegatesToRepository(t *testing.T) {
 	repo := &fakeRepo{}
 	uc := usecases.NewGetCandleSeries(repo)
 
-	_, _ = uc.Execute(sym, tf, from, to)
+	_, _ = uc.Execute(context.Background(), sym, tf, from, to)
 
 	if !repo.called {
 		t.Fatal("expected repository to be called")
@@ -69,7 +72,7 @@ func TestGetCandleSeries_ReturnsCandleSeries(t *testing.T) {
 	repo := &fakeRepo{series: series}
 	uc := usecases.NewGetCandleSeries(repo)
 
-	res, err := uc.Execute(sym, tf, from, to)
+	res, err := uc.Execute(context.Background(), sym, tf, from, to)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -88,7 +91,7 @@ func TestGetCandleSeries_PropagatesRepositoryError(t *testing.T) {
 	repo := &fakeRepo{err: repoErr}
 	uc := usecases.NewGetCandleSeries(repo)
 
-	_, err := uc.Execute(sym, tf, from, to)
+	_, err := uc.Execute(context.Background(), sym, tf, from, to)
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -96,3 +99,34 @@ func TestGetCandleSeries_PropagatesRepositoryError(t *testing.T) {
 		t.Fatalf("expected error to be propagated unchanged")
 	}
 }
+
+func TestGetCandleSeries_RejectsInvertedRange(t *testing.T) {
+	sym := domain.NewSymbolUnsafe("BTC")
+	tf := domain.NewTimeframeUnsafe("1m")
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	repo := &fakeRepo{}
+	uc := usecases.NewGetCandleSeries(repo)
+
+	_, err := uc.Execute(context.Background(), sym, tf, from, from.Add(-time.Minute))
+	if !errors.Is(err, domain.ErrInvalidRange) {
+		t.Fatalf("expected ErrInvalidRange, got %v", err)
+	}
+	if repo.called {
+		t.Fatal("did not expect repository to be called for an invalid range")
+	}
+}
+
+func TestGetCandleSeries_ForwardsContextToRepository(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "trace-1")
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	repo := &fakeRepo{}
+	uc := usecases.NewGetCandleSeries(repo)
+	_, _ = uc.Execute(ctx, domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), from, from.Add(time.Minute))
+
+	if repo.lastCtx == nil || repo.lastCtx.Value(key{}) != "trace-1" {
+		t.Fatal("expected the caller's context to reach the repository")
+	}
+}
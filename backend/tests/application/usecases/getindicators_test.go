@@ -0,0 +1,100 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/usecases"
+	"github.com/akarso/pano_chart/backend/domain"
+	"github.com/akarso/pano_chart/backend/domain/indicators"
+)
+
+// rangeRepo returns a contiguous series of closes 1, 2, 3, ... covering the requested range.
+type rangeRepo struct {
+	lastFrom time.Time
+	lastTo   time.Time
+	err      error
+}
+
+func (f *rangeRepo) GetSeries(_ context.Context, sym domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+	f.lastFrom, f.lastTo = from, to
+	if f.err != nil {
+		return domain.CandleSeries{}, f.err
+	}
+	var candles []domain.Candle
+	v := 1.0
+	for ts := from; ts.Before(to); ts = ts.Add(tf.Duration()) {
+		candles = append(candles, domain.NewCandleUnsafe(sym, tf, ts, v, v, v, v, 1))
+		v++
+	}
+	return domain.NewCandleSeries(sym, tf, candles)
+}
+
+func mustSpec(t *testing.T, s string) indicators.Spec {
+	t.Helper()
+	spec, err := indicators.ParseSpec(s)
+	if err != nil {
+		t.Fatalf("failed to parse spec %q: %v", s, err)
+	}
+	return spec
+}
+
+func TestGetIndicators_FetchesWarmUpAndTrimsToRange(t *testing.T) {
+	sym := domain.NewSymbolUnsafe("BTC")
+	tf := domain.Timeframe1m
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	to := from.Add(5 * time.Minute)
+
+	repo := &rangeRepo{}
+	uc := usecases.NewGetIndicators(repo)
+
+	res, err := uc.Execute(context.Background(), sym, tf, from, to, []indicators.Spec{mustSpec(t, "sma:3"), mustSpec(t, "rsi:4")}, indicators.GapReset)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !repo.lastFrom.Equal(from.Add(-4*time.Minute)) || !repo.lastTo.Equal(to) {
+		t.Fatalf("expected fetch widened by the longest warm-up, got %v..%v", repo.lastFrom, repo.lastTo)
+	}
+	if res.Series.Len() != 5 {
+		t.Fatalf("expected 5 candles in range, got %d", res.Series.Len())
+	}
+	first, _ := res.Series.First()
+	if !first.Timestamp().Equal(from) {
+		t.Fatalf("expected candles trimmed to from, got %v", first.Timestamp())
+	}
+	if len(res.Indicators) != 2 {
+		t.Fatalf("expected 2 indicator outputs, got %d", len(res.Indicators))
+	}
+	for _, out := range res.Indicators {
+		for _, line := range out.Lines {
+			if len(line.Points) != 5 {
+				t.Fatalf("%s: expected 5 aligned points, got %d", out.Spec, len(line.Points))
+			}
+			if !line.Points[0].Valid {
+				t.Fatalf("%s: expected first requested point to be warmed up", out.Spec)
+			}
+		}
+	}
+	// closes in range are 5..9; SMA(3) at the first requested candle is (3+4+5)/3.
+	if v := res.Indicators[0].Lines[0].Points[0].Value; v != 4 {
+		t.Fatalf("expected SMA 4, got %v", v)
+	}
+}
+
+func TestGetIndicators_PropagatesErrors(t *testing.T) {
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	repoErr := errors.New("repository failure")
+	uc := usecases.NewGetIndicators(&rangeRepo{err: repoErr})
+
+	_, err := uc.Execute(context.Background(), domain.NewSymbolUnsafe("BTC"), domain.Timeframe1m, from, from.Add(time.Minute), []indicators.Spec{mustSpec(t, "sma")}, indicators.GapReset)
+	if err != repoErr {
+		t.Fatalf("expected repository error to be propagated unchanged, got %v", err)
+	}
+
+	_, err = uc.Execute(context.Background(), domain.NewSymbolUnsafe("BTC"), domain.Timeframe1m, from, from, nil, indicators.GapReset)
+	if !errors.Is(err, domain.ErrInvalidRange) {
+		t.Fatalf("expected ErrInvalidRange, got %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/usecases"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// overviewRepo returns a per-symbol series or error and tracks peak concurrency.
+type overviewRepo struct {
+	mu       sync.Mutex
+	series   map[domain.Symbol]domain.CandleSeries
+	errs     map[domain.Symbol]error
+	delay    time.Duration
+	inFlight int32
+	peak     int32
+	calls    int32
+}
+
+func (f *overviewRepo) GetSeries(_ context.Context, sym domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+	atomic.AddInt32(&f.calls, 1)
+	n := atomic.AddInt32(&f.inFlight, 1)
+	defer atomic.AddInt32(&f.inFlight, -1)
+	f.mu.Lock()
+	if n > f.peak {
+		f.peak = n
+	}
+	f.mu.Unlock()
+
+	time.Sleep(f.delay)
+
+	if err := f.errs[sym]; err != nil {
+		return domain.CandleSeries{}, err
+	}
+	return f.series[sym], nil
+}
+
+func oneCandleSeries(sym domain.Symbol, tf domain.Timeframe, ts time.Time) domain.CandleSeries {
+	c := domain.NewCandleUnsafe(sym, tf, ts, 100, 110, 90, 105, 1000)
+	s, _ := domain.NewCandleSeries(sym, tf, []domain.Candle{c})
+	return s
+}
+
+func TestGetOverview_ReturnsEntriesInRequestOrder(t *testing.T) {
+	tf := domain.NewTimeframeUnsafe("1m")
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	syms := []domain.Symbol{domain.NewSymbolUnsafe("ETH"), domain.NewSymbolUnsafe("BTC"), domain.NewSymbolUnsafe("SOL")}
+
+	repo := &overviewRepo{series: map[domain.Symbol]domain.CandleSeries{}}
+	for _, s := range syms {
+		repo.series[s] = oneCandleSeries(s, tf, from)
+	}
+	uc := usecases.NewGetOverview(repo, 2)
+
+	res, err := uc.Execute(context.Background(), syms, tf, from, from.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Timeframe != tf {
+		t.Fatalf("expected timeframe %v, got %v", tf, res.Timeframe)
+	}
+	if len(res.Entries) != len(syms) {
+		t.Fatalf("expected %d entries, got %d", len(syms), len(res.Entries))
+	}
+	for i, e := range res.Entries {
+		if e.Symbol != syms[i] {
+			t.Fatalf("entry %d: expected %v, got %v", i, syms[i], e.Symbol)
+		}
+		if e.Err != nil || e.Series.Len() != 1 {
+			t.Fatalf("entry %d: expected one candle and no error", i)
+		}
+	}
+}
+
+func TestGetOverview_ReportsPerSymbolFailures(t *testing.T) {
+	tf := domain.NewTimeframeUnsafe("1m")
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	btc := domain.NewSymbolUnsafe("BTC")
+	eth := domain.NewSymbolUnsafe("ETH")
+	boom := errors.New("boom")
+
+	repo := &overviewRepo{
+		series: map[domain.Symbol]domain.CandleSeries{btc: oneCandleSeries(btc, tf, from)},
+		errs:   map[domain.Symbol]error{eth: boom},
+	}
+	uc := usecases.NewGetOverview(repo, 4)
+
+	res, err := uc.Execute(context.Background(), []domain.Symbol{btc, eth}, tf, from, from.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("expected per-symbol failure not to fail the call, got %v", err)
+	}
+	if res.Entries[0].Err != nil {
+		t.Fatalf("expected BTC to succeed, got %v", res.Entries[0].Err)
+	}
+	if res.Entries[1].Err != boom {
+		t.Fatalf("expected ETH error to be propagated unchanged, got %v", res.Entries[1].Err)
+	}
+}
+
+func TestGetOverview_BoundsConcurrency(t *testing.T) {
+	tf := domain.NewTimeframeUnsafe("1m")
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	syms := make([]domain.Symbol, 0, 12)
+	for _, s := range []string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "J", "K", "L"} {
+		syms = append(syms, domain.NewSymbolUnsafe(s))
+	}
+
+	repo := &overviewRepo{delay: 10 * time.Millisecond}
+	uc := usecases.NewGetOverview(repo, 3)
+
+	if _, err := uc.Execute(context.Background(), syms, tf, from, from.Add(time.Minute)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.calls != int32(len(syms)) {
+		t.Fatalf("expected %d repository calls, got %d", len(syms), repo.calls)
+	}
+	if repo.peak > 3 {
+		t.Fatalf("expected at most 3 concurrent calls, got %d", repo.peak)
+	}
+	if repo.peak < 2 {
+		t.Fatalf("expected calls to run concurrently, peak was %d", repo.peak)
+	}
+}
+
+func TestGetOverview_RejectsEmptySymbolList(t *testing.T) {
+	uc := usecases.NewGetOverview(&overviewRepo{}, 0)
+
+	_, err := uc.Execute(context.Background(), nil, domain.NewTimeframeUnsafe("1m"), time.Now(), time.Now())
+	if err == nil {
+		t.Fatal("expected error for empty symbol list")
+	}
+}
+
+func TestGetOverview_AttachesRangeAnalysisWhenSeriesIsLongEnough(t *testing.T) {
+	tf := domain.NewTimeframeUnsafe("1m")
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	btc := domain.NewSymbolUnsafe("BTC")
+	eth := domain.NewSymbolUnsafe("ETH")
+
+	candles := make([]domain.Candle, 40)
+	for i := range candles {
+		mid := 100.0 + float64(i%4)
+		candles[i] = domain.NewCandleUnsafe(btc, tf, from.Add(time.Duration(i)*time.Minute), mid, mid+1, mid-1, mid, 1)
+	}
+	long, _ := domain.NewCandleSeries(btc, tf, candles)
+
+	repo := &overviewRepo{series: map[domain.Symbol]domain.CandleSeries{
+		btc: long,
+		eth: oneCandleSeries(eth, tf, from),
+	}}
+	uc := usecases.NewGetOverview(repo, 2)
+
+	res, err := uc.Execute(context.Background(), []domain.Symbol{btc, eth}, tf, from, from.Add(40*time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r := res.Entries[0].Range; r == nil || r.Candles != 40 || r.Score <= 0 {
+		t.Fatalf("expected range analysis over 40 candles, got %+v", r)
+	}
+	if res.Entries[1].Range != nil {
+		t.Fatalf("expected no range analysis for a one-candle series, got %+v", res.Entries[1].Range)
+	}
+}
+
+func TestGetOverview_StopsWhenContextIsCanceled(t *testing.T) {
+	tf := domain.NewTimeframeUnsafe("1m")
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	syms := []domain.Symbol{domain.NewSymbolUnsafe("A"), domain.NewSymbolUnsafe("B"), domain.NewSymbolUnsafe("C")}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	repo := &overviewRepo{}
+	uc := usecases.NewGetOverview(repo, 1)
+
+	_, err := uc.Execute(ctx, syms, tf, from, from.Add(time.Minute))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if repo.calls != 0 {
+		t.Fatalf("expected no repository calls after cancellation, got %d", repo.calls)
+	}
+}
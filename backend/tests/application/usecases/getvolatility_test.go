@@ -0,0 +1,82 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/usecases"
+	"github.com/akarso/pano_chart/backend/domain"
+	"github.com/akarso/pano_chart/backend/domain/indicators"
+)
+
+// swingSeries builds n daily candles alternating between two closes.
+func swingSeries(sym domain.Symbol, from time.Time, n int) domain.CandleSeries {
+	tf := domain.NewTimeframeUnsafe("1d")
+	candles := make([]domain.Candle, n)
+	for i := range candles {
+		c := 100.0 + float64(i%2)*5
+		candles[i] = domain.NewCandleUnsafe(sym, tf, from.Add(time.Duration(i)*24*time.Hour), c, c+2, c-2, c, 1)
+	}
+	s, _ := domain.NewCandleSeries(sym, tf, candles)
+	return s
+}
+
+func TestGetVolatility_ComputesRequestedEstimatorsPerSymbol(t *testing.T) {
+	tf := domain.NewTimeframeUnsafe("1d")
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	btc := domain.NewSymbolUnsafe("BTC")
+	eth := domain.NewSymbolUnsafe("ETH")
+	sol := domain.NewSymbolUnsafe("SOL")
+	boom := errors.New("boom")
+
+	repo := &overviewRepo{
+		series: map[domain.Symbol]domain.CandleSeries{
+			btc: swingSeries(btc, from, 10),
+			sol: oneCandleSeries(sol, tf, from),
+		},
+		errs: map[domain.Symbol]error{eth: boom},
+	}
+	uc := usecases.NewGetVolatility(repo, 2)
+	ests := []indicators.Estimator{indicators.Parkinson, indicators.CloseToClose}
+
+	res, err := uc.Execute(context.Background(), []domain.Symbol{btc, eth, sol}, tf, from, from.Add(10*24*time.Hour), ests)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(res.Entries))
+	}
+
+	b := res.Entries[0]
+	if b.Err != nil || b.Candles != 10 || len(b.Values) != 2 {
+		t.Fatalf("unexpected BTC entry: %+v", b)
+	}
+	for i, v := range b.Values {
+		if v.Estimator != ests[i] || !v.Valid || v.Value <= 0 {
+			t.Fatalf("BTC value %d: unexpected %+v", i, v)
+		}
+	}
+	if res.Entries[1].Err != boom || res.Entries[1].Values != nil {
+		t.Fatalf("expected ETH to carry the repository error, got %+v", res.Entries[1])
+	}
+	s := res.Entries[2]
+	if s.Values[0].Valid || s.Values[1].Valid {
+		t.Fatalf("expected no valid values for a single candle, got %+v", s.Values)
+	}
+}
+
+func TestGetVolatility_RejectsEmptyInput(t *testing.T) {
+	uc := usecases.NewGetVolatility(&overviewRepo{}, 0)
+	tf := domain.NewTimeframeUnsafe("1d")
+	now := time.Now()
+
+	if _, err := uc.Execute(context.Background(), nil, tf, now, now.Add(time.Hour), indicators.Estimators); !errors.Is(err, domain.ErrInvalidSymbol) {
+		t.Errorf("expected ErrInvalidSymbol, got %v", err)
+	}
+	syms := []domain.Symbol{domain.NewSymbolUnsafe("BTC")}
+	if _, err := uc.Execute(context.Background(), syms, tf, now, now.Add(time.Hour), nil); !errors.Is(err, indicators.ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter, got %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package usecases_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/application/usecases"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// formingRepo serves, for every minute of the requested range, a candle closing at
+// close; it records the start of every call.
+type formingRepo struct {
+	close float64
+	froms []time.Time
+}
+
+func (f *formingRepo) GetSeries(_ context.Context, sym domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+	f.froms = append(f.froms, from)
+	var candles []domain.Candle
+	for ts := from; ts.Before(to); ts = ts.Add(tf.Duration()) {
+		candles = append(candles, domain.NewCandleUnsafe(sym, tf, ts, 100, 110, 90, f.close, 1000))
+	}
+	return domain.NewCandleSeries(sym, tf, candles)
+}
+
+func TestPollForming_PublishesChangesThenClosesTheCandle(t *testing.T) {
+	tf := domain.NewTimeframeUnsafe("1m")
+	repo := &formingRepo{close: 101}
+	publisher := &fakePublisher{watched: []ports.SeriesKey{{Symbol: domain.NewSymbolUnsafe("BTC"), Timeframe: tf}}}
+	uc := usecases.NewPollForming(repo, publisher, time.Second, 1)
+	bucket := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, now := range []time.Time{bucket.Add(10 * time.Second), bucket.Add(20 * time.Second)} {
+		if err := uc.Execute(context.Background(), now); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(publisher.updates) != 1 || publisher.updates[0].Closed {
+		t.Fatalf("expected one forming update while the candle is unchanged, got %+v", publisher.updates)
+	}
+
+	repo.close = 102
+	if err := uc.Execute(context.Background(), bucket.Add(time.Minute+5*time.Second)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !repo.froms[2].Equal(bucket) {
+		t.Fatalf("expected the previous candle to be fetched again, got from %v", repo.froms[2])
+	}
+	if len(publisher.updates) != 3 {
+		t.Fatalf("expected the closed and the new forming candle, got %+v", publisher.updates)
+	}
+	closed, forming := publisher.updates[1], publisher.updates[2]
+	if !closed.Closed || !closed.Candle.Timestamp().Equal(bucket) || closed.Candle.Close() != 102 {
+		t.Fatalf("expected the 12:00 candle's final state as closed, got %+v", closed)
+	}
+	if forming.Closed || !forming.Candle.Timestamp().Equal(bucket.Add(time.Minute)) {
+		t.Fatalf("expected the 12:01 candle as forming, got %+v", forming)
+	}
+
+	// Once closed, the candle is not fetched again.
+	if err := uc.Execute(context.Background(), bucket.Add(time.Minute+10*time.Second)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !repo.froms[3].Equal(bucket.Add(time.Minute)) || len(publisher.updates) != 3 {
+		t.Fatalf("expected only the unchanged forming candle to be polled, got from %v and %d updates", repo.froms[3], len(publisher.updates))
+	}
+}
+
+func TestPollForming_PollsOnlyWatchedSeries(t *testing.T) {
+	repo := &formingRepo{close: 101}
+	uc := usecases.NewPollForming(repo, &fakePublisher{}, time.Second, 1)
+
+	if err := uc.Execute(context.Background(), syncNow); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.froms) != 0 {
+		t.Fatalf("expected no provider calls without subscribers, got %d", len(repo.froms))
+	}
+}
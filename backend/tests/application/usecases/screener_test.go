@@ -0,0 +1,190 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/usecases"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// hourlySeries builds contiguous 1h candles ending just before to, one per close.
+// Every candle has volume 1 except the last, which has lastVolume.
+func hourlySeries(sym domain.Symbol, to time.Time, lastVolume float64, closes ...float64) domain.CandleSeries {
+	tf := domain.NewTimeframeUnsafe("1h")
+	start := to.Add(-time.Duration(len(closes)) * time.Hour)
+	candles := make([]domain.Candle, len(closes))
+	for i, c := range closes {
+		vol := 1.0
+		if i == len(closes)-1 {
+			vol = lastVolume
+		}
+		candles[i] = domain.NewCandleUnsafe(sym, tf, start.Add(time.Duration(i)*time.Hour), c, c+1, c-1, c, vol)
+	}
+	s, _ := domain.NewCandleSeries(sym, tf, candles)
+	return s
+}
+
+func linear(n int, start, step float64) []float64 {
+	v := make([]float64, n)
+	for i := range v {
+		v[i] = start + step*float64(i)
+	}
+	return v
+}
+
+func TestScreener_ComputesMetrics(t *testing.T) {
+	to := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	btc := domain.NewSymbolUnsafe("BTC")
+	repo := &overviewRepo{series: map[domain.Symbol]domain.CandleSeries{
+		btc: hourlySeries(btc, to, 4, linear(30, 100, 1)...),
+	}}
+	uc := usecases.NewScreener(repo, 2)
+
+	res, err := uc.Execute(context.Background(), usecases.ScreenerQuery{Symbols: []domain.Symbol{btc}, Timeframe: domain.NewTimeframeUnsafe("1h"), Lookback: 30, To: to})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.From.Equal(to.Add(-30*time.Hour)) || !res.To.Equal(to) {
+		t.Fatalf("unexpected window [%v, %v)", res.From, res.To)
+	}
+	m := res.Rows[0].Metrics
+	want := map[usecases.Metric]float64{
+		usecases.MetricPrice:       129,
+		usecases.MetricChange:      29,
+		usecases.MetricChange24h:   (129.0/105 - 1) * 100,
+		usecases.MetricVolumeSurge: 4,
+	}
+	for metric, w := range want {
+		if math.Abs(m[metric]-w) > 1e-9 {
+			t.Errorf("%s: expected %v, got %v", metric, w, m[metric])
+		}
+	}
+	for _, metric := range []usecases.Metric{usecases.MetricVolatility, usecases.MetricRangeScore, usecases.MetricADX} {
+		if _, ok := m[metric]; !ok {
+			t.Errorf("expected %s to be computed", metric)
+		}
+	}
+}
+
+func TestScreener_FiltersSortsAndLimits(t *testing.T) {
+	to := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	a, b, c, d := domain.NewSymbolUnsafe("A"), domain.NewSymbolUnsafe("B"), domain.NewSymbolUnsafe("C"), domain.NewSymbolUnsafe("D")
+	boom := errors.New("boom")
+	repo := &overviewRepo{
+		series: map[domain.Symbol]domain.CandleSeries{
+			a: hourlySeries(a, to, 1, 100, 110), // +10%
+			b: hourlySeries(b, to, 1, 100, 95),  // -5%
+			c: hourlySeries(c, to, 1, 100, 130), // +30%
+		},
+		errs: map[domain.Symbol]error{d: boom},
+	}
+	uc := usecases.NewScreener(repo, 2)
+	filter, err := usecases.ParseFilter("change > 0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	res, err := uc.Execute(context.Background(), usecases.ScreenerQuery{
+		Symbols:   []domain.Symbol{a, b, c, d},
+		Timeframe: domain.NewTimeframeUnsafe("1h"),
+		Lookback:  2,
+		To:        to,
+		Filter:    filter,
+		SortBy:    usecases.MetricChange,
+		Limit:     1,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Rows) != 1 || res.Rows[0].Symbol != c {
+		t.Fatalf("expected only C, got %+v", res.Rows)
+	}
+	if len(res.Failures) != 1 || res.Failures[0].Symbol != d || res.Failures[0].Err != boom {
+		t.Fatalf("expected D to be reported as a failure, got %+v", res.Failures)
+	}
+}
+
+func TestScreener_SortsMissingMetricsLast(t *testing.T) {
+	to := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	a, b := domain.NewSymbolUnsafe("A"), domain.NewSymbolUnsafe("B")
+	repo := &overviewRepo{series: map[domain.Symbol]domain.CandleSeries{
+		a: hourlySeries(a, to, 1, 100, 101), // too short for change_24h
+		b: hourlySeries(b, to, 1, linear(26, 100, 1)...),
+	}}
+	uc := usecases.NewScreener(repo, 2)
+
+	res, err := uc.Execute(context.Background(), usecases.ScreenerQuery{
+		Symbols: []domain.Symbol{a, b}, Timeframe: domain.NewTimeframeUnsafe("1h"), Lookback: 26, To: to,
+		SortBy: usecases.MetricChange24h, Ascending: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Rows[0].Symbol != b || res.Rows[1].Symbol != a {
+		t.Fatalf("expected B before A, got %v, %v", res.Rows[0].Symbol, res.Rows[1].Symbol)
+	}
+}
+
+func TestScreener_RejectsInvalidQueries(t *testing.T) {
+	uc := usecases.NewScreener(&overviewRepo{}, 0)
+	syms := []domain.Symbol{domain.NewSymbolUnsafe("BTC")}
+	tf := domain.NewTimeframeUnsafe("1h")
+
+	cases := map[string]usecases.ScreenerQuery{
+		"lookback": {Symbols: syms, Timeframe: tf, Lookback: 1},
+		"limit":    {Symbols: syms, Timeframe: tf, Lookback: 10, Limit: -1},
+		"sort":     {Symbols: syms, Timeframe: tf, Lookback: 10, SortBy: "rsi"},
+	}
+	for name, q := range cases {
+		if _, err := uc.Execute(context.Background(), q); !errors.Is(err, usecases.ErrInvalidScreenerQuery) {
+			t.Errorf("%s: expected ErrInvalidScreenerQuery, got %v", name, err)
+		}
+	}
+	if _, err := uc.Execute(context.Background(), usecases.ScreenerQuery{Timeframe: tf, Lookback: 10}); !errors.Is(err, domain.ErrInvalidSymbol) {
+		t.Errorf("expected ErrInvalidSymbol for an empty universe, got %v", err)
+	}
+}
+
+func TestParseFilter_ParsesConjunctions(t *testing.T) {
+	f, err := usecases.ParseFilter("volatility > 0.5 AND change_24h<=-2 and range_score != 1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := usecases.Filter{
+		{Metric: usecases.MetricVolatility, Op: usecases.OpGreater, Value: 0.5},
+		{Metric: usecases.MetricChange24h, Op: usecases.OpLessEqual, Value: -2},
+		{Metric: usecases.MetricRangeScore, Op: usecases.OpNotEqual, Value: 1},
+	}
+	if len(f) != len(want) {
+		t.Fatalf("expected %d conditions, got %+v", len(want), f)
+	}
+	for i := range want {
+		if f[i] != want[i] {
+			t.Errorf("condition %d: expected %+v, got %+v", i, want[i], f[i])
+		}
+	}
+
+	metrics := map[usecases.Metric]float64{usecases.MetricVolatility: 0.6, usecases.MetricChange24h: -3, usecases.MetricRangeScore: 0.4}
+	if !f.Matches(metrics) {
+		t.Error("expected metrics to match")
+	}
+	delete(metrics, usecases.MetricRangeScore)
+	if f.Matches(metrics) {
+		t.Error("expected a missing metric not to match")
+	}
+}
+
+func TestParseFilter_RejectsMalformedInput(t *testing.T) {
+	for _, in := range []string{"volatility", "volatility >> 1", "rsi > 30", "change > abc", "change > 1 OR change < -1"} {
+		if _, err := usecases.ParseFilter(in); !errors.Is(err, usecases.ErrInvalidScreenerQuery) {
+			t.Errorf("%q: expected ErrInvalidScreenerQuery, got %v", in, err)
+		}
+	}
+	if f, err := usecases.ParseFilter("  "); err != nil || f != nil {
+		t.Errorf("expected empty filter, got %v (%v)", f, err)
+	}
+}
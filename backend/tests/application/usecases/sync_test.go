@@ -0,0 +1,214 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/application/ports"
+	"github.com/akarso/pano_chart/backend/application/usecases"
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// refreshCall is one recorded Refresh.
+type refreshCall struct {
+	symbol   domain.Symbol
+	from, to time.Time
+}
+
+// fakeRefresher records refreshes and fails for the symbols in errs. If published is
+// set, candles opening at or after it have not been published yet.
+type fakeRefresher struct {
+	mu        sync.Mutex
+	calls     []refreshCall
+	errs      map[domain.Symbol]error
+	published time.Time
+}
+
+func (f *fakeRefresher) Refresh(_ context.Context, sym domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, refreshCall{symbol: sym, from: from, to: to})
+	return f.errs[sym]
+}
+
+// GetSeries serves one candle per bucket of the requested range.
+func (f *fakeRefresher) GetSeries(_ context.Context, sym domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+	var candles []domain.Candle
+	f.mu.Lock()
+	if !f.published.IsZero() && f.published.Before(to) {
+		to = f.published
+	}
+	f.mu.Unlock()
+	for ts := from; ts.Before(to); ts = ts.Add(tf.Duration()) {
+		candles = append(candles, domain.NewCandleUnsafe(sym, tf, ts, 100, 110, 90, 105, 1000))
+	}
+	return domain.NewCandleSeries(sym, tf, candles)
+}
+
+func (f *fakeRefresher) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.calls)
+}
+
+var syncNow = time.Date(2026, 1, 1, 12, 30, 0, 0, time.UTC)
+
+func TestSyncLatest_LoadsLookbackThenOnlyNewCandles(t *testing.T) {
+	refresher := &fakeRefresher{}
+	btc := domain.NewSymbolUnsafe("BTC")
+	uc := usecases.NewSyncLatest(refresher, memoryCheckpoints{}, usecases.SyncConfig{Universe: []domain.Symbol{btc}, Lookback: 3})
+
+	results, err := uc.Execute(context.Background(), domain.Timeframe1h, syncNow)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	closed := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	if got := refresher.calls[0]; !got.from.Equal(closed.Add(-3*time.Hour)) || !got.to.Equal(closed) {
+		t.Fatalf("expected the first sync to load [09:00, 12:00), got [%v, %v)", got.from, got.to)
+	}
+	if !results[0].Watermark.Equal(closed) {
+		t.Fatalf("expected the watermark to advance to 12:00, got %v", results[0].Watermark)
+	}
+
+	// Nothing new within the same bucket.
+	if _, err := uc.Execute(context.Background(), domain.Timeframe1h, syncNow.Add(10*time.Minute)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if refresher.count() != 1 {
+		t.Fatalf("expected no refresh before the next boundary, got %d calls", refresher.count())
+	}
+
+	if _, err := uc.Execute(context.Background(), domain.Timeframe1h, syncNow.Add(31*time.Minute)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := refresher.calls[1]; !got.from.Equal(closed) || !got.to.Equal(closed.Add(time.Hour)) {
+		t.Fatalf("expected the next sync to load [12:00, 13:00), got [%v, %v)", got.from, got.to)
+	}
+}
+
+func TestSyncLatest_AdvancesOnlyToTheLastLoadedCandle(t *testing.T) {
+	closed := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	// The 11:00 candle has closed but the provider has not returned it yet.
+	refresher := &fakeRefresher{published: closed.Add(-time.Hour)}
+	uc := usecases.NewSyncLatest(refresher, memoryCheckpoints{}, usecases.SyncConfig{Universe: []domain.Symbol{domain.NewSymbolUnsafe("BTC")}, Lookback: 3})
+
+	results, err := uc.Execute(context.Background(), domain.Timeframe1h, syncNow)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res := results[0]; res.Err != nil || !res.Watermark.Equal(closed.Add(-time.Hour)) {
+		t.Fatalf("expected the watermark to stop at 11:00, got %+v", res)
+	}
+
+	refresher.published = time.Time{}
+	if _, err := uc.Execute(context.Background(), domain.Timeframe1h, syncNow.Add(time.Hour)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := refresher.calls[1]; !got.from.Equal(closed.Add(-time.Hour)) {
+		t.Fatalf("expected the missing candle to be refreshed again, got from %v", got.from)
+	}
+}
+
+func TestSyncLatest_FailureKeepsWatermark(t *testing.T) {
+	btc, eth := domain.NewSymbolUnsafe("BTC"), domain.NewSymbolUnsafe("ETH")
+	refresher := &fakeRefresher{errs: map[domain.Symbol]error{eth: ports.ErrUpstreamUnavailable}}
+	watermarks := memoryCheckpoints{}
+	uc := usecases.NewSyncLatest(refresher, watermarks, usecases.SyncConfig{Universe: []domain.Symbol{btc, eth}, Lookback: 3, Concurrency: 1})
+
+	results, err := uc.Execute(context.Background(), domain.Timeframe1h, syncNow)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results[0].Err != nil || !errors.Is(results[1].Err, ports.ErrUpstreamUnavailable) {
+		t.Fatalf("expected only ETH to fail, got %+v", results)
+	}
+	if len(watermarks) != 1 {
+		t.Fatalf("expected only BTC's watermark to be recorded, got %v", watermarks)
+	}
+
+	delete(refresher.errs, eth)
+	if _, err := uc.Execute(context.Background(), domain.Timeframe1h, syncNow.Add(time.Hour)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, c := range refresher.calls[2:] {
+		if c.symbol == eth && !c.from.Equal(time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)) {
+			t.Fatalf("expected ETH to retry its lookback, got from %v", c.from)
+		}
+	}
+}
+
+func TestSyncLatest_RunSyncsAtStartupUntilCancelled(t *testing.T) {
+	refresher := &fakeRefresher{}
+	uc := usecases.NewSyncLatest(refresher, memoryCheckpoints{}, usecases.SyncConfig{
+		Universe:   []domain.Symbol{domain.NewSymbolUnsafe("BTC")},
+		Timeframes: []domain.Timeframe{domain.Timeframe1h, domain.Timeframe1d},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		uc.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for refresher.count() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if refresher.count() != 2 {
+		t.Fatalf("expected a startup sync per timeframe, got %d", refresher.count())
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected Run to return once cancelled")
+	}
+}
+
+// fakePublisher records published updates and reports watched as subscribed.
+type fakePublisher struct {
+	mu      sync.Mutex
+	updates []ports.CandleUpdate
+	watched []ports.SeriesKey
+}
+
+func (f *fakePublisher) Publish(update ports.CandleUpdate) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.updates = append(f.updates, update)
+}
+
+func (f *fakePublisher) Watched() []ports.SeriesKey { return f.watched }
+
+func TestSyncLatest_PublishesNewlySyncedCandles(t *testing.T) {
+	publisher := &fakePublisher{}
+	uc := usecases.NewSyncLatest(&fakeRefresher{}, memoryCheckpoints{}, usecases.SyncConfig{
+		Universe:  []domain.Symbol{domain.NewSymbolUnsafe("BTC")},
+		Lookback:  3,
+		Publisher: publisher,
+	})
+
+	// The first sync loads 3 candles but publishes only the latest.
+	if _, err := uc.Execute(context.Background(), domain.Timeframe1h, syncNow); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(publisher.updates) != 1 || !publisher.updates[0].Candle.Timestamp().Equal(time.Date(2026, 1, 1, 11, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the 11:00 candle to be published, got %+v", publisher.updates)
+	}
+
+	if _, err := uc.Execute(context.Background(), domain.Timeframe1h, syncNow.Add(2*time.Hour)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(publisher.updates) != 3 {
+		t.Fatalf("expected the 12:00 and 13:00 candles to follow, got %d updates", len(publisher.updates))
+	}
+	for _, u := range publisher.updates {
+		if !u.Closed {
+			t.Fatalf("expected synced candles to be published as closed, got %+v", u)
+		}
+	}
+}
@@ -1,11 +1,17 @@
 package composition_test
 
 import (
+	"bufio"
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/akarso/pano_chart/backend/adapters/infra"
+	"github.com/akarso/pano_chart/backend/application/ports"
 	"github.com/akarso/pano_chart/backend/cmd/server"
 	"github.com/akarso/pano_chart/backend/domain"
 )
@@ -17,7 +23,7 @@ type fakeRepo struct {
 	err    error
 }
 
-func (f *fakeRepo) GetSeries(sym domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+func (f *fakeRepo) GetSeries(_ context.Context, sym domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
 	f.called = true
 	if f.err != nil {
 		return domain.CandleSeries{}, f.err
@@ -34,13 +40,13 @@ type fakeRedis struct {
 	lastTTL time.Duration
 }
 
-func (f *fakeRedis) Get(key string) ([]byte, error) {
+func (f *fakeRedis) Get(_ context.Context, key string) ([]byte, error) {
 	if f.getErr != nil { return nil, f.getErr }
 	b, ok := f.store[key]
 	if !ok { return nil, http.ErrNoLocation }
 	return b, nil
 }
-func (f *fakeRedis) Set(key string, value []byte, ttl time.Duration) error {
+func (f *fakeRedis) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
 	if f.setErr != nil { return f.setErr }
 	if f.store == nil { f.store = make(map[string][]byte) }
 	f.store[key] = value
@@ -121,3 +127,300 @@ func TestComposition_HTTPHandlerIsReachable(t *testing.T) {
 		t.Fatalf("expected status 200, got %d", res.StatusCode)
 	}
 }
+
+func TestComposition_OverviewHandlerIsReachable(t *testing.T) {
+	series, _ := domain.NewCandleSeries(domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), []domain.Candle{})
+	fake := &fakeRepo{series: series}
+	h, err := server.NewApp(server.Config{Repo: fake})
+	if err != nil {
+		t.Fatalf("failed to wire app: %v", err)
+	}
+
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/api/v1/overview?symbols=BTC&timeframe=1m")
+	if err != nil {
+		t.Fatalf("failed to reach handler: %v", err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", res.StatusCode)
+	}
+	if !fake.called {
+		t.Fatal("expected repo to be called")
+	}
+}
+
+func TestComposition_IndicatorsHandlerIsReachable(t *testing.T) {
+	series, _ := domain.NewCandleSeries(domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), []domain.Candle{})
+	fake := &fakeRepo{series: series}
+	h, err := server.NewApp(server.Config{Repo: fake})
+	if err != nil {
+		t.Fatalf("failed to wire app: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/indicators?symbol=BTC&timeframe=1m&from=2026-01-01T12:00:00Z&to=2026-01-01T12:01:00Z&indicators=sma:2", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if !fake.called {
+		t.Fatal("expected repo to be called")
+	}
+}
+
+func TestComposition_VolatilityHandlerIsReachable(t *testing.T) {
+	series, _ := domain.NewCandleSeries(domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1d"), []domain.Candle{})
+	fake := &fakeRepo{series: series}
+	h, err := server.NewApp(server.Config{Repo: fake})
+	if err != nil {
+		t.Fatalf("failed to wire app: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/volatility?symbols=BTC&timeframe=1d", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if !fake.called {
+		t.Fatal("expected repo to be called")
+	}
+}
+
+func TestComposition_ScreenerHandlerUsesConfiguredUniverse(t *testing.T) {
+	series, _ := domain.NewCandleSeries(domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1h"), []domain.Candle{})
+	fake := &fakeRepo{series: series}
+	h, err := server.NewApp(server.Config{Repo: fake, ScreenerUniverse: []domain.Symbol{domain.NewSymbolUnsafe("BTC")}})
+	if err != nil {
+		t.Fatalf("failed to wire app: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/screener?timeframe=1h", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if !fake.called {
+		t.Fatal("expected repo to be called")
+	}
+}
+
+func TestComposition_WiresCoalescingAroundRedis(t *testing.T) {
+	sym := domain.NewSymbolUnsafe("BTC")
+	tf := domain.NewTimeframeUnsafe("1m")
+	ts := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := domain.NewCandleUnsafe(sym, tf, ts, 100, 110, 90, 105, 1000)
+	series, _ := domain.NewCandleSeries(sym, tf, []domain.Candle{c})
+	wrapped := &fakeRepo{series: series}
+	redis := &fakeRedis{}
+
+	h, err := server.NewApp(server.Config{Repo: wrapped, RedisClient: redis, CoalesceRequests: true})
+	if err != nil {
+		t.Fatalf("failed to wire app: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/candles?symbol=BTC&timeframe=1m&from=2026-01-01T12:00:00Z&to=2026-01-01T12:01:00Z", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if !wrapped.called || redis.lastKey == "" {
+		t.Fatal("expected the request to pass through the cache to the wrapped repo")
+	}
+}
+
+func TestComposition_WiresMemoryCacheInFrontOfRepo(t *testing.T) {
+	series, _ := domain.NewCandleSeries(domain.NewSymbolUnsafe("BTC"), domain.NewTimeframeUnsafe("1m"), []domain.Candle{})
+	wrapped := &fakeRepo{series: series}
+
+	h, err := server.NewApp(server.Config{Repo: wrapped, MemoryCacheCandles: 100})
+	if err != nil {
+		t.Fatalf("failed to wire app: %v", err)
+	}
+
+	url := "/api/v1/candles?symbol=BTC&timeframe=1m&from=2026-01-01T12:00:00Z&to=2026-01-01T12:01:00Z"
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", url, nil))
+	wrapped.called = false
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if wrapped.called {
+		t.Fatal("expected the repeated request to be served from memory")
+	}
+}
+
+func TestComposition_FailsOverToFallbackProviders(t *testing.T) {
+	sym := domain.NewSymbolUnsafe("BTC")
+	tf := domain.NewTimeframeUnsafe("1m")
+	ts := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	series, _ := domain.NewCandleSeries(sym, tf, []domain.Candle{domain.NewCandleUnsafe(sym, tf, ts, 100, 110, 90, 105, 1000)})
+	primary := &fakeRepo{err: ports.ErrUpstreamUnavailable}
+	fallback := &fakeRepo{series: series}
+
+	h, err := server.NewApp(server.Config{Repo: primary, FallbackProviders: []infra.NamedRepository{{Name: "fallback", Repo: fallback}}})
+	if err != nil {
+		t.Fatalf("failed to wire app: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/candles?symbol=BTC&timeframe=1m&from=2026-01-01T12:00:00Z&to=2026-01-01T12:01:00Z", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if !primary.called || !fallback.called {
+		t.Fatal("expected the fallback to serve the request after the primary failed")
+	}
+}
+
+func TestComposition_ServesHistoryFromCandleStore(t *testing.T) {
+	sym := domain.NewSymbolUnsafe("BTC")
+	tf := domain.NewTimeframeUnsafe("1m")
+	ts := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	series, _ := domain.NewCandleSeries(sym, tf, []domain.Candle{domain.NewCandleUnsafe(sym, tf, ts, 100, 110, 90, 105, 1000)})
+	wrapped := &fakeRepo{series: series}
+	dir := t.TempDir()
+
+	url := "/api/v1/candles?symbol=BTC&timeframe=1m&from=2026-01-01T12:00:00Z&to=2026-01-01T12:01:00Z"
+	h, err := server.NewApp(server.Config{Repo: wrapped, CandleStoreDir: dir})
+	if err != nil {
+		t.Fatalf("failed to wire app: %v", err)
+	}
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", url, nil))
+
+	// A restarted app serves the stored history without the provider.
+	restarted := &fakeRepo{err: ports.ErrUpstreamUnavailable}
+	h, err = server.NewApp(server.Config{Repo: restarted, CandleStoreDir: dir})
+	if err != nil {
+		t.Fatalf("failed to wire app: %v", err)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if restarted.called {
+		t.Fatal("expected the stored history to be served without the provider")
+	}
+}
+
+func TestComposition_SyncRequiresACacheOrStore(t *testing.T) {
+	fake := &fakeRepo{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cfg := server.Config{Repo: fake, SyncUniverse: []domain.Symbol{domain.NewSymbolUnsafe("BTC")}, SyncTimeframes: []domain.Timeframe{domain.NewTimeframeUnsafe("1m")}, Background: ctx}
+
+	if _, err := server.NewApp(cfg); err == nil {
+		t.Fatal("expected an error when there is nothing to sync into")
+	}
+
+	cfg.CandleStoreDir, cfg.Background = t.TempDir(), nil
+	if _, err := server.NewApp(cfg); err == nil {
+		t.Fatal("expected an error when the background jobs have no context")
+	}
+
+	cfg.Background = ctx
+	if _, err := server.NewApp(cfg); err != nil {
+		t.Fatalf("failed to wire app: %v", err)
+	}
+}
+
+// liveRepo serves one candle per bucket of the requested range; it is safe for
+// concurrent use.
+type liveRepo struct{}
+
+func (liveRepo) GetSeries(_ context.Context, sym domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+	var candles []domain.Candle
+	for ts := from; ts.Before(to); ts = ts.Add(tf.Duration()) {
+		candles = append(candles, domain.NewCandleUnsafe(sym, tf, ts, 100, 110, 90, 105, 1000))
+	}
+	return domain.NewCandleSeries(sym, tf, candles)
+}
+
+func TestComposition_StreamsFormingCandlesPastTheRequestTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	h, err := server.NewApp(server.Config{
+		Repo:               liveRepo{},
+		Stream:             infra.NewCandleHub(0),
+		StreamPollInterval: 50 * time.Millisecond,
+		RequestTimeout:     20 * time.Millisecond,
+		Background:         ctx,
+	})
+	if err != nil {
+		t.Fatalf("failed to wire app: %v", err)
+	}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	reqCtx, stop := context.WithTimeout(context.Background(), 2*time.Second)
+	defer stop()
+	req, _ := http.NewRequestWithContext(reqCtx, "GET", srv.URL+"/api/v1/stream?pairs=BTC:1m", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	defer resp.Body.Close()
+
+	lines := bufio.NewScanner(resp.Body)
+	for lines.Scan() {
+		if data, ok := strings.CutPrefix(lines.Text(), "data: "); ok {
+			if !strings.Contains(data, `"symbol":"BTC"`) || !strings.Contains(data, `"closed":false`) {
+				t.Fatalf("expected a forming BTC candle, got %s", data)
+			}
+			return
+		}
+	}
+	t.Fatalf("stream ended without a candle: %v", lines.Err())
+}
+
+// countingRepo counts the calls that reach it; it is safe for concurrent use.
+type countingRepo struct {
+	liveRepo
+	calls atomic.Int32
+}
+
+func (r *countingRepo) GetSeries(ctx context.Context, sym domain.Symbol, tf domain.Timeframe, from time.Time, to time.Time) (domain.CandleSeries, error) {
+	r.calls.Add(1)
+	return r.liveRepo.GetSeries(ctx, sym, tf, from, to)
+}
+
+func TestComposition_ChargesPollingToTheStreamBudget(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	repo := &countingRepo{}
+	hub := infra.NewCandleHub(0)
+	_, err := server.NewApp(server.Config{
+		Repo:               repo,
+		Stream:             hub,
+		StreamPollInterval: 10 * time.Millisecond,
+		ProviderRateLimit:  infra.RateLimit{PerMinute: 100},
+		StreamRateLimit:    infra.RateLimit{PerMinute: 2},
+		Background:         ctx,
+	})
+	if err != nil {
+		t.Fatalf("failed to wire app: %v", err)
+	}
+	if _, err := hub.Subscribe(ctx, []ports.SeriesKey{{Symbol: domain.NewSymbolUnsafe("BTC"), Timeframe: domain.NewTimeframeUnsafe("1m")}}); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if got := repo.calls.Load(); got != 2 {
+		t.Fatalf("expected polling to stop at its budget of 2 calls, got %d", got)
+	}
+}
@@ -0,0 +1,117 @@
+package analysis_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/domain"
+	"github.com/akarso/pano_chart/backend/domain/analysis"
+	"github.com/akarso/pano_chart/backend/domain/indicators"
+)
+
+var t0 = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// seriesFromMids builds contiguous 1m candles centred on mids with a ±1 wick.
+func seriesFromMids(t *testing.T, mids []float64) domain.CandleSeries {
+	t.Helper()
+	sym := domain.NewSymbolUnsafe("BTC")
+	candles := make([]domain.Candle, len(mids))
+	for i, m := range mids {
+		candles[i] = domain.NewCandleUnsafe(sym, domain.Timeframe1m, t0.Add(time.Duration(i)*time.Minute), m, m+1, m-1, m, 1)
+	}
+	s, err := domain.NewCandleSeries(sym, domain.Timeframe1m, candles)
+	if err != nil {
+		t.Fatalf("failed to build series: %v", err)
+	}
+	return s
+}
+
+// oscillating returns n mids bouncing between 98 and 102.
+func oscillating(n int) []float64 {
+	mids := make([]float64, n)
+	for i := range mids {
+		mids[i] = 100 + 2*math.Sin(float64(i)*math.Pi/4)
+	}
+	return mids
+}
+
+// trending returns n mids rising by step per candle.
+func trending(n int, step float64) []float64 {
+	mids := make([]float64, n)
+	for i := range mids {
+		mids[i] = 100 + step*float64(i)
+	}
+	return mids
+}
+
+func TestAnalyzeRange_ScoresSidewaysAboveTrend(t *testing.T) {
+	cfg := analysis.DefaultRangeConfig()
+	sideways, err := analysis.AnalyzeRange(seriesFromMids(t, oscillating(60)), cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	trend, err := analysis.AnalyzeRange(seriesFromMids(t, trending(60, 1)), cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if sideways.Score < 0.7 {
+		t.Errorf("expected sideways score >= 0.7, got %v (%+v)", sideways.Score, sideways)
+	}
+	if trend.Score > 0.3 {
+		t.Errorf("expected trending score <= 0.3, got %v (%+v)", trend.Score, trend)
+	}
+	if trend.SlopeT <= 2 || trend.SlopeR2 < 0.99 {
+		t.Errorf("expected a significant trend, got t=%v r2=%v", trend.SlopeT, trend.SlopeR2)
+	}
+	if sideways.Candles != 60 {
+		t.Errorf("expected 60 analysed candles, got %d", sideways.Candles)
+	}
+}
+
+func TestAnalyzeRange_DetectsBoundaries(t *testing.T) {
+	cfg := analysis.DefaultRangeConfig()
+	cfg.BoundaryQuantile = 0
+	got, err := analysis.AnalyzeRange(seriesFromMids(t, oscillating(40)), cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(got.Upper-103) > 1e-9 || math.Abs(got.Lower-97) > 1e-9 {
+		t.Fatalf("expected boundaries [97, 103], got [%v, %v]", got.Lower, got.Upper)
+	}
+}
+
+func TestAnalyzeRange_UsesLookbackWindow(t *testing.T) {
+	// A strong trend followed by a sideways stretch: only the tail is analysed.
+	mids := append(trending(40, 5), oscillating(40)...)
+	for i := 40; i < len(mids); i++ {
+		mids[i] += 200
+	}
+	cfg := analysis.DefaultRangeConfig()
+	cfg.Lookback = 40
+	got, err := analysis.AnalyzeRange(seriesFromMids(t, mids), cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Candles != 40 || got.Lower < 290 {
+		t.Fatalf("expected analysis of the last 40 candles only, got %+v", got)
+	}
+}
+
+func TestAnalyzeRange_RequiresEnoughCandles(t *testing.T) {
+	_, err := analysis.AnalyzeRange(seriesFromMids(t, oscillating(27)), analysis.DefaultRangeConfig())
+	if !errors.Is(err, analysis.ErrInsufficientData) {
+		t.Fatalf("expected ErrInsufficientData, got %v", err)
+	}
+}
+
+func TestAnalyzeRange_RejectsInvalidConfig(t *testing.T) {
+	cfg := analysis.DefaultRangeConfig()
+	cfg.BoundaryQuantile = 0.5
+	_, err := analysis.AnalyzeRange(seriesFromMids(t, oscillating(40)), cfg)
+	if !errors.Is(err, indicators.ErrInvalidParameter) {
+		t.Fatalf("expected ErrInvalidParameter, got %v", err)
+	}
+}
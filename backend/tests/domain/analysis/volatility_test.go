@@ -0,0 +1,38 @@
+package analysis_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/akarso/pano_chart/backend/domain/analysis"
+	"github.com/akarso/pano_chart/backend/domain/indicators"
+)
+
+func TestRealizedVolatility_SpansWholeSeries(t *testing.T) {
+	cs := seriesFromMids(t, oscillating(30))
+	for _, est := range indicators.Estimators {
+		got, err := analysis.RealizedVolatility(cs, est)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", est, err)
+		}
+		window := 30
+		if est == indicators.CloseToClose {
+			window = 29
+		}
+		rolling, _ := indicators.Volatility(cs, est, window, indicators.GapIgnore)
+		want := rolling.Points[len(rolling.Points)-1].Value
+		if got <= 0 || math.Abs(got-want) > 1e-12 {
+			t.Errorf("%s: expected %v, got %v", est, want, got)
+		}
+	}
+}
+
+func TestRealizedVolatility_RequiresEnoughCandles(t *testing.T) {
+	if _, err := analysis.RealizedVolatility(seriesFromMids(t, oscillating(2)), indicators.CloseToClose); !errors.Is(err, analysis.ErrInsufficientData) {
+		t.Errorf("close-to-close: expected ErrInsufficientData, got %v", err)
+	}
+	if _, err := analysis.RealizedVolatility(seriesFromMids(t, oscillating(2)), indicators.Parkinson); err != nil {
+		t.Errorf("parkinson: expected 2 candles to suffice, got %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+func TestValidateRange_AcceptsForwardRange(t *testing.T) {
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	if err := domain.ValidateRange(from, from.Add(time.Minute)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestValidateRange_RejectsEmptyOrInvertedRange(t *testing.T) {
+	from := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name     string
+		from, to time.Time
+	}{
+		{"equal", from, from},
+		{"inverted", from, from.Add(-time.Minute)},
+		{"zero from", time.Time{}, from},
+		{"zero to", from, time.Time{}},
+	}
+	for _, c := range cases {
+		err := domain.ValidateRange(c.from, c.to)
+		if !errors.Is(err, domain.ErrInvalidRange) {
+			t.Errorf("%s: expected ErrInvalidRange, got %v", c.name, err)
+		}
+	}
+}
+
+func TestConstructors_WrapSentinelErrors(t *testing.T) {
+	if _, err := domain.NewSymbol("BTC$"); !errors.Is(err, domain.ErrInvalidSymbol) {
+		t.Errorf("expected ErrInvalidSymbol, got %v", err)
+	}
+	if _, err := domain.NewSymbol(""); !errors.Is(err, domain.ErrInvalidSymbol) {
+		t.Errorf("expected ErrInvalidSymbol for empty symbol, got %v", err)
+	}
+	if _, err := domain.NewTimeframe("2m"); !errors.Is(err, domain.ErrInvalidTimeframe) {
+		t.Errorf("expected ErrInvalidTimeframe, got %v", err)
+	}
+	ts := time.Date(2026, 1, 1, 12, 0, 30, 0, time.UTC)
+	if _, err := domain.NewCandle(domain.NewSymbolUnsafe("BTC"), domain.Timeframe1m, ts, 1, 1, 1, 1, 1); !errors.Is(err, domain.ErrInvalidCandle) {
+		t.Errorf("expected ErrInvalidCandle, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package indicators_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/domain"
+	"github.com/akarso/pano_chart/backend/domain/indicators"
+)
+
+// trendSeries builds n contiguous 1m candles moving by step per candle with a fixed range.
+func trendSeries(t *testing.T, n int, step float64) domain.CandleSeries {
+	t.Helper()
+	sym := domain.NewSymbolUnsafe("BTC")
+	candles := make([]domain.Candle, n)
+	for i := range candles {
+		mid := 100 + step*float64(i)
+		candles[i] = domain.NewCandleUnsafe(sym, domain.Timeframe1m, t0.Add(time.Duration(i)*time.Minute), mid, mid+1, mid-1, mid, 1)
+	}
+	s, err := domain.NewCandleSeries(sym, domain.Timeframe1m, candles)
+	if err != nil {
+		t.Fatalf("failed to build series: %v", err)
+	}
+	return s
+}
+
+func TestADX_WarmsUpForTwoPeriods(t *testing.T) {
+	got, err := indicators.ADX(trendSeries(t, 8, 1), 3, indicators.GapReset)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, p := range got.Points {
+		if want := i >= 5; p.Valid != want {
+			t.Fatalf("point %d: expected valid=%v, got %v", i, want, p.Valid)
+		}
+	}
+}
+
+func TestADX_ReportsFullStrengthForMonotonicTrend(t *testing.T) {
+	got, _ := indicators.ADX(trendSeries(t, 10, 2), 3, indicators.GapReset)
+	last := got.Points[len(got.Points)-1]
+	if !last.Valid || last.Value < 99.999 {
+		t.Fatalf("expected ADX 100 for a one-directional trend, got %v", last.Value)
+	}
+}
+
+func TestADX_IsZeroForFlatMarket(t *testing.T) {
+	got, _ := indicators.ADX(trendSeries(t, 10, 0), 3, indicators.GapReset)
+	last := got.Points[len(got.Points)-1]
+	if !last.Valid || last.Value != 0 {
+		t.Fatalf("expected ADX 0 for a flat market, got %v", last.Value)
+	}
+}
+
+func TestADX_RejectsNonPositivePeriod(t *testing.T) {
+	if _, err := indicators.ADX(trendSeries(t, 3, 1), 0, indicators.GapReset); err == nil {
+		t.Fatal("expected error for period 0")
+	}
+}
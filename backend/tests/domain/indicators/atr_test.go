@@ -0,0 +1,39 @@
+package indicators_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/domain"
+	"github.com/akarso/pano_chart/backend/domain/indicators"
+)
+
+func TestATR_UsesTrueRangeAndWilderSmoothing(t *testing.T) {
+	sym := domain.NewSymbolUnsafe("BTC")
+	tf := domain.Timeframe1m
+	candles := []domain.Candle{
+		domain.NewCandleUnsafe(sym, tf, t0, 9, 10, 8, 9, 1),                           // TR 2
+		domain.NewCandleUnsafe(sym, tf, t0.Add(time.Minute), 11, 12, 9, 11, 1),        // TR 3
+		domain.NewCandleUnsafe(sym, tf, t0.Add(2*time.Minute), 10.5, 11, 10, 10.5, 1), // TR max(1, 0, 1) = 1
+	}
+	cs, _ := domain.NewCandleSeries(sym, tf, candles)
+
+	got, err := indicators.ATR(cs, 2, indicators.GapReset)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectPoints(t, got, nan, 2.5, 1.75)
+}
+
+func TestATR_CapturesGapsViaPreviousClose(t *testing.T) {
+	sym := domain.NewSymbolUnsafe("BTC")
+	tf := domain.Timeframe1m
+	candles := []domain.Candle{
+		domain.NewCandleUnsafe(sym, tf, t0, 10, 10, 10, 10, 1),
+		domain.NewCandleUnsafe(sym, tf, t0.Add(time.Minute), 20, 20, 20, 20, 1), // TR |20-10| = 10
+	}
+	cs, _ := domain.NewCandleSeries(sym, tf, candles)
+
+	got, _ := indicators.ATR(cs, 1, indicators.GapReset)
+	expectPoints(t, got, 0, 10)
+}
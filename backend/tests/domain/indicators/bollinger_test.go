@@ -0,0 +1,24 @@
+package indicators_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/akarso/pano_chart/backend/domain/indicators"
+)
+
+func TestBollinger_ComputesBandsFromPopulationStdDev(t *testing.T) {
+	got, err := indicators.Bollinger(closeSeries(t, 1, 3, 3), 2, 2, indicators.GapReset)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectPoints(t, got.Middle, nan, 2, 3)
+	expectPoints(t, got.Upper, nan, 4, 3)
+	expectPoints(t, got.Lower, nan, 0, 3)
+}
+
+func TestBollinger_RejectsNonPositiveWidth(t *testing.T) {
+	if _, err := indicators.Bollinger(closeSeries(t, 1), 20, 0, indicators.GapReset); !errors.Is(err, indicators.ErrInvalidParameter) {
+		t.Fatalf("expected ErrInvalidParameter, got %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package indicators_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/domain"
+	"github.com/akarso/pano_chart/backend/domain/indicators"
+)
+
+var t0 = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// closeSeries builds a contiguous 1m series whose candles close at the given prices.
+func closeSeries(t *testing.T, closes ...float64) domain.CandleSeries {
+	t.Helper()
+	offsets := make([]int, len(closes))
+	for i := range closes {
+		offsets[i] = i
+	}
+	return closeSeriesAt(t, offsets, closes...)
+}
+
+// closeSeriesAt builds a 1m series with candles at the given minute offsets from t0.
+func closeSeriesAt(t *testing.T, offsets []int, closes ...float64) domain.CandleSeries {
+	t.Helper()
+	sym := domain.NewSymbolUnsafe("BTC")
+	candles := make([]domain.Candle, len(closes))
+	for i, c := range closes {
+		candles[i] = domain.NewCandleUnsafe(sym, domain.Timeframe1m, t0.Add(time.Duration(offsets[i])*time.Minute), c, c, c, c, 1)
+	}
+	s, err := domain.NewCandleSeries(sym, domain.Timeframe1m, candles)
+	if err != nil {
+		t.Fatalf("failed to build series: %v", err)
+	}
+	return s
+}
+
+// expectPoints compares points against want, where NaN marks an invalid (warm-up) point.
+func expectPoints(t *testing.T, got indicators.Series, want ...float64) {
+	t.Helper()
+	if len(got.Points) != len(want) {
+		t.Fatalf("%s: expected %d points, got %d", got.Name, len(want), len(got.Points))
+	}
+	for i, w := range want {
+		p := got.Points[i]
+		if math.IsNaN(w) {
+			if p.Valid {
+				t.Errorf("%s[%d]: expected invalid point, got %v", got.Name, i, p.Value)
+			}
+			continue
+		}
+		if !p.Valid || math.Abs(p.Value-w) > 1e-9 {
+			t.Errorf("%s[%d]: expected %v, got %v (valid=%v)", got.Name, i, w, p.Value, p.Valid)
+		}
+	}
+}
+
+var nan = math.NaN()
+
+func TestIndicators_AlignPointsWithCandleTimestamps(t *testing.T) {
+	cs := closeSeries(t, 1, 2, 3)
+	got, err := indicators.SMA(cs, 2, indicators.GapReset)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, c := range cs.All() {
+		if !got.Points[i].Timestamp.Equal(c.Timestamp()) {
+			t.Fatalf("point %d: expected timestamp %v, got %v", i, c.Timestamp(), got.Points[i].Timestamp)
+		}
+	}
+}
+
+func TestIndicators_GapResetRestartsWarmUp(t *testing.T) {
+	// Gap between minute 2 and minute 5.
+	cs := closeSeriesAt(t, []int{0, 1, 2, 5, 6}, 1, 3, 5, 7, 9)
+
+	reset, _ := indicators.SMA(cs, 2, indicators.GapReset)
+	expectPoints(t, reset, nan, 2, 4, nan, 8)
+
+	ignore, _ := indicators.SMA(cs, 2, indicators.GapIgnore)
+	expectPoints(t, ignore, nan, 2, 4, 6, 8)
+}
+
+func TestSeries_SinceTrimsLeadingPoints(t *testing.T) {
+	cs := closeSeries(t, 1, 2, 3, 4)
+	got, _ := indicators.SMA(cs, 1, indicators.GapReset)
+
+	trimmed := got.Since(t0.Add(2 * time.Minute))
+	expectPoints(t, trimmed, 3, 4)
+	if len(got.Points) != 4 {
+		t.Fatal("expected receiver to be unchanged")
+	}
+}
+
+func TestIndicators_MarkNonFiniteValuesInvalid(t *testing.T) {
+	line, err := indicators.SMA(closeSeries(t, math.MaxFloat64, math.MaxFloat64, 1), 2, indicators.GapReset)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if line.Points[1].Valid {
+		t.Fatalf("expected the overflowing average to be invalid, got %v", line.Points[1].Value)
+	}
+	for i, pt := range line.Points {
+		if pt.Valid && (math.IsNaN(pt.Value) || math.IsInf(pt.Value, 0)) {
+			t.Fatalf("point %d: expected a finite value, got %v", i, pt.Value)
+		}
+	}
+}
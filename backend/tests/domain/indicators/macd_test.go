@@ -0,0 +1,25 @@
+package indicators_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/akarso/pano_chart/backend/domain/indicators"
+)
+
+func TestMACD_ComputesLinesWithStaggeredWarmUp(t *testing.T) {
+	got, err := indicators.MACD(closeSeries(t, 1, 2, 3, 4, 5, 6), 2, 3, 2, indicators.GapReset)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// EMA2 = 1.5, 2.5, 3.5, ... from index 1; EMA3 = 2, 3, 4, ... from index 2.
+	expectPoints(t, got.MACD, nan, nan, 0.5, 0.5, 0.5, 0.5)
+	expectPoints(t, got.Signal, nan, nan, nan, 0.5, 0.5, 0.5)
+	expectPoints(t, got.Histogram, nan, nan, nan, 0, 0, 0)
+}
+
+func TestMACD_RejectsFastNotBelowSlow(t *testing.T) {
+	if _, err := indicators.MACD(closeSeries(t, 1), 26, 12, 9, indicators.GapReset); !errors.Is(err, indicators.ErrInvalidParameter) {
+		t.Fatalf("expected ErrInvalidParameter, got %v", err)
+	}
+}
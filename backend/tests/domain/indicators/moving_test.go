@@ -0,0 +1,46 @@
+package indicators_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/akarso/pano_chart/backend/domain/indicators"
+)
+
+func TestSMA_ComputesRollingMean(t *testing.T) {
+	got, err := indicators.SMA(closeSeries(t, 1, 2, 3, 4, 5), 3, indicators.GapReset)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectPoints(t, got, nan, nan, 2, 3, 4)
+}
+
+func TestEMA_SeedsWithSMAThenSmooths(t *testing.T) {
+	got, err := indicators.EMA(closeSeries(t, 1, 2, 3, 4, 5), 3, indicators.GapReset)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectPoints(t, got, nan, nan, 2, 3, 4)
+
+	got, _ = indicators.EMA(closeSeries(t, 2, 4, 10), 2, indicators.GapReset)
+	// seed 3, then 10*2/3 + 3/3
+	expectPoints(t, got, nan, 3, 23.0/3)
+}
+
+func TestMovingAverages_RejectInvalidPeriod(t *testing.T) {
+	cs := closeSeries(t, 1, 2)
+	if _, err := indicators.SMA(cs, 0, indicators.GapReset); !errors.Is(err, indicators.ErrInvalidParameter) {
+		t.Fatalf("expected ErrInvalidParameter, got %v", err)
+	}
+	if _, err := indicators.EMA(cs, -1, indicators.GapReset); !errors.Is(err, indicators.ErrInvalidParameter) {
+		t.Fatalf("expected ErrInvalidParameter, got %v", err)
+	}
+}
+
+func TestMovingAverages_ShortSeriesIsAllWarmUp(t *testing.T) {
+	got, err := indicators.EMA(closeSeries(t, 1, 2), 5, indicators.GapReset)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectPoints(t, got, nan, nan)
+}
@@ -0,0 +1,30 @@
+package indicators_test
+
+import (
+	"testing"
+
+	"github.com/akarso/pano_chart/backend/domain/indicators"
+)
+
+func TestRSI_UsesWilderSmoothing(t *testing.T) {
+	got, err := indicators.RSI(closeSeries(t, 1, 2, 3, 2), 2, indicators.GapReset)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Seed: gains 1,1 / losses 0,0 -> 100. Then gain 0, loss 1 -> avg 0.5/0.5 -> 50.
+	expectPoints(t, got, nan, nan, 100, 50)
+}
+
+func TestRSI_FlatMarketIsNeutral(t *testing.T) {
+	got, _ := indicators.RSI(closeSeries(t, 5, 5, 5), 2, indicators.GapReset)
+	expectPoints(t, got, nan, nan, 50)
+}
+
+func TestRSI_StaysWithinBounds(t *testing.T) {
+	got, _ := indicators.RSI(closeSeries(t, 10, 12, 11, 15, 9, 8, 13, 14, 10, 11), 3, indicators.GapReset)
+	for i, p := range got.Points {
+		if p.Valid && (p.Value < 0 || p.Value > 100) {
+			t.Fatalf("point %d out of bounds: %v", i, p.Value)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package indicators_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/akarso/pano_chart/backend/domain/indicators"
+)
+
+func TestParseSpec_AppliesDefaultsAndOverrides(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"sma", "sma:20"},
+		{"RSI:7", "rsi:7"},
+		{"macd", "macd:12:26:9"},
+		{"macd:5", "macd:5:26:9"},
+		{"bb:10:1.5", "bb:10:1.5"},
+		{" atr ", "atr:14"},
+		{"VOL_PARKINSON", "vol_parkinson:20"},
+	}
+	for _, c := range cases {
+		spec, err := indicators.ParseSpec(c.in)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", c.in, err)
+		}
+		if spec.String() != c.want {
+			t.Errorf("%q: expected %s, got %s", c.in, c.want, spec.String())
+		}
+	}
+}
+
+func TestParseSpec_RejectsInvalidInput(t *testing.T) {
+	for _, in := range []string{"", "vwap", "sma:abc", "sma:0", "sma:2.5", "rsi:14:2", "bb:-1:2", "sma:1001", "macd:12:1e300:9", "vol_cc:1e18", "bb:20:0", "bb:20:11", "bb:20:1e300"} {
+		if _, err := indicators.ParseSpec(in); !errors.Is(err, indicators.ErrInvalidParameter) {
+			t.Errorf("%q: expected ErrInvalidParameter, got %v", in, err)
+		}
+	}
+}
+
+func TestSpec_WarmUpMatchesFirstValidPoint(t *testing.T) {
+	cs := closeSeries(t, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
+	for _, in := range []string{"sma:4", "ema:3", "rsi:5", "macd:2:4:3", "bb:6:2", "atr:3", "adx:3", "vol_cc:3", "vol_gk:3"} {
+		spec, err := indicators.ParseSpec(in)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", in, err)
+		}
+		lines, err := spec.Compute(cs, indicators.GapReset)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", in, err)
+		}
+		allValid := 0
+		for _, line := range lines {
+			first := -1
+			for i, p := range line.Points {
+				if p.Valid {
+					first = i
+					break
+				}
+			}
+			if first > allValid {
+				allValid = first
+			}
+		}
+		if allValid != spec.WarmUp() {
+			t.Errorf("%s: expected all lines valid from %d, got %d", in, spec.WarmUp(), allValid)
+		}
+	}
+}
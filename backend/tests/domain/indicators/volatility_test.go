@@ -0,0 +1,114 @@
+package indicators_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/domain"
+	"github.com/akarso/pano_chart/backend/domain/indicators"
+)
+
+// ohlcSeries builds contiguous 1d candles from {open, high, low, close} rows.
+func ohlcSeries(t *testing.T, rows ...[4]float64) domain.CandleSeries {
+	t.Helper()
+	sym := domain.NewSymbolUnsafe("BTC")
+	candles := make([]domain.Candle, len(rows))
+	for i, r := range rows {
+		candles[i] = domain.NewCandleUnsafe(sym, domain.Timeframe1d, t0.Truncate(24*time.Hour).Add(time.Duration(i)*24*time.Hour), r[0], r[1], r[2], r[3], 1)
+	}
+	s, err := domain.NewCandleSeries(sym, domain.Timeframe1d, candles)
+	if err != nil {
+		t.Fatalf("failed to build series: %v", err)
+	}
+	return s
+}
+
+func TestAnnualizationFactor_UsesTimeframeDuration(t *testing.T) {
+	if got := indicators.AnnualizationFactor(domain.Timeframe1d); math.Abs(got-math.Sqrt(365)) > 1e-9 {
+		t.Errorf("1d: expected sqrt(365), got %v", got)
+	}
+	if got := indicators.AnnualizationFactor(domain.Timeframe1h); math.Abs(got-math.Sqrt(365*24)) > 1e-9 {
+		t.Errorf("1h: expected sqrt(8760), got %v", got)
+	}
+	if got := indicators.AnnualizationFactor(domain.Timeframe("2m")); got != 0 {
+		t.Errorf("unknown timeframe: expected 0, got %v", got)
+	}
+}
+
+func TestVolatility_CloseToCloseIsSampleStdDevOfLogReturns(t *testing.T) {
+	e := math.E
+	// Log returns: +1, -1, +1.
+	cs := ohlcSeries(t,
+		[4]float64{1, 1, 1, 1},
+		[4]float64{e, e, e, e},
+		[4]float64{1, 1, 1, 1},
+		[4]float64{e, e, e, e},
+	)
+	got, err := indicators.Volatility(cs, indicators.CloseToClose, 2, indicators.GapReset)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Sample variance of {+1, -1} is 2.
+	want := math.Sqrt(2) * math.Sqrt(365)
+	expectPoints(t, got, nan, nan, want, want)
+	if got.Name != "vol_cc" {
+		t.Errorf("expected line name vol_cc, got %s", got.Name)
+	}
+}
+
+func TestVolatility_RangeEstimatorsMatchClosedForms(t *testing.T) {
+	// One candle with high/low = e^2 and close/open = e.
+	e := math.E
+	cs := ohlcSeries(t, [4]float64{1, e * e, 1, e}, [4]float64{1, e * e, 1, e})
+	ann := math.Sqrt(365)
+	cases := map[indicators.Estimator]float64{
+		indicators.Parkinson:      math.Sqrt(4/(4*math.Ln2)) * ann,
+		indicators.GarmanKlass:    math.Sqrt(0.5*4-(2*math.Ln2-1)) * ann,
+		indicators.RogersSatchell: math.Sqrt(math.Log(e*e/e)*math.Log(e*e)+math.Log(1/e)*math.Log(1)) * ann,
+	}
+	for est, want := range cases {
+		got, err := indicators.Volatility(cs, est, 2, indicators.GapReset)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", est, err)
+		}
+		expectPoints(t, got, nan, want)
+	}
+}
+
+func TestVolatility_FlatMarketIsZero(t *testing.T) {
+	cs := ohlcSeries(t, [4]float64{5, 5, 5, 5}, [4]float64{5, 5, 5, 5}, [4]float64{5, 5, 5, 5})
+	for _, est := range indicators.Estimators {
+		got, _ := indicators.Volatility(cs, est, 2, indicators.GapReset)
+		last := got.Points[2]
+		if !last.Valid || last.Value != 0 {
+			t.Errorf("%s: expected 0, got %+v", est, last)
+		}
+	}
+}
+
+func TestVolatility_InvalidWhenWindowHasNonPositivePrice(t *testing.T) {
+	cs := ohlcSeries(t, [4]float64{1, 2, 0, 1}, [4]float64{1, 2, 1, 1}, [4]float64{1, 2, 1, 1})
+	got, _ := indicators.Volatility(cs, indicators.Parkinson, 2, indicators.GapReset)
+	if got.Points[1].Valid || !got.Points[2].Valid {
+		t.Fatalf("expected only the window free of zero prices to be valid, got %+v", got.Points)
+	}
+}
+
+func TestVolatility_RejectsInvalidParameters(t *testing.T) {
+	cs := ohlcSeries(t, [4]float64{1, 1, 1, 1})
+	if _, err := indicators.Volatility(cs, indicators.Parkinson, 1, indicators.GapReset); !errors.Is(err, indicators.ErrInvalidParameter) {
+		t.Errorf("window 1: expected ErrInvalidParameter, got %v", err)
+	}
+	if _, err := indicators.Volatility(cs, "yang_zhang", 5, indicators.GapReset); !errors.Is(err, indicators.ErrInvalidParameter) {
+		t.Errorf("unknown estimator: expected ErrInvalidParameter, got %v", err)
+	}
+}
+
+func TestParseEstimator_IsCaseInsensitive(t *testing.T) {
+	got, err := indicators.ParseEstimator(" Parkinson ")
+	if err != nil || got != indicators.Parkinson {
+		t.Fatalf("expected parkinson, got %q (%v)", got, err)
+	}
+}
@@ -0,0 +1,189 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akarso/pano_chart/backend/domain"
+)
+
+// minuteSeries builds a 1m series with one candle per offset (in minutes) from base.
+// Candle i has open=i, close=i+1, high=i+2, low=i and volume=1.
+func minuteSeries(t *testing.T, base time.Time, offsets ...int) domain.CandleSeries {
+	t.Helper()
+	sym := domain.NewSymbolUnsafe("BTC")
+	candles := make([]domain.Candle, 0, len(offsets))
+	for _, m := range offsets {
+		v := float64(m)
+		c, err := domain.NewCandle(sym, domain.Timeframe1m, base.Add(time.Duration(m)*time.Minute), v, v+2, v, v+1, 1)
+		if err != nil {
+			t.Fatalf("failed to build candle: %v", err)
+		}
+		candles = append(candles, c)
+	}
+	s, err := domain.NewCandleSeries(sym, domain.Timeframe1m, candles)
+	if err != nil {
+		t.Fatalf("failed to build series: %v", err)
+	}
+	return s
+}
+
+func rangeOf(from, to int) []int {
+	out := make([]int, 0, to-from)
+	for i := from; i < to; i++ {
+		out = append(out, i)
+	}
+	return out
+}
+
+func TestResample_FoldsOHLCV(t *testing.T) {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	src := minuteSeries(t, base, rangeOf(0, 10)...)
+
+	res, err := src.Resample(domain.Timeframe5m, domain.ResamplePolicy{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Timeframe() != domain.Timeframe5m || res.Symbol() != src.Symbol() {
+		t.Fatalf("expected 5m BTC series, got %v %v", res.Symbol(), res.Timeframe())
+	}
+	if res.Len() != 2 {
+		t.Fatalf("expected 2 buckets, got %d", res.Len())
+	}
+	c, _ := res.At(1)
+	if !c.Timestamp().Equal(base.Add(5 * time.Minute)) {
+		t.Fatalf("unexpected bucket timestamp %v", c.Timestamp())
+	}
+	if c.Open() != 5 || c.Close() != 10 || c.High() != 11 || c.Low() != 5 || c.Volume() != 5 {
+		t.Fatalf("unexpected fold: o=%v h=%v l=%v c=%v v=%v", c.Open(), c.High(), c.Low(), c.Close(), c.Volume())
+	}
+}
+
+func TestResample_AlignsBucketsToTargetTimeframe(t *testing.T) {
+	// 1h series starting at 02:00 must land in the 00:00 and 04:00 4h buckets.
+	sym := domain.NewSymbolUnsafe("BTC")
+	base := time.Date(2026, 1, 1, 2, 0, 0, 0, time.UTC)
+	var candles []domain.Candle
+	for h := 0; h < 6; h++ {
+		candles = append(candles, domain.NewCandleUnsafe(sym, domain.Timeframe1h, base.Add(time.Duration(h)*time.Hour), 1, 1, 1, 1, 1))
+	}
+	src, _ := domain.NewCandleSeries(sym, domain.Timeframe1h, candles)
+
+	res, err := src.Resample(domain.Timeframe4h, domain.ResamplePolicy{Edges: domain.KeepPartial})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []time.Time{
+		time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 1, 1, 4, 0, 0, 0, time.UTC),
+	}
+	if res.Len() != len(want) {
+		t.Fatalf("expected %d buckets, got %d", len(want), res.Len())
+	}
+	for i, w := range want {
+		c, _ := res.At(i)
+		if !c.Timestamp().Equal(w) {
+			t.Errorf("bucket %d: expected %v, got %v", i, w, c.Timestamp())
+		}
+	}
+}
+
+func TestResample_EdgePolicy(t *testing.T) {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	// Starts mid-bucket (12:03) and ends mid-bucket (12:11).
+	src := minuteSeries(t, base, rangeOf(3, 12)...)
+
+	strict, err := src.Resample(domain.Timeframe5m, domain.ResamplePolicy{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strict.Len() != 1 {
+		t.Fatalf("expected only the complete 12:05 bucket, got %d", strict.Len())
+	}
+
+	lenient, err := src.Resample(domain.Timeframe5m, domain.ResamplePolicy{Edges: domain.KeepPartial})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lenient.Len() != 3 {
+		t.Fatalf("expected partial edge buckets to be kept, got %d", lenient.Len())
+	}
+	first, _ := lenient.First()
+	if first.Open() != 3 || first.Volume() != 2 {
+		t.Fatalf("expected edge bucket folded from 12:03-12:04, got open=%v volume=%v", first.Open(), first.Volume())
+	}
+}
+
+func TestResample_GapPolicy(t *testing.T) {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	// Bucket 12:05 is missing 12:07; first and last buckets are complete.
+	offsets := append(rangeOf(0, 7), rangeOf(8, 15)...)
+	src := minuteSeries(t, base, offsets...)
+
+	// Gaps are not edges: keeping edges alone must still drop the gapped bucket.
+	res, err := src.Resample(domain.Timeframe5m, domain.ResamplePolicy{Edges: domain.KeepPartial})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Len() != 2 || !res.HasGapAfter(0) {
+		t.Fatalf("expected gapped bucket to be dropped, got %d buckets", res.Len())
+	}
+
+	res, err = src.Resample(domain.Timeframe5m, domain.ResamplePolicy{Gaps: domain.KeepPartial})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Len() != 3 {
+		t.Fatalf("expected gapped bucket to be kept, got %d buckets", res.Len())
+	}
+	mid, _ := res.At(1)
+	if mid.Volume() != 4 {
+		t.Fatalf("expected gapped bucket volume 4, got %v", mid.Volume())
+	}
+}
+
+func TestResample_RejectsFinerOrIncompatibleTarget(t *testing.T) {
+	sym := domain.NewSymbolUnsafe("BTC")
+	src, _ := domain.NewCandleSeries(sym, domain.Timeframe1h, nil)
+
+	if _, err := src.Resample(domain.Timeframe15m, domain.ResamplePolicy{}); !errors.Is(err, domain.ErrInvalidTimeframe) {
+		t.Fatalf("expected ErrInvalidTimeframe for finer target, got %v", err)
+	}
+	if _, err := src.Resample(domain.NewTimeframeUnsafe("7m"), domain.ResamplePolicy{}); !errors.Is(err, domain.ErrInvalidTimeframe) {
+		t.Fatalf("expected ErrInvalidTimeframe for unsupported target, got %v", err)
+	}
+}
+
+func TestResample_EmptySeries(t *testing.T) {
+	src, _ := domain.NewCandleSeries(domain.NewSymbolUnsafe("BTC"), domain.Timeframe1m, nil)
+
+	res, err := src.Resample(domain.Timeframe1h, domain.ResamplePolicy{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Len() != 0 || res.Timeframe() != domain.Timeframe1h {
+		t.Fatalf("expected empty 1h series, got %d candles at %v", res.Len(), res.Timeframe())
+	}
+}
+
+func TestCandleSeries_BetweenSelectsHalfOpenRange(t *testing.T) {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	src := minuteSeries(t, base, rangeOf(0, 10)...)
+
+	sub := src.Between(base.Add(2*time.Minute), base.Add(5*time.Minute))
+	if sub.Len() != 3 {
+		t.Fatalf("expected 3 candles, got %d", sub.Len())
+	}
+	first, _ := sub.First()
+	last, _ := sub.Last()
+	if !first.Timestamp().Equal(base.Add(2*time.Minute)) || !last.Timestamp().Equal(base.Add(4*time.Minute)) {
+		t.Fatalf("unexpected bounds %v..%v", first.Timestamp(), last.Timestamp())
+	}
+	if src.Len() != 10 {
+		t.Fatal("expected receiver to be unchanged")
+	}
+	if empty := src.Between(base.Add(time.Hour), base); empty.Len() != 0 {
+		t.Fatalf("expected inverted range to be empty, got %d", empty.Len())
+	}
+}
@@ -0,0 +1,25 @@
+package migrations_test
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/akarso/pano_chart/backend/migrations"
+)
+
+func TestMigrations_EveryUpHasADown(t *testing.T) {
+	ups, err := fs.Glob(migrations.FS, "*.up.sql")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ups) == 0 {
+		t.Fatal("expected embedded migrations")
+	}
+	for _, up := range ups {
+		down := strings.TrimSuffix(up, ".up.sql") + ".down.sql"
+		if _, err := fs.Stat(migrations.FS, down); err != nil {
+			t.Fatalf("expected %s for %s", down, up)
+		}
+	}
+}
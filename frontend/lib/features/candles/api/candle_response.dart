@@ -1,6 +1,8 @@
 // No extra imports required; keep file minimal to satisfy analyzer.
 
-/// Immutable DTO for a single candle matching PR-011 (timestamp RFC3339, numeric OHLCV).
+/// Immutable DTO for a single candle matching COMMON.md (timestamp UTC epoch
+/// milliseconds, numeric OHLCV). RFC3339 timestamps from the legacy shape are
+/// still accepted when parsing.
 class CandleDto {
   final DateTime timestamp; // UTC
   final double open;
@@ -18,9 +20,8 @@ class CandleDto {
       required this.volume});
 
   factory CandleDto.fromJson(Map<String, dynamic> json) {
-    final ts = DateTime.parse(json['timestamp'] as String).toUtc();
     return CandleDto(
-      timestamp: ts,
+      timestamp: _parseTimestamp(json['timestamp']),
       open: (json['open'] as num).toDouble(),
       high: (json['high'] as num).toDouble(),
       low: (json['low'] as num).toDouble(),
@@ -30,7 +31,7 @@ class CandleDto {
   }
 
   Map<String, dynamic> toJson() => {
-        'timestamp': timestamp.toUtc().toIso8601String(),
+        'timestamp': timestamp.millisecondsSinceEpoch,
         'open': open,
         'high': high,
         'low': low,
@@ -38,6 +39,13 @@ class CandleDto {
         'volume': volume,
       };
 
+  static DateTime _parseTimestamp(Object? value) {
+    if (value is num) {
+      return DateTime.fromMillisecondsSinceEpoch(value.toInt(), isUtc: true);
+    }
+    return DateTime.parse(value as String).toUtc();
+  }
+
   @override
   bool operator ==(Object other) {
     return identical(this, other) ||
@@ -21,7 +21,23 @@ void main() {
     expect(dto.volume, 123.45);
 
     final out = dto.toJson();
-    expect(out['timestamp'], '2024-01-01T00:00:00.000Z');
+    expect(out['timestamp'], 1704067200000);
     expect(out['open'], 42000.0);
   });
+
+  test('CandleDto_parsesEpochMillisecondTimestamp', () {
+    final json = {
+      'timestamp': 1704067200000,
+      'open': 42000.0,
+      'high': 42100.0,
+      'low': 41950.0,
+      'close': 42050.0,
+      'volume': 123.45
+    };
+
+    final dto = CandleDto.fromJson(json);
+    expect(dto.timestamp, DateTime.utc(2024, 1, 1));
+    expect(dto.timestamp.isUtc, isTrue);
+    expect(CandleDto.fromJson(dto.toJson()), dto);
+  });
 }